@@ -12,8 +12,13 @@ import (
 	"backend/internal/config"
 	"backend/internal/db"
 	"backend/internal/handlers"
+	"backend/internal/jobs"
+	"backend/internal/mail"
 	mw "backend/internal/middleware"
 	"backend/internal/middleware/cors"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/notify"
+	"backend/internal/payment"
 	"backend/internal/store"
 )
 
@@ -28,6 +33,17 @@ func main() {
 	var revisionTicketStore *store.RevisionTicketStore
 	var analitikStore *store.AnalitikStore
 	var taperStore *store.TaperStore
+	var webhookEventStore *store.WebhookEventStore
+	var campaignStore *store.CampaignStore
+	var reviewStore *store.ReviewStore
+	var mailOutbox *store.MailOutboxStore
+	var subscriptionStore *store.SubscriptionStore
+	var inquiryStore *store.InquiryStore
+	var quotationStore *store.QuotationStore
+	var orderMessageStore *store.OrderMessageStore
+	var revisionPurchaseStore *store.RevisionPurchaseStore
+	var rateLimitStore ratelimit.Store
+	var feedTokenStore *store.FeedTokenStore
 
 	if cfg.DatabaseURL != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -49,6 +65,17 @@ func main() {
 		revisionTicketStore = store.NewRevisionTicketStoreFromDB(pool)
 		analitikStore = store.NewAnalitikStoreFromDB(pool)
 		taperStore = store.NewTaperStoreFromDB(pool)
+		webhookEventStore = store.NewWebhookEventStoreFromDB(pool)
+		campaignStore = store.NewCampaignStoreFromDB(pool)
+		reviewStore = store.NewReviewStoreFromDB(pool)
+		mailOutbox = store.NewMailOutboxStoreFromDB(pool)
+		subscriptionStore = store.NewSubscriptionStoreFromDB(pool)
+		inquiryStore = store.NewInquiryStoreFromDB(pool)
+		quotationStore = store.NewQuotationStoreFromDB(pool)
+		orderMessageStore = store.NewOrderMessageStoreFromDB(pool)
+		revisionPurchaseStore = store.NewRevisionPurchaseStoreFromDB(pool)
+		rateLimitStore = ratelimit.NewPostgresStore(pool)
+		feedTokenStore = store.NewFeedTokenStoreFromDB(pool)
 		log.Println("Raspro connected to PostgreSQL (real-time persistent)")
 	} else {
 		donateStore = store.New()
@@ -60,6 +87,17 @@ func main() {
 		revisionTicketStore = store.NewRevisionTicketStore()
 		analitikStore = store.NewAnalitikStore()
 		taperStore = store.NewTaperStore()
+		webhookEventStore = store.NewWebhookEventStore()
+		campaignStore = store.NewCampaignStore()
+		reviewStore = store.NewReviewStore()
+		mailOutbox = store.NewMailOutboxStore()
+		subscriptionStore = store.NewSubscriptionStore()
+		inquiryStore = store.NewInquiryStore()
+		quotationStore = store.NewQuotationStore()
+		orderMessageStore = store.NewOrderMessageStore()
+		revisionPurchaseStore = store.NewRevisionPurchaseStore()
+		rateLimitStore = ratelimit.NewMemoryStore()
+		feedTokenStore = store.NewFeedTokenStore()
 	}
 
 	handlers.DonateStore = donateStore
@@ -69,40 +107,164 @@ func main() {
 	handlers.PortoCfg = cfg
 	handlers.OrderStore = orderStore
 	handlers.RevisionTicketStore = revisionTicketStore
+	handlers.OrderCfg = cfg
 	handlers.AnalitikStore = analitikStore
 	handlers.AuthCfg = cfg
 	handlers.TaperStore = taperStore
 	handlers.TaperCfg = cfg
+	handlers.WebhookEventStore = webhookEventStore
+	handlers.CampaignStore = campaignStore
+	handlers.ReviewStore = reviewStore
+	handlers.SubscriptionStore = subscriptionStore
+	handlers.InquiryStore = inquiryStore
+	handlers.QuotationStore = quotationStore
+	handlers.OrderMessageStore = orderMessageStore
+	handlers.RevisionPurchaseStore = revisionPurchaseStore
+	handlers.FeedTokenStore = feedTokenStore
+	handlers.InquiryLimiter = ratelimit.PerHour("inquiry", rateLimitStore, cfg.InquiryPerHour)
+	handlers.ClientMessageLimiter = ratelimit.PerHour("message", rateLimitStore, handlers.ClientMessagesPerHour)
+	ratelimit.TrustProxies(cfg.TrustedProxies)
+	if cfg.JWTSecret == "" {
+		log.Println("WARNING: JWT_SECRET is not set; signed links (bukti transfer, batal langganan, penawaran) are disabled")
+	}
+
+	mailer := mail.FromConfig(cfg)
+	log.Printf("Mailer: %s", mailer.Name())
+	mailDispatcher := &jobs.MailDispatcher{Outbox: mailOutbox, Mailer: mailer}
+	handlers.MailOutbox = mailOutbox
+	handlers.MailDispatcher = mailDispatcher
+	go jobs.Every(context.Background(), "mail outbox", time.Duration(cfg.MailOutboxSeconds)*time.Second, func(ctx context.Context) {
+		mailDispatcher.Run(ctx)
+	})
+
+	notifier, err := notify.FromConfig(cfg, mailOutbox, func() { go mailDispatcher.Run(context.Background()) })
+	if err != nil {
+		log.Printf("Notifier: %v; falling back to log", err)
+		notifier = notify.NewLog()
+	}
+	log.Printf("Notifier: %s", notifier.Name())
+	handlers.AdminNotifier = notifier
+	deadlineWatcher := &jobs.DeadlineWatcher{Orders: orderStore, Notifier: notifier, WarnDays: cfg.DeadlineWarnDays, Hour: cfg.DeadlineDigestHour}
+	handlers.DeadlineWatcher = deadlineWatcher
+	go jobs.Every(context.Background(), "deadline digest", 10*time.Minute, deadlineWatcher.Tick)
+	revisionExpirer := &jobs.RevisionExpirer{Tickets: revisionTicketStore}
+	go jobs.Every(context.Background(), "revision ticket expiry", 10*time.Minute, func(ctx context.Context) {
+		revisionExpirer.Run(ctx)
+	})
+	go jobs.Every(context.Background(), "rate limit prune", time.Hour, func(ctx context.Context) {
+		if _, err := rateLimitStore.Prune(ctx, time.Now().Add(-24*time.Hour)); err != nil {
+			log.Printf("[ratelimit] prune: %v", err)
+		}
+	})
+
+	gateway, err := payment.FromConfig(cfg)
+	if err != nil {
+		log.Printf("Payment gateway disabled: %v", err)
+	} else {
+		handlers.PaymentGateway = gateway
+		reconciler := &jobs.DonationReconciler{Store: donateStore, Gateway: gateway, OnPaid: handlers.NotifyDonationPaid}
+		handlers.DonationReconciler = reconciler
+		go jobs.Every(context.Background(), "donation reconcile", time.Duration(cfg.ReconcileMinutes)*time.Minute, func(ctx context.Context) {
+			reconciler.Run(ctx)
+		})
+		biller := &jobs.SubscriptionBiller{
+			Subscriptions: subscriptionStore,
+			Donations:     donateStore,
+			Gateway:       gateway,
+			Highlight:     cfg.DonateHighlight,
+			Notify:        handlers.NotifySubscriptionPeriod,
+		}
+		handlers.SubscriptionBiller = biller
+		go jobs.Every(context.Background(), "subscription billing", time.Duration(cfg.SubscriptionMinutes)*time.Minute, func(ctx context.Context) {
+			biller.Run(ctx)
+		})
+	}
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.CORS)
 
+	// Endpoint publik yang mengubah data dibatasi per IP; endpoint yang memeriksa kode rahasia
+	// (kode lacak, tiket revisi, OTP, login admin, token ulasan, feed kalender) memakai batas lebih ketat dengan backoff dan lockout.
+	publicLimit := ratelimit.Public(cfg, rateLimitStore)
+	codeLimit := ratelimit.Codes(cfg, rateLimitStore)
+
 	r.Get("/", handlers.Home)
 	r.Get("/health", handlers.Health)
-	r.Post("/api/donate", handlers.DonatePost)
-	r.Post("/api/donate/create-transaction", handlers.DonateCreateTransaction)
+	r.With(publicLimit.Handler).Post("/api/donate", handlers.DonatePost)
+	r.With(publicLimit.Handler).Post("/api/donate/create-transaction", handlers.DonateCreateTransaction)
 	r.Post("/api/donate/webhook", handlers.DonateWebhook)
+	r.With(publicLimit.Handler).Post("/api/donate/{id}/proof", handlers.DonateUploadProof)
 	r.Get("/api/reviews", handlers.ReviewsList)
+	r.With(publicLimit.Handler).Post("/api/reviews", handlers.ReviewSubmit)
+	r.With(codeLimit.Handler).Post("/api/reviews/verified", handlers.ReviewSubmitVerified)
+	r.Get("/api/campaigns", handlers.CampaignsList)
+	r.Get("/api/donations/recent", handlers.DonationsRecent)
+	r.Get("/api/donations/leaderboard", handlers.DonationsLeaderboard)
+	r.Get("/api/subscriptions/plans", handlers.SubscriptionPlansList)
+	r.With(publicLimit.Handler).Post("/api/subscriptions", handlers.SubscriptionCreate)
+	r.Get("/api/subscriptions/{id}", handlers.SubscriptionGet)
+	r.With(publicLimit.Handler).Post("/api/subscriptions/{id}/cancel", handlers.SubscriptionCancel)
 	r.Get("/api/services", handlers.ServicesList)
 	r.Get("/api/porto", handlers.PortoList)
 	r.Get("/api/analitik", handlers.AnalitikList)
 	r.Get("/api/orders/antrian", handlers.OrdersAntrian)
-	r.Post("/api/revisi/klaim", handlers.RevisiKlaim)
-	r.Post("/api/auth/admin", handlers.AuthAdmin)
-	r.Post("/api/taper/verify", handlers.TaperVerify)
-	r.Post("/api/taper/sign", handlers.TaperSign)
+	r.With(codeLimit.Handler).Get("/api/orders/track/{code}", handlers.OrderTrack)
+	r.With(codeLimit.Handler).Get("/api/orders/track/{code}/messages", handlers.OrderTrackMessages)
+	r.With(codeLimit.Handler).Post("/api/orders/track/{code}/messages", handlers.OrderTrackPostMessage)
+	r.With(codeLimit.Handler).Get("/api/orders/track/{code}/attachments/{id}", handlers.OrderTrackAttachment)
+	r.With(codeLimit.Handler).Get("/api/orders/track/{code}/revisi-tambahan", handlers.OrderTrackRevisionPurchases)
+	r.With(codeLimit.Handler).Post("/api/orders/track/{code}/revisi-tambahan", handlers.OrderTrackRevisionPurchase)
+	r.With(publicLimit.Handler).Post("/api/inquiries", handlers.InquirySubmit)
+	r.Get("/api/quotations/{id}", handlers.QuotationPublic)
+	r.Get("/api/quotations/{id}/pdf", handlers.QuotationPDFPublic)
+	r.With(publicLimit.Handler).Post("/api/quotations/{id}/accept", handlers.QuotationAccept)
+	r.With(publicLimit.Handler).Post("/api/quotations/{id}/decline", handlers.QuotationDecline)
+	r.With(codeLimit.Handler).Post("/api/revisi/klaim", handlers.RevisiKlaim)
+	r.With(codeLimit.Handler).Get("/api/revisi/{code}", handlers.RevisiStatus)
+	r.With(codeLimit.Handler).Get("/api/revisi/{code}/attachments/{id}", handlers.RevisiAttachment)
+	r.With(codeLimit.Handler).Post("/api/revisi/{code}/accept", handlers.RevisiAccept)
+	r.With(codeLimit.Handler).Post("/api/auth/admin", handlers.AuthAdmin)
+	r.With(codeLimit.Handler).Post("/api/taper/verify", handlers.TaperVerify)
+	r.With(publicLimit.Handler).Post("/api/taper/sign", handlers.TaperSign)
+	r.With(codeLimit.Handler).Get("/api/calendar/orders.ics", handlers.OrdersCalendar)
 
 	r.Handle("/uploads/*", http.StripPrefix("/uploads", http.FileServer(http.Dir(cfg.UploadDir))))
 
 	r.Group(func(r chi.Router) {
 		r.Use(mw.AdminKey(cfg))
 		r.Get("/api/admin/donations", handlers.DonationsListAll)
+		r.Post("/api/admin/donations/reconcile", handlers.DonationsReconcile)
+		r.Post("/api/admin/donations/{id}/review", handlers.DonationReview)
+		r.Get("/api/admin/donations/{id}/proof", handlers.DonationProof)
+		r.Get("/api/admin/campaigns", handlers.CampaignsListAdmin)
+		r.Post("/api/admin/campaigns", handlers.CampaignsAdd)
+		r.Put("/api/admin/campaigns", handlers.CampaignsUpdate)
+		r.Delete("/api/admin/campaigns", handlers.CampaignsDelete)
+		r.Get("/api/admin/subscriptions", handlers.SubscriptionsListAdmin)
+		r.Post("/api/admin/subscriptions/run", handlers.SubscriptionsRun)
+		r.Post("/api/admin/subscriptions/{id}/cancel", handlers.SubscriptionCancelAdmin)
+		r.Get("/api/admin/subscriptions/plans", handlers.SubscriptionPlansListAdmin)
+		r.Post("/api/admin/subscriptions/plans", handlers.SubscriptionPlanAdd)
+		r.Put("/api/admin/subscriptions/plans/{id}", handlers.SubscriptionPlanUpdate)
+		r.Get("/api/admin/reviews", handlers.ReviewsListAdmin)
+		r.Post("/api/admin/reviews", handlers.ReviewsAdd)
+		r.Post("/api/admin/reviews/{id}/moderate", handlers.ReviewModerate)
+		r.Delete("/api/admin/reviews", handlers.ReviewsDelete)
+		r.Get("/api/admin/privacy/export", handlers.PrivacyExport)
+		r.Post("/api/admin/privacy/erase", handlers.PrivacyErase)
+		r.Get("/api/admin/mail/outbox", handlers.MailOutboxList)
+		r.Post("/api/admin/mail/outbox/{id}/retry", handlers.MailOutboxRetry)
+		r.Get("/api/admin/webhooks", handlers.WebhookEventsList)
+		r.Get("/api/admin/webhooks/{id}", handlers.WebhookEventGet)
+		r.Post("/api/admin/webhooks/{id}/replay", handlers.WebhookEventReplay)
 		r.Get("/api/admin/services", handlers.ServicesListAdmin)
 		r.Post("/api/admin/services", handlers.ServicesAdd)
 		r.Put("/api/admin/services", handlers.ServicesUpdate)
 		r.Post("/api/admin/services/close", handlers.ServicesSetClosed)
+		r.Post("/api/admin/services/queue", handlers.ServicesSetQueue)
+		r.Post("/api/admin/services/tier", handlers.ServicesSetTier)
 		r.Delete("/api/admin/services", handlers.ServicesDelete)
 		r.Get("/api/admin/analitik", handlers.AnalitikListAdmin)
 		r.Post("/api/admin/analitik", handlers.AnalitikAdd)
@@ -116,6 +278,31 @@ func main() {
 		r.Get("/api/admin/orders", handlers.OrdersListAll)
 		r.Post("/api/admin/orders", handlers.OrdersAdd)
 		r.Patch("/api/admin/orders", handlers.OrdersComplete)
+		r.Get("/api/admin/orders/attention", handlers.OrdersAttention)
+		r.Get("/api/admin/calendar/orders", handlers.OrdersCalendarURL)
+		r.Post("/api/admin/calendar/orders/regenerate", handlers.OrdersCalendarRegenerate)
+		r.Post("/api/admin/orders/attention/notify", handlers.OrdersAttentionNotify)
+		r.Get("/api/admin/inquiries", handlers.InquiriesList)
+		r.Patch("/api/admin/inquiries/{id}", handlers.InquirySetStatus)
+		r.Post("/api/admin/inquiries/{id}/convert", handlers.InquiryConvert)
+		r.Get("/api/admin/orders/{id}/messages", handlers.OrderMessages)
+		r.Post("/api/admin/orders/{id}/messages", handlers.OrderPostMessage)
+		r.Get("/api/admin/orders/{id}/attachments/{aid}", handlers.OrderAttachment)
+		r.Post("/api/admin/orders/{id}/tickets", handlers.OrderIssueTickets)
+		r.Get("/api/admin/orders/{id}/tickets/pdf", handlers.RevisionVoucherPDF)
+		r.Post("/api/admin/orders/{id}/tickets/send", handlers.RevisionVoucherSend)
+		r.Patch("/api/admin/revisions/{id}", handlers.RevisionSetStatus)
+		r.Post("/api/admin/revisions/{id}/extend", handlers.RevisionExtend)
+		r.Get("/api/admin/revisions/{id}/attachments/{aid}", handlers.RevisionAttachment)
+		r.Get("/api/admin/quotations", handlers.QuotationsList)
+		r.Post("/api/admin/quotations", handlers.QuotationCreate)
+		r.Put("/api/admin/quotations/{id}", handlers.QuotationUpdate)
+		r.Post("/api/admin/quotations/{id}/send", handlers.QuotationSend)
+		r.Get("/api/admin/quotations/{id}/pdf", handlers.QuotationPDFAdmin)
+		r.Get("/api/admin/quotations/{id}/agreement", handlers.QuotationAgreement)
+		r.Put("/api/admin/orders/{id}", handlers.OrdersUpdate)
+		r.Patch("/api/admin/orders/{id}/status", handlers.OrderSetStatus)
+		r.Get("/api/admin/orders/{id}/events", handlers.OrderEvents)
 		r.Delete("/api/admin/orders", handlers.OrdersDelete)
 		r.Get("/api/admin/agreement/sample", handlers.AgreementSamplePDF)
 		r.Post("/api/admin/agreement/pdf", handlers.AgreementPDF)
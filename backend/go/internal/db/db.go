@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"log"
+	"time"
+
+	"backend/internal/store"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -25,6 +28,11 @@ func Open(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// donationReviewName is the public name of a donation-sourced review, seperti Donation.PublicName:
+// nama asli bila donatur setuju dipublikasikan, selain itu disamarkan ("Budi Santoso" -> "B*** S***").
+const donationReviewName = `CASE WHEN btrim(name) = '' THEN 'Anonim' WHEN consent_publish THEN name
+	ELSE regexp_replace(btrim(name), '(\S)\S*', '\1***', 'g') END`
+
 // Migrate creates tables if they don't exist. Idempotent.
 func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
 	queries := []string{
@@ -72,6 +80,47 @@ func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
 			kapan_uang_masuk TEXT NOT NULL DEFAULT '',
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS proof_path TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS proof_uploaded_at TIMESTAMPTZ`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS admin_note TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS anonymous BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS consent_publish BOOLEAN NOT NULL DEFAULT false`,
+		// reviews dibuat sekali; saat dibuat, ulasan lama (komentar donasi non-highlight yang sudah dibayar)
+		// dipindah sebagai approved agar halaman publik tidak kosong. Donasi baru tidak lagi jadi ulasan.
+		// Berjalan sebelum backfill status donasi di bawah (status kosong = donasi lama yang dulu dianggap lunas);
+		// donasi anonim tidak dipindah, dan nama disamarkan bila donatur belum setuju dipublikasikan.
+		`DO $$ BEGIN
+			IF to_regclass('reviews') IS NULL THEN
+				CREATE TABLE reviews (
+					id TEXT PRIMARY KEY,
+					rating INT NOT NULL DEFAULT 0,
+					display_name TEXT NOT NULL DEFAULT '',
+					comment TEXT NOT NULL DEFAULT '',
+					email TEXT NOT NULL DEFAULT '',
+					order_id TEXT NOT NULL DEFAULT '',
+					service_id TEXT NOT NULL DEFAULT '',
+					source TEXT NOT NULL DEFAULT 'form',
+					status TEXT NOT NULL DEFAULT 'pending',
+					admin_note TEXT NOT NULL DEFAULT '',
+					moderated_at TIMESTAMPTZ,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+				);
+				INSERT INTO reviews (id, display_name, comment, source, status, created_at)
+					SELECT 'don-' || id, ` + donationReviewName + `, comment, 'donation', 'approved', created_at FROM donations
+					WHERE highlighted = false AND comment <> '' AND anonymous = false
+					AND status IN ('', 'settlement', 'capture', 'confirmed');
+			END IF;
+		END $$`,
+		// Terapkan aturan nama publik yang sama pada ulasan yang sudah terlanjur dipindah.
+		`UPDATE reviews r SET display_name = CASE WHEN d.anonymous THEN 'Anonim' ELSE ` + donationReviewName + ` END
+			FROM donations d WHERE r.source = 'donation' AND r.id = 'don-' || d.id
+			AND r.display_name IS DISTINCT FROM (CASE WHEN d.anonymous THEN 'Anonim' ELSE ` + donationReviewName + ` END)`,
+		// Donasi lama tanpa status sudah diterima: transfer bank dianggap confirmed, dan donasi online dulu hanya disimpan
+		// webhook saat lunas, jadi settlement. Bukan pending agar reconciler tidak memprosesnya ulang (kuitansi/notifikasi ganda).
+		`UPDATE donations SET status = 'confirmed' WHERE status = '' AND order_id IS NULL`,
+		`UPDATE donations SET status = 'settlement' WHERE status = '' AND order_id IS NOT NULL`,
 		`ALTER TABLE porto ADD COLUMN IF NOT EXISTS closed BOOLEAN NOT NULL DEFAULT false`,
 		`ALTER TABLE porto ADD COLUMN IF NOT EXISTS tools_used JSONB NOT NULL DEFAULT '[]'`,
 		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS pemesan TEXT NOT NULL DEFAULT ''`,
@@ -110,12 +159,267 @@ func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
 			closed BOOLEAN NOT NULL DEFAULT false,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)`,
+		`CREATE TABLE IF NOT EXISTS campaigns (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			target_amount INT NOT NULL DEFAULT 0,
+			start_date DATE NOT NULL,
+			end_date DATE,
+			cover_image_url TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS campaign_id TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS webhook_events (
+			id TEXT PRIMARY KEY,
+			gateway TEXT NOT NULL DEFAULT '',
+			order_id TEXT NOT NULL DEFAULT '',
+			event_key TEXT NOT NULL DEFAULT '',
+			payload TEXT NOT NULL,
+			headers JSONB NOT NULL DEFAULT '{}',
+			verified BOOLEAN NOT NULL DEFAULT false,
+			status TEXT NOT NULL DEFAULT 'received',
+			outcome TEXT NOT NULL DEFAULT '',
+			attempts INT NOT NULL DEFAULT 0,
+			received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			processed_at TIMESTAMPTZ
+		)`,
+		`CREATE INDEX IF NOT EXISTS webhook_events_event_key_idx ON webhook_events (event_key)`,
+		// processed_key: event key yang diklaim sebelum diproses; unik agar event yang sama tidak diproses dua kali bersamaan.
+		`ALTER TABLE webhook_events ADD COLUMN IF NOT EXISTS processed_key TEXT`,
+		`UPDATE webhook_events w SET processed_key = w.event_key
+			WHERE w.processed_key IS NULL AND w.id IN (
+				SELECT DISTINCT ON (event_key) id FROM webhook_events
+				WHERE status = 'processed' AND event_key <> '' ORDER BY event_key, received_at)
+			AND NOT EXISTS (SELECT 1 FROM webhook_events x WHERE x.processed_key = w.event_key)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS webhook_events_processed_key_idx ON webhook_events (processed_key)`,
+		`ALTER TABLE reviews ADD COLUMN IF NOT EXISTS layanan TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE reviews ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT false`,
+		`CREATE TABLE IF NOT EXISTS review_tokens (
+			token TEXT PRIMARY KEY,
+			order_id TEXT NOT NULL UNIQUE,
+			layanan TEXT NOT NULL DEFAULT '',
+			service_id TEXT NOT NULL DEFAULT '',
+			used_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS mail_outbox (
+			id TEXT PRIMARY KEY,
+			key TEXT NOT NULL UNIQUE,
+			recipient TEXT NOT NULL,
+			subject TEXT NOT NULL DEFAULT '',
+			text_body TEXT NOT NULL DEFAULT '',
+			html_body TEXT NOT NULL DEFAULT '',
+			attachments JSONB NOT NULL DEFAULT '[]',
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			sent_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS mail_outbox_due_idx ON mail_outbox (status, next_attempt_at)`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS email_pemesan TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS subscription_plans (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			amount INT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS subscriptions (
+			id TEXT PRIMARY KEY,
+			plan_id TEXT NOT NULL,
+			plan_name TEXT NOT NULL DEFAULT '',
+			amount INT NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL,
+			anonymous BOOLEAN NOT NULL DEFAULT false,
+			consent_publish BOOLEAN NOT NULL DEFAULT false,
+			status TEXT NOT NULL DEFAULT 'active',
+			anchor_day INT NOT NULL DEFAULT 1,
+			next_charge_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			period_order_id TEXT NOT NULL DEFAULT '',
+			period_started_at TIMESTAMPTZ,
+			period_paid BOOLEAN NOT NULL DEFAULT false,
+			missed_periods INT NOT NULL DEFAULT 0,
+			last_paid_at TIMESTAMPTZ,
+			cancelled_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS subscriptions_due_idx ON subscriptions (status, next_charge_at)`,
+		`ALTER TABLE donations ADD COLUMN IF NOT EXISTS subscription_id TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS order_events (
+			id TEXT PRIMARY KEY,
+			order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			from_status TEXT NOT NULL DEFAULT '',
+			to_status TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS order_events_order_idx ON order_events (order_id, created_at)`,
+		// Tanggal & nilai order bertipe: kolom teks lama dipindah ke *_legacy, diisi ulang oleh backfillOrderFields.
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'orders' AND column_name = 'deadline_legacy') THEN
+				ALTER TABLE orders RENAME COLUMN deadline TO deadline_legacy;
+				ALTER TABLE orders RENAME COLUMN mulai_tanggal TO mulai_tanggal_legacy;
+				ALTER TABLE orders RENAME COLUMN kapan_uang_masuk TO kapan_uang_masuk_legacy;
+				ALTER TABLE orders ADD COLUMN deadline DATE, ADD COLUMN mulai_tanggal DATE, ADD COLUMN kapan_uang_masuk DATE;
+			END IF;
+		END $$`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS nilai_kesepakatan BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS legacy_parsed BOOLEAN NOT NULL DEFAULT false`,
+		`CREATE INDEX IF NOT EXISTS orders_deadline_idx ON orders (deadline)`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS jumlah_dibayar BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS tracking_code TEXT NOT NULL DEFAULT ''`,
+		`UPDATE orders SET tracking_code = 'TRK-' || upper(substr(md5(random()::text || id), 1, 12)) WHERE tracking_code = ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS orders_tracking_code_idx ON orders (tracking_code)`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS durasi_hari INT NOT NULL DEFAULT 0`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS kapasitas INT NOT NULL DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS inquiries (
+			id TEXT PRIMARY KEY,
+			nama TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL DEFAULT '',
+			whatsapp TEXT NOT NULL DEFAULT '',
+			service_ids JSONB NOT NULL DEFAULT '[]',
+			layanan JSONB NOT NULL DEFAULT '[]',
+			deskripsi TEXT NOT NULL DEFAULT '',
+			budget_min BIGINT NOT NULL DEFAULT 0,
+			budget_max BIGINT NOT NULL DEFAULT 0,
+			deadline DATE,
+			status TEXT NOT NULL DEFAULT 'new',
+			admin_note TEXT NOT NULL DEFAULT '',
+			order_id TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS inquiries_status_idx ON inquiries (status, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS quotations (
+			id TEXT PRIMARY KEY,
+			nomor TEXT NOT NULL,
+			order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			items JSONB NOT NULL DEFAULT '[]',
+			bruto BIGINT NOT NULL DEFAULT 0,
+			diskon BIGINT NOT NULL DEFAULT 0,
+			total BIGINT NOT NULL DEFAULT 0,
+			dp_percent INT NOT NULL DEFAULT 50,
+			termin2_percent INT NOT NULL DEFAULT 0,
+			termin2_waktu TEXT NOT NULL DEFAULT '',
+			berlaku_sampai DATE NOT NULL,
+			catatan TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'draft',
+			alasan_tolak TEXT NOT NULL DEFAULT '',
+			sent_at TIMESTAMPTZ,
+			responded_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS quotations_order_idx ON quotations (order_id, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS order_messages (
+			id TEXT PRIMARY KEY,
+			order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			sender TEXT NOT NULL,
+			sender_name TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			attachments JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS order_messages_order_idx ON order_messages (order_id, created_at)`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS revisi_status TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS delivery_note TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS revisi_updated_at TIMESTAMPTZ`,
+		`UPDATE revision_tickets SET revisi_status = 'requested', revisi_updated_at = used_at WHERE status = 'used' AND revisi_status = ''`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT 'standar'`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS revisi_putaran INT NOT NULL DEFAULT 2`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'included'`,
+		`CREATE TABLE IF NOT EXISTS revision_purchases (
+			id TEXT PRIMARY KEY,
+			order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			payment_order_id TEXT NOT NULL UNIQUE,
+			qty INT NOT NULL DEFAULT 1,
+			amount BIGINT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			ticket_codes JSONB NOT NULL DEFAULT '[]',
+			paid_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS revision_purchases_order_idx ON revision_purchases (order_id, created_at DESC)`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+		`ALTER TABLE revision_tickets ADD COLUMN IF NOT EXISTS expiry_note TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS revision_tickets_expiry_idx ON revision_tickets (expires_at) WHERE status = 'unused'`,
+		`CREATE TABLE IF NOT EXISTS rate_limits (
+			key TEXT PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL DEFAULT -1,
+			refilled_at TIMESTAMPTZ,
+			failures INT NOT NULL DEFAULT 0,
+			last_failure_at TIMESTAMPTZ,
+			blocked_until TIMESTAMPTZ,
+			touched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS rate_limits_touched_idx ON rate_limits (touched_at)`,
+		`CREATE TABLE IF NOT EXISTS feed_tokens (
+			name TEXT PRIMARY KEY,
+			token TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
 	}
 	for _, q := range queries {
 		if _, err := pool.Exec(ctx, q); err != nil {
 			return err
 		}
 	}
+	if err := backfillOrderFields(ctx, pool); err != nil {
+		return err
+	}
 	log.Println("Database migrations applied (tables ready)")
 	return nil
 }
+
+// backfillOrderFields parses the legacy free-text dates and money note of each order once
+// (format yang tidak dikenali dibiarkan kosong; teks aslinya tetap di kolom *_legacy).
+func backfillOrderFields(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `SELECT id, deadline_legacy, mulai_tanggal_legacy, kapan_uang_masuk_legacy, kesepakatan_brief_uang
+		FROM orders WHERE NOT legacy_parsed`)
+	if err != nil {
+		return err
+	}
+	type legacyOrder struct{ id, deadline, mulai, uangMasuk, kesepakatan string }
+	var pending []legacyOrder
+	for rows.Next() {
+		var o legacyOrder
+		if err := rows.Scan(&o.id, &o.deadline, &o.mulai, &o.uangMasuk, &o.kesepakatan); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	parsed := 0
+	for _, o := range pending {
+		date := func(s string) *time.Time {
+			if d, ok := store.ParseDate(s); ok {
+				return d.Ptr()
+			}
+			return nil
+		}
+		amount, _ := store.ParseRupiah(o.kesepakatan)
+		if _, err := pool.Exec(ctx, `UPDATE orders SET deadline = COALESCE(deadline, $2), mulai_tanggal = COALESCE(mulai_tanggal, $3),
+			kapan_uang_masuk = COALESCE(kapan_uang_masuk, $4), nilai_kesepakatan = CASE WHEN nilai_kesepakatan = 0 THEN $5 ELSE nilai_kesepakatan END,
+			legacy_parsed = true WHERE id = $1`, o.id, date(o.deadline), date(o.mulai), date(o.uangMasuk), amount); err != nil {
+			return err
+		}
+		if o.deadline != "" || o.mulai != "" || o.uangMasuk != "" || o.kesepakatan != "" {
+			parsed++
+		}
+	}
+	if parsed > 0 {
+		log.Printf("Orders: parsed legacy date/amount text of %d orders", parsed)
+	}
+	return nil
+}
@@ -3,9 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
+	"backend/internal/jobs"
+	"backend/internal/store"
 )
 
-// DonationsListAll handles GET /api/admin/donations (all donations + ulasan, for Rasya only).
+// DonationReconciler is set from main (nil bila Midtrans belum dikonfigurasi).
+var DonationReconciler *jobs.DonationReconciler
+
+// DonationsListAll handles GET /api/admin/donations?status= (all donations + ulasan, for Rasya only).
 func DonationsListAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -16,7 +22,33 @@ func DonationsListAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	list := DonateStore.ListAll()
+	if status := r.URL.Query().Get("status"); status != "" {
+		filtered := make([]store.Donation, 0, len(list))
+		for _, d := range list {
+			if d.Status == status {
+				filtered = append(filtered, d)
+			}
+		}
+		list = filtered
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "donations": list})
 }
+
+// DonationsReconcile handles POST /api/admin/donations/reconcile (cek ulang donasi pending ke Midtrans sekarang).
+func DonationsReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if DonationReconciler == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Midtrans belum dikonfigurasi (MIDTRANS_SERVER_KEY)"})
+		return
+	}
+	res := DonationReconciler.Run(r.Context())
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": res})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/store"
+)
+
+// MailOutboxList handles GET /api/admin/mail/outbox?status=pending|sent|failed.
+func MailOutboxList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list := []store.OutboxMessage{}
+	if MailOutbox != nil {
+		if l := MailOutbox.List(r.URL.Query().Get("status"), 200); l != nil {
+			list = l
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": list})
+}
+
+// MailOutboxRetry handles POST /api/admin/mail/outbox/{id}/retry (kirim ulang pesan pending/failed sekarang).
+func MailOutboxRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if MailOutbox == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	m, ok := MailOutbox.Retry(chi.URLParam(r, "id"))
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found or already sent"})
+		return
+	}
+	sent := 0
+	if MailDispatcher != nil {
+		sent = MailDispatcher.Run(r.Context())
+	}
+	if updated, ok := MailOutbox.Get(m.ID); ok {
+		m = updated
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": m, "sent": sent})
+}
@@ -79,10 +79,10 @@ func AgreementPDF(w http.ResponseWriter, r *http.Request) {
 		data.HariNum = time.Now().Format("2")
 	}
 	if data.RevisiPutaran == "" {
-		data.RevisiPutaran = "2 (dua)"
+		data.RevisiPutaran = jumlahTerbilang(tierRevisions(data.Tier))
 	}
 	if data.RevisiHari == "" {
-		data.RevisiHari = "7 (tujuh)"
+		data.RevisiHari = revisionClaimDaysText()
 	}
 	if data.KonfirmasiHari == "" {
 		data.KonfirmasiHari = "5"
@@ -119,6 +119,11 @@ func AgreementPDF(w http.ResponseWriter, r *http.Request) {
 	w.Write(pdfBytes)
 }
 
+// jumlahTerbilang formats a count the way the agreement writes it, e.g. "3 (tiga)".
+func jumlahTerbilang(n int) string {
+	return strconv.Itoa(n) + " (" + pdf.Terbilang(int64(n)) + ")"
+}
+
 // AgreementSamplePDF handles GET /api/admin/agreement/sample?tier=standar|profesional
 func AgreementSamplePDF(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -163,8 +168,8 @@ func AgreementSamplePDF(w http.ResponseWriter, r *http.Request) {
 		BankNumber:           "1234567890",
 		BankAccount:          "Rasya Production",
 		KeterlambatanHari:    "14 (empat belas)",
-		RevisiPutaran:        "2 (dua)",
-		RevisiHari:           "7 (tujuh)",
+		RevisiPutaran:        jumlahTerbilang(tierRevisions(tier)),
+		RevisiHari:           revisionClaimDaysText(),
 		KonfirmasiHari:       "5",
 		SerahTerimaHari:      "7 (tujuh)",
 		TanggungJawabHari:    "14 (empat belas)",
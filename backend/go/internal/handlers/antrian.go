@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/store"
+)
+
+// QueueEntry is one anonymized order in the public antrian (tanpa nama pemesan maupun ID order).
+type QueueEntry struct {
+	Posisi          int        `json:"posisi"`
+	Layanan         string     `json:"layanan"`
+	Kategori        string     `json:"kategori"`
+	Status          string     `json:"status"` // "dikerjakan" | "menunggu"
+	EstimasiMulai   store.Date `json:"estimasi_mulai"`
+	EstimasiSelesai store.Date `json:"estimasi_selesai"`
+}
+
+// ServiceQueue is the current load of one layanan and when a new order would start if placed today.
+type ServiceQueue struct {
+	ServiceID       string     `json:"service_id,omitempty"`
+	Layanan         string     `json:"layanan"`
+	Kategori        string     `json:"kategori"`
+	Aktif           int        `json:"aktif"`
+	Kapasitas       int        `json:"kapasitas"` // 0 = tanpa batas
+	DurasiHari      int        `json:"durasi_hari"`
+	Penuh           bool       `json:"penuh"` // kapasitas tercapai: order baru ditutup otomatis
+	EstimasiMulai   store.Date `json:"estimasi_mulai"`
+	EstimasiSelesai store.Date `json:"estimasi_selesai"`
+}
+
+// queueKey matches orders to services by title (order hanya menyimpan nama layanan).
+func queueKey(layanan string) string {
+	return strings.ToLower(strings.TrimSpace(layanan))
+}
+
+// queueStart returns when an order is (or was) planned to start: mulai_tanggal, else tanggal order dibuat.
+func queueStart(o store.OrderItem) time.Time {
+	if o.MulaiTanggal != nil {
+		return o.MulaiTanggal.Time
+	}
+	return store.NewDate(o.CreatedAt.In(wib)).Time
+}
+
+// buildQueue estimates start and finish dates of active orders per layanan.
+// Order yang sedang dikerjakan menempati slot sampai deadline-nya (atau mulai + durasi); order dp_paid
+// menunggu slot kosong berikutnya. Layanan tanpa kapasitas dianggap bisa dikerjakan paralel.
+// Returns entries in queue order and one summary per layanan (services urut, lalu layanan lain dari order).
+func buildQueue(orders []store.OrderItem, services []store.Service, now time.Time, defaultDays int) ([]QueueEntry, []ServiceQueue) {
+	if defaultDays <= 0 {
+		defaultDays = 7
+	}
+	today := store.NewDate(now.In(wib)).Time
+	type group struct {
+		summary ServiceQueue
+		running []store.OrderItem
+		waiting []store.OrderItem
+	}
+	groups := map[string]*group{}
+	var keys []string
+	for _, svc := range services {
+		k := queueKey(svc.Title)
+		if _, ok := groups[k]; ok || k == "" {
+			continue
+		}
+		days := svc.DurasiHari
+		if days <= 0 {
+			days = defaultDays
+		}
+		groups[k] = &group{summary: ServiceQueue{ServiceID: svc.ID, Layanan: svc.Title, Kategori: svc.Tag, Kapasitas: svc.Kapasitas, DurasiHari: days}}
+		keys = append(keys, k)
+	}
+	for _, o := range orders {
+		if !o.IsActive() {
+			continue
+		}
+		k := queueKey(o.Layanan)
+		if k == "" {
+			continue
+		}
+		g, ok := groups[k]
+		if !ok {
+			g = &group{summary: ServiceQueue{Layanan: strings.TrimSpace(o.Layanan), Kategori: "Lain-lain", DurasiHari: defaultDays}}
+			groups[k] = g
+			keys = append(keys, k)
+		}
+		if o.Status == store.OrderStatusDPPaid {
+			g.waiting = append(g.waiting, o)
+		} else {
+			g.running = append(g.running, o)
+		}
+	}
+
+	var entries []QueueEntry
+	summaries := make([]ServiceQueue, 0, len(keys))
+	for _, k := range keys {
+		g := groups[k]
+		days := g.summary.DurasiHari
+		capacity := g.summary.Kapasitas
+		var free []time.Time // tanggal slot kosong lagi, satu per order yang sedang jalan
+		takeSlot := func(earliest time.Time) time.Time {
+			start := today
+			if capacity > 0 && len(free) >= capacity {
+				sort.Slice(free, func(i, j int) bool { return free[i].Before(free[j]) })
+				start = free[0]
+				free = free[1:]
+			}
+			if earliest.After(start) {
+				start = earliest
+			}
+			return start
+		}
+		sort.SliceStable(g.running, func(i, j int) bool { return queueStart(g.running[i]).Before(queueStart(g.running[j])) })
+		for _, o := range g.running {
+			start := queueStart(o)
+			finish := start.AddDate(0, 0, days)
+			if o.Deadline != nil {
+				finish = o.Deadline.Time
+			}
+			if finish.Before(today) {
+				finish = today // terlambat: dianggap selesai hari ini
+			}
+			free = append(free, finish)
+			entries = append(entries, QueueEntry{Layanan: g.summary.Layanan, Kategori: g.summary.Kategori, Status: "dikerjakan",
+				EstimasiMulai: store.Date{Time: start}, EstimasiSelesai: store.Date{Time: finish}})
+		}
+		sort.SliceStable(g.waiting, func(i, j int) bool { return queueStart(g.waiting[i]).Before(queueStart(g.waiting[j])) })
+		for _, o := range g.waiting {
+			var earliest time.Time
+			if o.MulaiTanggal != nil {
+				earliest = o.MulaiTanggal.Time
+			}
+			start := takeSlot(earliest)
+			finish := start.AddDate(0, 0, days)
+			free = append(free, finish)
+			entries = append(entries, QueueEntry{Layanan: g.summary.Layanan, Kategori: g.summary.Kategori, Status: "menunggu",
+				EstimasiMulai: store.Date{Time: start}, EstimasiSelesai: store.Date{Time: finish}})
+		}
+		g.summary.Aktif = len(g.running) + len(g.waiting)
+		g.summary.Penuh = capacity > 0 && g.summary.Aktif >= capacity
+		start := takeSlot(time.Time{})
+		g.summary.EstimasiMulai = store.Date{Time: start}
+		g.summary.EstimasiSelesai = store.Date{Time: start.AddDate(0, 0, days)}
+		summaries = append(summaries, g.summary)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Status != entries[j].Status {
+			return entries[i].Status == "dikerjakan"
+		}
+		return entries[i].EstimasiMulai.Before(entries[j].EstimasiMulai.Time)
+	})
+	for i := range entries {
+		entries[i].Posisi = i + 1
+	}
+	return entries, summaries
+}
+
+// currentQueue builds the antrian from the stores (kosong bila store belum di-set).
+func currentQueue() ([]QueueEntry, []ServiceQueue) {
+	var orders []store.OrderItem
+	if OrderStore != nil {
+		orders = OrderStore.List()
+	}
+	var services []store.Service
+	if ServiceStore != nil {
+		services = ServiceStore.ListAll()
+	}
+	defaultDays := 0
+	if OrderCfg != nil {
+		defaultDays = OrderCfg.QueueDefaultDays
+	}
+	return buildQueue(orders, services, time.Now(), defaultDays)
+}
+
+// ServiceQueueRequest for POST /api/admin/services/queue.
+type ServiceQueueRequest struct {
+	ID         string `json:"id"`
+	DurasiHari int    `json:"durasi_hari"` // 0 = pakai QUEUE_DEFAULT_DAYS
+	Kapasitas  int    `json:"kapasitas"`   // 0 = tanpa batas
+}
+
+// ServicesSetQueue handles POST /api/admin/services/queue (atur durasi rata-rata dan kapasitas per layanan).
+func ServicesSetQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ServiceQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if strings.TrimSpace(req.ID) == "" || req.DurasiHari < 0 || req.Kapasitas < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "id required; durasi_hari dan kapasitas tidak boleh negatif"})
+		return
+	}
+	if ServiceStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	svc, ok := ServiceStore.SetQueueSettings(strings.TrimSpace(req.ID), req.DurasiHari, req.Kapasitas)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "service": svc})
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"backend/internal/config"
+	"backend/internal/middleware/ratelimit"
 )
 
 // AuthAdminRequest is the body for POST /api/auth/admin.
@@ -61,6 +62,7 @@ func AuthAdmin(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
+		ratelimit.Fail(r)
 		http.Error(w, "invalid token", http.StatusUnauthorized)
 		return
 	}
@@ -83,6 +85,7 @@ func AuthAdmin(w http.ResponseWriter, r *http.Request) {
 	// Check if email is allowed
 	allowed := parseAllowedEmails(AuthCfg.AdminAllowedEmail)
 	if !allowed[email] {
+		ratelimit.Fail(r)
 		http.Error(w, "unauthorized", http.StatusForbidden)
 		return
 	}
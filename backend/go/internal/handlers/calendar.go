@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/ics"
+	"backend/internal/mail"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/store"
+)
+
+// FeedTokenStore holds the secrets of subscribable feeds (injected in main).
+var FeedTokenStore *store.FeedTokenStore
+
+// calendarFeedName is the FeedTokenStore name of the order calendar feed.
+// Token tidak kedaluwarsa; cabut link lama lewat POST /api/admin/calendar/orders/regenerate.
+const calendarFeedName = "calendar-orders"
+
+// orderCalendarEvents returns the VEVENTs of one order: mulai, deadline, uang masuk dan selesai.
+// UID memakai ID order + jenis tanggal sehingga tetap sama walau tanggalnya diubah.
+func orderCalendarEvents(o store.OrderItem) []ics.Event {
+	title := o.Layanan
+	if o.Pemesan != "" {
+		title += " - " + o.Pemesan
+	}
+	desc := "Status: " + o.Status
+	if o.DeskripsiPekerjaan != "" {
+		desc += "\n" + o.DeskripsiPekerjaan
+	}
+	if o.NilaiKesepakatan > 0 {
+		desc += "\nNilai: " + mail.FormatIDR(int(o.NilaiKesepakatan))
+	}
+	var out []ics.Event
+	add := func(kind, label string, d *store.Date) {
+		if d == nil {
+			return
+		}
+		out = append(out, ics.Event{
+			UID:         o.ID + "-" + kind + "@raspro",
+			Date:        d.Time,
+			Summary:     label + ": " + title,
+			Description: desc,
+			Categories:  "Order " + label,
+		})
+	}
+	add("start", "Mulai", o.MulaiTanggal)
+	add("deadline", "Deadline", o.Deadline)
+	add("payment", "Uang masuk", o.KapanUangMasuk)
+	if o.CompletedAt != nil {
+		done := store.NewDate(o.CompletedAt.In(wib))
+		add("completed", "Selesai", &done)
+	}
+	return out
+}
+
+// OrdersCalendar handles GET /api/calendar/orders.ics?token=xxx[&completed=0].
+// Feed untuk langganan Google Calendar/Thunderbird (tidak bisa kirim header Authorization, jadi token di URL).
+// completed=0 menyembunyikan order yang sudah selesai; order batal selalu disembunyikan.
+func OrdersCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !validCalendarToken(r.URL.Query().Get("token")) {
+		ratelimit.Fail(r)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	hideCompleted := r.URL.Query().Get("completed") == "0"
+	cal := ics.Calendar{Name: "Raspro - Order", Refresh: time.Hour}
+	if OrderStore != nil {
+		for _, o := range OrderStore.List() {
+			if o.Status == store.OrderStatusCancelled || (hideCompleted && o.Status == store.OrderStatusCompleted) {
+				continue
+			}
+			cal.Events = append(cal.Events, orderCalendarEvents(o)...)
+		}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="orders.ics"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_ = ics.Write(w, cal, time.Now())
+}
+
+// validCalendarToken reports whether token is the current calendar feed token.
+func validCalendarToken(token string) bool {
+	if FeedTokenStore == nil || token == "" {
+		return false
+	}
+	t, ok := FeedTokenStore.Get(calendarFeedName)
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) == 1
+}
+
+// calendarFeedResponse writes the feed links for token.
+func calendarFeedResponse(w http.ResponseWriter, r *http.Request, t store.FeedToken) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = strings.TrimSpace(strings.Split(p, ",")[0])
+	}
+	q := url.Values{"token": {t.Token}}
+	feed := scheme + "://" + r.Host + "/api/calendar/orders.ics?" + q.Encode()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":                 true,
+		"url":                feed,
+		"url_hide_completed": feed + "&completed=0",
+		"webcal_url":         "webcal://" + strings.TrimPrefix(strings.TrimPrefix(feed, "https://"), "http://"),
+		"created_at":         t.CreatedAt,
+	})
+}
+
+// OrdersCalendarURL handles GET /api/admin/calendar/orders (link feed .ics untuk disalin ke aplikasi kalender).
+// Token dibuat saat pertama kali diminta.
+func OrdersCalendarURL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if FeedTokenStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t, ok := FeedTokenStore.Ensure(calendarFeedName)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	calendarFeedResponse(w, r, t)
+}
+
+// OrdersCalendarRegenerate handles POST /api/admin/calendar/orders/regenerate: ganti token feed kalender.
+// Link lama langsung tidak berlaku; langganan kalender perlu ditambahkan ulang dengan link baru.
+func OrdersCalendarRegenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if FeedTokenStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t, ok := FeedTokenStore.Rotate(calendarFeedName)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[calendar] feed token regenerated by %s", orderActor(r))
+	calendarFeedResponse(w, r, t)
+}
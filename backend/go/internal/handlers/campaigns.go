@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/store"
+)
+
+// CampaignStore is the donation campaign store (injected in main).
+var CampaignStore *store.CampaignStore
+
+// CampaignProgress is a campaign with its paid progress (public & admin).
+type CampaignProgress struct {
+	store.Campaign
+	Collected       int     `json:"collected"`
+	DonorCount      int     `json:"donor_count"`
+	ProgressPercent float64 `json:"progress_percent"` // bisa > 100 bila target terlampaui
+	IsOpen          bool    `json:"is_open"`
+}
+
+func campaignProgressList() []CampaignProgress {
+	if CampaignStore == nil {
+		return []CampaignProgress{}
+	}
+	totals := map[string]store.CampaignTotal{}
+	if DonateStore != nil {
+		totals = DonateStore.CampaignTotals()
+	}
+	now := time.Now()
+	list := CampaignStore.List()
+	out := make([]CampaignProgress, 0, len(list))
+	for _, c := range list {
+		t := totals[c.ID]
+		p := CampaignProgress{Campaign: c, Collected: t.Collected, DonorCount: t.DonorCount, IsOpen: c.IsOpen(now)}
+		if c.TargetAmount > 0 {
+			p.ProgressPercent = math.Round(float64(t.Collected)*1000/float64(c.TargetAmount)) / 10
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// validateDonationCampaign returns an error message if campaignID is set but unknown or not open.
+func validateDonationCampaign(campaignID string) string {
+	if campaignID == "" {
+		return ""
+	}
+	if CampaignStore == nil {
+		return "campaign tidak ditemukan"
+	}
+	c, ok := CampaignStore.Get(campaignID)
+	if !ok {
+		return "campaign tidak ditemukan"
+	}
+	if !c.IsOpen(time.Now()) {
+		return "campaign sudah ditutup atau belum dimulai"
+	}
+	return ""
+}
+
+// CampaignsList handles GET /api/campaigns (public: campaign + terkumpul, jumlah donatur, persen progress).
+func CampaignsList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "campaigns": campaignProgressList()})
+}
+
+// CampaignsListAdmin handles GET /api/admin/campaigns.
+func CampaignsListAdmin(w http.ResponseWriter, r *http.Request) {
+	CampaignsList(w, r)
+}
+
+// parseCampaignForm reads title, description, target_amount, start_date, end_date (YYYY-MM-DD) and optional cover image.
+func parseCampaignForm(r *http.Request) (store.Campaign, string) {
+	var c store.Campaign
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		return c, "invalid form or file too large (max 10MB)"
+	}
+	c.ID = strings.TrimSpace(r.FormValue("id"))
+	c.Title = strings.TrimSpace(r.FormValue("title"))
+	c.Description = strings.TrimSpace(r.FormValue("description"))
+	if c.Title == "" {
+		return c, "title required"
+	}
+	target, err := strconv.Atoi(strings.TrimSpace(r.FormValue("target_amount")))
+	if err != nil || target <= 0 {
+		return c, "target_amount must be > 0"
+	}
+	c.TargetAmount = target
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(r.FormValue("start_date")))
+	if err != nil {
+		return c, "start_date required (YYYY-MM-DD)"
+	}
+	c.StartDate = start
+	if v := strings.TrimSpace(r.FormValue("end_date")); v != "" {
+		end, err := time.Parse("2006-01-02", v)
+		if err != nil || end.Before(start) {
+			return c, "end_date must be YYYY-MM-DD and not before start_date"
+		}
+		c.EndDate = &end
+	}
+	file, header, err := r.FormFile("cover")
+	if err != nil {
+		return c, ""
+	}
+	defer file.Close()
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".webp":
+	default:
+		return c, "cover must be jpg, png or webp"
+	}
+	uploadDir := "uploads"
+	if DonateCfg != nil && DonateCfg.UploadDir != "" {
+		uploadDir = DonateCfg.UploadDir
+	}
+	dir := filepath.Join(uploadDir, "campaigns")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return c, "failed to save cover"
+	}
+	filename := uniqueFilename() + ext
+	dst, err := os.Create(filepath.Join(dir, filename))
+	if err != nil {
+		return c, "failed to save cover"
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, file); err != nil {
+		os.Remove(filepath.Join(dir, filename))
+		return c, "failed to save cover"
+	}
+	c.CoverImageURL = "/uploads/campaigns/" + filename
+	return c, ""
+}
+
+// CampaignsAdd handles POST /api/admin/campaigns (multipart: title, description, target_amount, start_date, end_date, cover).
+func CampaignsAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if CampaignStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	c, msg := parseCampaignForm(r)
+	w.Header().Set("Content-Type", "application/json")
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	c = CampaignStore.Add(c)
+	if c.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "campaign": c})
+}
+
+// CampaignsUpdate handles PUT /api/admin/campaigns (multipart, sama dengan add + id; cover opsional).
+func CampaignsUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if CampaignStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	c, msg := parseCampaignForm(r)
+	w.Header().Set("Content-Type", "application/json")
+	if msg == "" && c.ID == "" {
+		msg = "id required"
+	}
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	c, ok := CampaignStore.Update(c)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "campaign": c})
+}
+
+// CampaignsDelete handles DELETE /api/admin/campaigns?id=xxx.
+func CampaignsDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if CampaignStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ok := CampaignStore.Delete(id)
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+	}
+}
@@ -3,6 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/store"
@@ -12,16 +15,22 @@ const highlightThresholdIDR = 50000
 
 // DonateRequest is the JSON body for POST /api/donate.
 type DonateRequest struct {
-	Amount  int    `json:"amount"`
-	Comment string `json:"comment"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
+	Amount         int    `json:"amount"`
+	Comment        string `json:"comment"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	CampaignID     string `json:"campaign_id"`     // opsional
+	Anonymous      bool   `json:"anonymous"`       // nama tidak ditampilkan publik
+	ConsentPublish bool   `json:"consent_publish"` // setuju nama & pesan tampil publik
 }
 
 // DonateResponse is returned after successful donate.
+// DonationID + ProofToken dipakai donatur untuk unggah bukti transfer (POST /api/donate/{id}/proof).
 type DonateResponse struct {
 	OK          bool   `json:"ok"`
 	Message     string `json:"message"`
+	DonationID  string `json:"donation_id,omitempty"`
+	ProofToken  string `json:"proof_token,omitempty"`
 	BankName    string `json:"bank_name,omitempty"`
 	BankNumber  string `json:"bank_number,omitempty"`
 	BankAccount string `json:"bank_account,omitempty"`
@@ -50,31 +59,43 @@ func DonatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	threshold := highlightThresholdIDR
-	if DonateCfg != nil && DonateCfg.DonateHighlight > 0 {
-		threshold = DonateCfg.DonateHighlight
+	req.CampaignID = strings.TrimSpace(req.CampaignID)
+	if msg := validateDonationCampaign(req.CampaignID); msg != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
 	}
-	highlighted := req.Amount >= threshold
+	highlighted := req.Amount >= donateHighlightThreshold()
 
+	// Belum dihitung sebagai donasi sampai bukti transfer dikonfirmasi admin.
 	d := store.Donation{
-		Amount:      req.Amount,
-		Comment:     req.Comment,
-		Name:        req.Name,
-		Email:       req.Email,
-		Highlighted: highlighted,
+		Amount:         req.Amount,
+		Comment:        req.Comment,
+		Name:           req.Name,
+		Email:          req.Email,
+		Highlighted:    highlighted,
+		Status:         store.DonationStatusAwaitingTransfer,
+		CampaignID:     req.CampaignID,
+		Anonymous:      req.Anonymous,
+		ConsentPublish: req.ConsentPublish,
 	}
 	if DonateStore != nil {
-		DonateStore.Add(d)
+		d = DonateStore.Add(d)
 	}
 
 	resp := DonateResponse{
 		OK:          true,
-		Message:     "Terima kasih. Silakan transfer ke rekening di bawah.",
+		Message:     "Terima kasih. Silakan transfer ke rekening di bawah, lalu unggah bukti transfer.",
+		DonationID:  d.ID,
 		BankName:    "",
 		BankNumber:  "",
 		BankAccount: "",
 		Highlighted: highlighted,
 	}
+	if d.ID != "" {
+		resp.ProofToken = signedToken("donation-proof", d.ID)
+	}
 	if DonateCfg != nil {
 		resp.BankName = DonateCfg.BankName
 		resp.BankNumber = DonateCfg.BankNumber
@@ -85,3 +106,51 @@ func DonatePost(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+// donateHighlightThreshold returns DONATE_HIGHLIGHT_IDR or the default.
+func donateHighlightThreshold() int {
+	if DonateCfg != nil && DonateCfg.DonateHighlight > 0 {
+		return DonateCfg.DonateHighlight
+	}
+	return highlightThresholdIDR
+}
+
+// PublicDonation is a paid donation as shown publicly (nama disamarkan sesuai pilihan donatur, tanpa email).
+type PublicDonation struct {
+	Name       string    `json:"name"`
+	Amount     int       `json:"amount"`
+	Comment    string    `json:"comment,omitempty"`
+	CampaignID string    `json:"campaign_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func toPublicDonation(d store.Donation) PublicDonation {
+	return PublicDonation{
+		Name:       d.PublicName(),
+		Amount:     d.Amount,
+		Comment:    d.PublicComment(),
+		CampaignID: d.CampaignID,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+// DonationsRecent handles GET /api/donations/recent?limit=20 (public: donasi lunas terbaru).
+func DonationsRecent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := 20
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 100 {
+		limit = n
+	}
+	list := []PublicDonation{}
+	if DonateStore != nil {
+		for _, d := range DonateStore.ListPaid(limit) {
+			list = append(list, toPublicDonation(d))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "donations": list})
+}
@@ -1,38 +1,45 @@
 package handlers
 
 import (
-	"bytes"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"backend/internal/payment"
 	"backend/internal/store"
 )
 
-const midtransSandboxURL = "https://app.sandbox.midtrans.com/snap/v1/transactions"
-const midtransProductionURL = "https://app.midtrans.com/snap/v1/transactions"
-
 // DonateCreateTransactionRequest is the body for POST /api/donate/create-transaction.
 type DonateCreateTransactionRequest struct {
-	Amount  int    `json:"amount"`
-	Comment string `json:"comment"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
+	Amount         int    `json:"amount"`
+	Comment        string `json:"comment"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	CampaignID     string `json:"campaign_id"`     // opsional
+	Anonymous      bool   `json:"anonymous"`       // nama tidak ditampilkan publik
+	ConsentPublish bool   `json:"consent_publish"` // setuju nama & pesan tampil publik
 }
 
-// DonateCreateTransactionResponse returns snap_token for frontend Snap modal.
+// DonateCreateTransactionResponse returns snap_token for frontend Snap modal (Midtrans) or redirect_url (Xendit).
 type DonateCreateTransactionResponse struct {
-	OK         bool   `json:"ok"`
-	Message    string `json:"message,omitempty"`
-	SnapToken  string `json:"snap_token,omitempty"`
-	OrderID    string `json:"order_id,omitempty"`
-	ClientKey  string `json:"client_key,omitempty"` // untuk frontend snap.js
+	OK          bool   `json:"ok"`
+	Message     string `json:"message,omitempty"`
+	Gateway     string `json:"gateway,omitempty"`
+	SnapToken   string `json:"snap_token,omitempty"`
+	RedirectURL string `json:"redirect_url,omitempty"`
+	OrderID     string `json:"order_id,omitempty"`
+	ClientKey   string `json:"client_key,omitempty"` // untuk frontend snap.js
 }
 
-// DonateCreateTransaction creates a Midtrans Snap transaction (GoPay dll) and returns snap_token.
+// PaymentGateway is the configured payment gateway (injected in main; nil = belum dikonfigurasi).
+var PaymentGateway payment.Gateway
+
+// DonateCreateTransaction creates a payment through PaymentGateway (Midtrans Snap GoPay / Xendit invoice).
 func DonateCreateTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -49,76 +56,63 @@ func DonateCreateTransaction(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "amount minimal 1000"})
 		return
 	}
-	if DonateCfg == nil || DonateCfg.MidtransServerKey == "" {
+	req.CampaignID = strings.TrimSpace(req.CampaignID)
+	if msg := validateDonationCampaign(req.CampaignID); msg != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	if PaymentGateway == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"ok": false, "message": "GoPay/Midtrans belum dikonfigurasi. Isi MIDTRANS_SERVER_KEY dan MIDTRANS_CLIENT_KEY di backend.",
+			"ok": false, "message": "Payment gateway belum dikonfigurasi. Isi MIDTRANS_SERVER_KEY dan MIDTRANS_CLIENT_KEY (atau XENDIT_SECRET_KEY) di backend.",
 		})
 		return
 	}
 
 	orderID := fmt.Sprintf("donate-%d-%s", time.Now().Unix(), randomHex(6))
-	baseURL := midtransSandboxURL
-	if DonateCfg.MidtransIsProduction {
-		baseURL = midtransProductionURL
-	}
-
-	// Midtrans Snap request body
-	payload := map[string]interface{}{
-		"transaction_details": map[string]interface{}{
-			"order_id":     orderID,
-			"gross_amount": req.Amount,
-		},
-		"customer_details": map[string]interface{}{
-			"first_name": req.Name,
-			"email":      req.Email,
-		},
-		"enabled_payments": []string{"gopay"},
-		"custom_field1":    req.Name,
-		"custom_field2":    req.Email,
-		"custom_field3":    req.Comment,
-	}
-	body, _ := json.Marshal(payload)
-
-	httpReq, err := http.NewRequest(http.MethodPost, baseURL, bytes.NewReader(body))
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "failed to create request"})
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	httpReq.SetBasicAuth(DonateCfg.MidtransServerKey, "")
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(httpReq)
+	tx, err := PaymentGateway.CreateTransaction(r.Context(), payment.CreateRequest{
+		OrderID:     orderID,
+		Amount:      req.Amount,
+		Description: "Donasi Rasya Production",
+		Customer:    payment.Customer{Name: req.Name, Email: req.Email},
+		Metadata:    map[string]string{"name": req.Name, "email": req.Email, "comment": req.Comment},
+	})
 	if err != nil {
+		log.Printf("[donate] create transaction via %s: %v", PaymentGateway.Name(), err)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "request to payment gateway failed"})
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "payment gateway tidak mengembalikan token"})
 		return
 	}
-	defer resp.Body.Close()
 
-	var snapResp struct {
-		Token string `json:"token"`
-		Error string `json:"error_messages,omitempty"`
-	}
-	_ = json.NewDecoder(resp.Body).Decode(&snapResp)
-	if snapResp.Token == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "payment gateway tidak mengembalikan token"})
-		return
+	// Simpan sebagai pending sejak awal agar bisa direkonsiliasi bila webhook tidak pernah datang.
+	if DonateStore != nil {
+		DonateStore.Add(store.Donation{
+			OrderID:        orderID,
+			Amount:         req.Amount,
+			Comment:        req.Comment,
+			Name:           req.Name,
+			Email:          req.Email,
+			Highlighted:    req.Amount >= donateHighlightThreshold(),
+			Status:         store.DonationStatusPending,
+			CampaignID:     req.CampaignID,
+			Anonymous:      req.Anonymous,
+			ConsentPublish: req.ConsentPublish,
+		})
 	}
 
 	out := DonateCreateTransactionResponse{
-		OK:        true,
-		SnapToken: snapResp.Token,
-		OrderID:   orderID,
-		ClientKey: DonateCfg.MidtransClientKey,
+		OK:          true,
+		Gateway:     PaymentGateway.Name(),
+		RedirectURL: tx.RedirectURL,
+		OrderID:     orderID,
+		ClientKey:   tx.ClientKey,
+	}
+	if PaymentGateway.Name() == "midtrans" {
+		out.SnapToken = tx.Token
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -135,80 +129,80 @@ func randomHex(n int) string {
 	return string(b)
 }
 
-func parseGrossAmount(v interface{}) int {
-	if v == nil {
-		return 0
-	}
-	switch x := v.(type) {
-	case string:
-		var n int
-		_, _ = fmt.Sscanf(x, "%d", &n)
-		return n
-	case float64:
-		return int(x)
-	case int:
-		return x
-	case int64:
-		return int(x)
-	default:
-		return 0
-	}
-}
-
-// MidtransNotification is the webhook payload from Midtrans.
-type MidtransNotification struct {
-	TransactionStatus string      `json:"transaction_status"`
-	OrderID           string      `json:"order_id"`
-	GrossAmount       interface{} `json:"gross_amount"` // Midtrans bisa kirim string "50000.00" atau number
-	CustomField1      string      `json:"custom_field1"`
-	CustomField2      string      `json:"custom_field2"`
-	CustomField3      string      `json:"custom_field3"`
-}
-
-// DonateWebhook handles POST /api/donate/webhook (Midtrans notification).
+// DonateWebhook handles POST /api/donate/webhook (notifikasi dari payment gateway aktif).
+// Setiap notifikasi disimpan verbatim di inbox webhook_events sebelum diproses.
 func DonateWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var notif MidtransNotification
-	if err := json.NewDecoder(r.Body).Decode(&notif); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+	if PaymentGateway == nil {
+		http.Error(w, "payment gateway not configured", http.StatusServiceUnavailable)
 		return
 	}
-	// Idempotency: jangan tambah dua kali untuk order_id yang sama
-	if DonateStore != nil {
-		if _, exists := DonateStore.FindByOrderID(notif.OrderID); exists {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-	}
-	// Hanya simpan saat pembayaran berhasil (settlement) atau pending (GoPay kadang pending dulu)
-	status := strings.ToLower(notif.TransactionStatus)
-	if status != "settlement" && status != "pending" {
-		w.WriteHeader(http.StatusOK)
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
 		return
 	}
-	amount := parseGrossAmount(notif.GrossAmount)
-	if amount <= 0 {
-		w.WriteHeader(http.StatusOK)
-		return
+	ev := store.WebhookEvent{Gateway: PaymentGateway.Name(), Payload: string(body), Headers: r.Header}
+	if WebhookEventStore != nil {
+		if saved := WebhookEventStore.Add(PaymentGateway.Name(), string(body), r.Header); saved.ID != "" {
+			ev = saved
+		}
 	}
-	threshold := highlightThresholdIDR
-	if DonateCfg != nil && DonateCfg.DonateHighlight > 0 {
-		threshold = DonateCfg.DonateHighlight
+	status, outcome, verified := processWebhookEvent(ev, false)
+	switch {
+	case status == store.WebhookStatusRejected:
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+	case status == store.WebhookStatusFailed && !verified:
+		http.Error(w, "invalid notification: "+outcome, http.StatusBadRequest)
+	case status == store.WebhookStatusFailed:
+		// Gagal diproses (mis. DB error, tiket belum lengkap): 500 agar gateway mengirim ulang.
+		log.Printf("[webhook] %s failed: %s", ev.ID, outcome)
+		http.Error(w, outcome, http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusOK)
 	}
-	highlighted := amount >= threshold
-	d := store.Donation{
-		OrderID:     notif.OrderID,
-		Amount:      amount,
-		Comment:     notif.CustomField3,
-		Name:        notif.CustomField1,
-		Email:       notif.CustomField2,
-		Highlighted: highlighted,
+}
+
+// applyDonationEvent updates or records the donation for a verified gateway event and describes what it did.
+func applyDonationEvent(ev payment.Event) string {
+	if DonateStore == nil || ev.OrderID == "" {
+		return "ignored: no store or order_id"
 	}
-	if DonateStore != nil {
-		DonateStore.Add(d)
+	// Transaksi dari create-transaction sudah tersimpan sebagai pending: cukup perbarui statusnya (idempotent).
+	if existing, exists := DonateStore.FindByOrderID(ev.OrderID); exists {
+		if ev.Status == "" || existing.Status == ev.Status {
+			return "unchanged: " + existing.Status
+		}
+		if !DonateStore.UpdateStatus(ev.OrderID, ev.Status) {
+			return "error: update status failed"
+		}
+		if updated, ok := DonateStore.FindByOrderID(ev.OrderID); ok {
+			NotifyDonationPaid(updated)
+		}
+		return "status " + existing.Status + " -> " + ev.Status
 	}
-	w.WriteHeader(http.StatusOK)
+	// Hanya simpan saat pembayaran berhasil (settlement) atau pending (GoPay kadang pending dulu)
+	if !payment.IsPaid(ev.Status) && ev.Status != payment.StatusPending {
+		return "ignored: unknown order with status " + ev.Status
+	}
+	if ev.Amount <= 0 {
+		return "ignored: amount <= 0"
+	}
+	d := DonateStore.Add(store.Donation{
+		OrderID:     ev.OrderID,
+		Amount:      ev.Amount,
+		Comment:     ev.Metadata["comment"],
+		Name:        ev.Metadata["name"],
+		Email:       ev.Metadata["email"],
+		Highlighted: ev.Amount >= donateHighlightThreshold(),
+		Status:      ev.Status,
+	})
+	if d.ID == "" {
+		return "error: insert donation failed"
+	}
+	NotifyDonationPaid(d)
+	return "recorded donation " + d.ID + " (" + ev.Status + ")"
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/mail"
+	"backend/internal/pdf"
+	"backend/internal/store"
+)
+
+// MailOutbox and MailDispatcher are set from main (nil = email nonaktif).
+var MailOutbox *store.MailOutboxStore
+var MailDispatcher *jobs.MailDispatcher
+
+// NotifyDonationPaid marks subscription periods paid, refreshes the leaderboard and queues the donor's
+// thank-you receipt and, for highlighted donations, an admin alert.
+// Aman dipanggil berulang untuk donasi yang sama: outbox menolak key yang sudah ada.
+func NotifyDonationPaid(d store.Donation) {
+	if !d.IsPaid() {
+		return
+	}
+	markSubscriptionPeriodPaid(d)
+	invalidateLeaderboard()
+	if MailOutbox == nil {
+		return
+	}
+	data := mail.DonationData{
+		ReceiptNo: d.ID,
+		Name:      d.Name,
+		Email:     d.Email,
+		Amount:    d.Amount,
+		Method:    "Transfer bank",
+		Comment:   d.Comment,
+		PaidAt:    time.Now(),
+	}
+	if d.OrderID != "" {
+		data.Method = "Pembayaran online"
+		if PaymentGateway != nil {
+			data.Method += " (" + PaymentGateway.Name() + ")"
+		}
+	}
+	if d.CampaignID != "" && CampaignStore != nil {
+		if c, ok := CampaignStore.Get(d.CampaignID); ok {
+			data.Campaign = c.Title
+		}
+	}
+	queued := false
+	if d.Email != "" {
+		queued = enqueueDonationReceipt(d, data) || queued
+	}
+	if d.Highlighted && DonateCfg != nil && DonateCfg.AdminNotifyEmail != "" {
+		msg, err := mail.HighlightAlert(DonateCfg.AdminNotifyEmail, data)
+		if err != nil {
+			log.Printf("[mail] highlight alert %s: %v", d.ID, err)
+		} else {
+			queued = enqueueMail("donation-highlight:"+d.ID, msg) || queued
+		}
+	}
+	if queued && MailDispatcher != nil {
+		go MailDispatcher.Run(context.Background())
+	}
+}
+
+func enqueueDonationReceipt(d store.Donation, data mail.DonationData) bool {
+	msg, err := mail.DonationReceipt(data)
+	if err != nil {
+		log.Printf("[mail] receipt %s: %v", d.ID, err)
+		return false
+	}
+	if DonateCfg != nil && DonateCfg.DonateReceiptPDF {
+		b, err := pdf.GenerateDonationReceiptPDF(&pdf.DonationReceiptData{
+			Nomor:    data.ReceiptNo,
+			Tanggal:  data.PaidAt.Format("02 Jan 2006"),
+			Nama:     data.Name,
+			Email:    data.Email,
+			Jumlah:   mail.FormatIDR(data.Amount),
+			Metode:   data.Method,
+			Campaign: data.Campaign,
+		})
+		if err != nil {
+			log.Printf("[mail] receipt pdf %s: %v", d.ID, err)
+		} else {
+			msg.Attachments = append(msg.Attachments, mail.Attachment{
+				Filename:    "kuitansi-donasi-" + d.ID + ".pdf",
+				ContentType: "application/pdf",
+				Data:        b,
+			})
+		}
+	}
+	return enqueueMail("donation-receipt:"+d.ID, msg)
+}
+
+// enqueueMail stores msg in the outbox under key (satu penerima per pesan).
+func enqueueMail(key string, msg mail.Message) bool {
+	if MailOutbox == nil || len(msg.To) == 0 {
+		return false
+	}
+	m := store.OutboxMessage{Key: key, To: msg.To[0], Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML}
+	for _, a := range msg.Attachments {
+		m.Attachments = append(m.Attachments, store.OutboxAttachment{Filename: a.Filename, ContentType: a.ContentType, Data: a.Data})
+	}
+	_, ok := MailOutbox.Enqueue(m)
+	return ok
+}
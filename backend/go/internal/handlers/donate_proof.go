@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/store"
+)
+
+const maxProofBytes = 5 << 20 // 5 MB
+
+// proofExtensions maps allowed (sniffed) content types of a transfer proof to file extensions.
+var proofExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+func privateUploadDir() string {
+	if DonateCfg != nil && DonateCfg.PrivateUploadDir != "" {
+		return DonateCfg.PrivateUploadDir
+	}
+	return "private_uploads"
+}
+
+// DonateUploadProof handles POST /api/donate/{id}/proof (multipart: proof, token). Donatur unggah bukti transfer bank.
+func DonateUploadProof(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if DonateStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "service unavailable"})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxProofBytes+(1<<20))
+	if err := r.ParseMultipartForm(maxProofBytes); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "invalid form or file too large (max 5MB)"})
+		return
+	}
+	id := chi.URLParam(r, "id")
+	token := strings.TrimSpace(r.FormValue("token"))
+	if id == "" || !verifySignedToken("donation-proof", id, token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "token tidak valid"})
+		return
+	}
+	d, ok := DonateStore.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	switch d.Status {
+	case store.DonationStatusAwaitingTransfer, store.DonationStatusAwaitingConfirmation, store.DonationStatusRejected:
+	default:
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "donasi ini tidak menunggu bukti transfer"})
+		return
+	}
+	file, _, err := r.FormFile("proof")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "file proof wajib diisi"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxProofBytes+1))
+	if err != nil || len(data) == 0 || len(data) > maxProofBytes {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "file kosong atau terlalu besar (max 5MB)"})
+		return
+	}
+	ext, ok := proofExtensions[http.DetectContentType(data)]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "bukti transfer harus gambar JPG, PNG, atau WEBP"})
+		return
+	}
+	dir := filepath.Join(privateUploadDir(), "proofs")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rel := filepath.Join("proofs", id+"-"+uniqueFilename()+ext)
+	if err := os.WriteFile(filepath.Join(privateUploadDir(), rel), data, 0600); err != nil {
+		log.Printf("[donate] save proof %s: %v", id, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, ok := DonateStore.SetProof(id, rel); !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":      true,
+		"status":  store.DonationStatusAwaitingConfirmation,
+		"message": "Bukti transfer diterima. Donasi akan tampil setelah dikonfirmasi admin.",
+	})
+}
+
+// DonationReviewRequest for POST /api/admin/donations/{id}/review.
+type DonationReviewRequest struct {
+	Action string `json:"action"` // confirm | reject
+	Note   string `json:"note"`
+}
+
+// DonationReview handles POST /api/admin/donations/{id}/review (admin konfirmasi atau tolak transfer bank).
+func DonationReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req DonationReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	status := ""
+	switch strings.ToLower(strings.TrimSpace(req.Action)) {
+	case "confirm":
+		status = store.DonationStatusConfirmed
+	case "reject":
+		status = store.DonationStatusRejected
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "action must be confirm or reject"})
+		return
+	}
+	if DonateStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	d, ok := DonateStore.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	if d.OrderID != "" {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "donasi payment gateway dikonfirmasi otomatis lewat webhook"})
+		return
+	}
+	if d.Status != store.DonationStatusAwaitingConfirmation {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "donasi berstatus " + d.Status + ", hanya donasi dengan bukti transfer yang menunggu konfirmasi yang bisa direview"})
+		return
+	}
+	d, ok = DonateStore.Review(id, status, strings.TrimSpace(req.Note))
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "donasi sudah direview"})
+		return
+	}
+	if d.Status == store.DonationStatusRejected {
+		invalidateLeaderboard()
+	}
+	NotifyDonationPaid(d)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "donation": d})
+}
+
+// DonationProof handles GET /api/admin/donations/{id}/proof (lihat bukti transfer; file privat).
+func DonationProof(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if DonateStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	d, ok := DonateStore.Get(chi.URLParam(r, "id"))
+	if !ok || d.ProofPath == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeFile(w, r, filepath.Join(privateUploadDir(), filepath.Clean(d.ProofPath)))
+}
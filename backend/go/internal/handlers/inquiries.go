@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/mail"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/notify"
+	"backend/internal/store"
+)
+
+var InquiryStore *store.InquiryStore
+
+// AdminNotifier is set from main (channel NOTIFIER) untuk notifikasi singkat ke admin.
+var AdminNotifier notify.Notifier
+
+// notifyAdmin sends msg in the background; kegagalan hanya dicatat di log.
+func notifyAdmin(msg notify.Message) {
+	if AdminNotifier == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := AdminNotifier.Notify(ctx, msg); err != nil {
+			log.Printf("[notify] %s failed: %v", msg.Key, err)
+		}
+	}()
+}
+
+// InquiryLimiter limits inquiries per IP (INQUIRY_PER_HOUR), disimpan di store rate limit bersama; set from main.
+var InquiryLimiter *ratelimit.Limiter
+
+// InquiryRequest is the body for POST /api/inquiries.
+// Website adalah honeypot: field tersembunyi di form, hanya bot yang mengisinya.
+type InquiryRequest struct {
+	Nama       string   `json:"nama"`
+	Email      string   `json:"email"`
+	WhatsApp   string   `json:"whatsapp"`
+	ServiceIDs []string `json:"service_ids"`
+	Deskripsi  string   `json:"deskripsi"`
+	BudgetMin  int64    `json:"budget_min"`
+	BudgetMax  int64    `json:"budget_max"`
+	Deadline   string   `json:"deadline"` // YYYY-MM-DD, opsional
+	Website    string   `json:"website"`
+}
+
+const inquiryThanks = "Terima kasih! Permintaan Anda sudah kami terima, kami akan menghubungi Anda secepatnya."
+
+// InquirySubmit handles POST /api/inquiries (public: form permintaan order).
+func InquirySubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req InquiryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	ip := ratelimit.ClientIP(r)
+	if strings.TrimSpace(req.Website) != "" {
+		// Balas seolah berhasil agar bot tidak belajar; tidak disimpan.
+		log.Printf("[inquiry] honeypot filled, dropped (ip %s)", ip)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": inquiryThanks})
+		return
+	}
+	if ok, _ := InquiryLimiter.Allow(r.Context(), ip); !ok {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Terlalu banyak permintaan. Coba lagi dalam satu jam atau hubungi kami lewat WhatsApp."})
+		return
+	}
+	q, msg := inquiryFromRequest(req)
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	if InquiryStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q.IP = ip
+	q = InquiryStore.Add(q)
+	if q.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan permintaan"})
+		return
+	}
+	notifyAdmin(notify.Message{
+		Key:     "inquiry:" + q.ID,
+		Subject: "Permintaan order baru: " + strings.Join(q.Layanan, ", "),
+		Text:    formatInquiry(q),
+	})
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": inquiryThanks, "id": q.ID})
+}
+
+// inquiryFromRequest validates req against the current services and returns the inquiry, or an error message.
+func inquiryFromRequest(req InquiryRequest) (store.Inquiry, string) {
+	q := store.Inquiry{
+		Nama:      strings.TrimSpace(req.Nama),
+		Email:     strings.TrimSpace(req.Email),
+		WhatsApp:  strings.TrimSpace(req.WhatsApp),
+		Deskripsi: strings.TrimSpace(req.Deskripsi),
+		BudgetMin: req.BudgetMin,
+		BudgetMax: req.BudgetMax,
+	}
+	switch {
+	case q.Nama == "":
+		return q, "nama wajib diisi"
+	case q.Email == "" && q.WhatsApp == "":
+		return q, "isi email atau nomor WhatsApp agar kami bisa menghubungi Anda"
+	case q.Email != "" && !strings.Contains(q.Email, "@"):
+		return q, "format email tidak valid"
+	case q.Deskripsi == "":
+		return q, "ceritakan kebutuhan pekerjaan Anda"
+	case len(q.Deskripsi) > 5000 || len(q.Nama) > 200 || len(q.Email) > 200 || len(q.WhatsApp) > 50:
+		return q, "isian terlalu panjang"
+	case q.BudgetMin < 0 || q.BudgetMax < 0:
+		return q, "budget tidak boleh negatif"
+	case q.BudgetMax > 0 && q.BudgetMin > q.BudgetMax:
+		return q, "budget minimal lebih besar dari maksimal"
+	case len(req.ServiceIDs) == 0:
+		return q, "pilih minimal satu layanan"
+	case len(req.ServiceIDs) > 10:
+		return q, "maksimal 10 layanan per permintaan"
+	}
+	if strings.TrimSpace(req.Deadline) != "" {
+		d, ok := store.ParseDate(req.Deadline)
+		if !ok {
+			return q, "deadline: format tanggal tidak valid (gunakan YYYY-MM-DD)"
+		}
+		if d.Before(store.NewDate(time.Now().In(wib)).Time) {
+			return q, "deadline sudah lewat"
+		}
+		q.Deadline = &d
+	}
+	services := map[string]store.Service{}
+	if ServiceStore != nil {
+		for _, svc := range ServiceStore.ListAll() {
+			services[svc.ID] = svc
+		}
+	}
+	full := map[string]bool{}
+	_, queues := currentQueue()
+	for _, sq := range queues {
+		if sq.Penuh {
+			full[sq.ServiceID] = true
+		}
+	}
+	seen := map[string]bool{}
+	for _, id := range req.ServiceIDs {
+		id = strings.TrimSpace(id)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		svc, ok := services[id]
+		if !ok {
+			return q, "layanan tidak ditemukan"
+		}
+		if svc.Closed || full[id] {
+			return q, "layanan " + svc.Title + " sedang tutup, belum bisa menerima order baru"
+		}
+		q.ServiceIDs = append(q.ServiceIDs, svc.ID)
+		q.Layanan = append(q.Layanan, svc.Title)
+	}
+	return q, ""
+}
+
+// formatBudget returns the budget range as text, e.g. "Rp 1.000.000 - Rp 2.000.000" ("" bila tidak diisi).
+func formatBudget(min, max int64) string {
+	switch {
+	case min > 0 && max > 0 && min != max:
+		return mail.FormatIDR(int(min)) + " - " + mail.FormatIDR(int(max))
+	case max > 0:
+		return "maks. " + mail.FormatIDR(int(max))
+	case min > 0:
+		return "min. " + mail.FormatIDR(int(min))
+	}
+	return ""
+}
+
+// formatInquiry returns the admin notification text of q.
+func formatInquiry(q store.Inquiry) string {
+	var b strings.Builder
+	b.WriteString("Layanan: " + strings.Join(q.Layanan, ", ") + "\n")
+	b.WriteString("Nama: " + q.Nama + "\n")
+	if q.Email != "" {
+		b.WriteString("Email: " + q.Email + "\n")
+	}
+	if q.WhatsApp != "" {
+		b.WriteString("WhatsApp: " + q.WhatsApp + "\n")
+	}
+	if budget := formatBudget(q.BudgetMin, q.BudgetMax); budget != "" {
+		b.WriteString("Budget: " + budget + "\n")
+	}
+	if q.Deadline != nil {
+		b.WriteString("Deadline: " + q.Deadline.String() + "\n")
+	}
+	b.WriteString("\n" + q.Deskripsi)
+	return b.String()
+}
+
+// InquiriesList handles GET /api/admin/inquiries?status=new.
+func InquiriesList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list := []store.Inquiry{}
+	if InquiryStore != nil {
+		list = append(list, InquiryStore.List(r.URL.Query().Get("status"))...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "inquiries": list})
+}
+
+// InquiryStatusRequest is the body for PATCH /api/admin/inquiries/{id}.
+type InquiryStatusRequest struct {
+	Status    string `json:"status"` // new | contacted | rejected
+	AdminNote string `json:"admin_note"`
+}
+
+// InquirySetStatus handles PATCH /api/admin/inquiries/{id} (tandai sudah dihubungi / tolak + catatan admin).
+func InquirySetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req InquiryStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !store.ValidInquiryStatus(req.Status) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "status must be new, contacted or rejected"})
+		return
+	}
+	if InquiryStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q, ok := InquiryStore.SetStatus(chi.URLParam(r, "id"), req.Status, strings.TrimSpace(req.AdminNote))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found or already converted"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "inquiry": q})
+}
+
+// InquiryConvertRequest is the optional body for POST /api/admin/inquiries/{id}/convert.
+type InquiryConvertRequest struct {
+	Layanan          string `json:"layanan"`           // default: semua layanan yang dipilih, dipisah koma
+	NilaiKesepakatan int64  `json:"nilai_kesepakatan"` // default 0 (diisi saat penawaran)
+	RevisiPutaran    *int   `json:"revisi_putaran"`    // default menurut tier layanan
+}
+
+// InquiryConvert handles POST /api/admin/inquiries/{id}/convert: buat order berstatus inquiry dari permintaan ini.
+// Data pemesan, deskripsi (plus kontak WhatsApp dan budget) dan deadline disalin; tiket revisi dibuat seperti OrdersAdd.
+func InquiryConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req InquiryConvertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if InquiryStore == nil || OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q, ok := InquiryStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	if q.Status == store.InquiryStatusConverted {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "sudah dijadikan order", "order_id": q.OrderID})
+		return
+	}
+	if req.NilaiKesepakatan < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "nilai_kesepakatan tidak boleh negatif"})
+		return
+	}
+	if req.RevisiPutaran != nil && (*req.RevisiPutaran < 0 || *req.RevisiPutaran > maxRevisiPutaran) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "revisi_putaran harus 0-" + strconv.Itoa(maxRevisiPutaran)})
+		return
+	}
+	layanan := strings.TrimSpace(req.Layanan)
+	if layanan == "" {
+		layanan = strings.Join(q.Layanan, ", ")
+	}
+	desc := q.Deskripsi
+	if q.WhatsApp != "" {
+		desc += "\n\nWhatsApp: " + q.WhatsApp
+	}
+	if budget := formatBudget(q.BudgetMin, q.BudgetMax); budget != "" {
+		desc += "\nBudget klien: " + budget
+	}
+	item := OrderStore.Add(store.OrderItem{
+		Layanan:            layanan,
+		Pemesan:            q.Nama,
+		EmailPemesan:       q.Email,
+		DeskripsiPekerjaan: strings.TrimSpace(desc),
+		Deadline:           q.Deadline,
+		NilaiKesepakatan:   req.NilaiKesepakatan,
+		Status:             store.OrderStatusInquiry,
+		RevisiPutaran:      revisionAllowance(layanan, req.RevisiPutaran),
+	})
+	if item.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal membuat order"})
+		return
+	}
+	q, ok = InquiryStore.MarkConverted(q.ID, item.ID)
+	if !ok {
+		// Dikonversi bersamaan dari tab lain: batalkan order duplikat.
+		OrderStore.Delete(item.ID)
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "sudah dijadikan order"})
+		return
+	}
+	tickets := issueRevisionTickets(item.ID, item.RevisiPutaran)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "inquiry": q, "order": item, "tickets": tickets})
+}
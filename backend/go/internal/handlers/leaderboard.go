@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/store"
+)
+
+// wib is Indonesia Western Time, dipakai untuk batas periode (awal bulan/tahun).
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// Supporter is one aggregated donor on the public leaderboard.
+type Supporter struct {
+	Rank          int       `json:"rank"`
+	Name          string    `json:"name"` // sudah disamarkan/“Anonim” sesuai pilihan donatur
+	Total         int       `json:"total"`
+	DonationCount int       `json:"donation_count"`
+	Tier          string    `json:"tier,omitempty"`
+	Highlighted   bool      `json:"highlighted"` // pernah berdonasi >= DONATE_HIGHLIGHT_IDR
+	Message       string    `json:"message,omitempty"`
+	LastDonatedAt time.Time `json:"last_donated_at"`
+}
+
+// LeaderboardResponse is the JSON for GET /api/donations/leaderboard.
+type LeaderboardResponse struct {
+	OK          bool                  `json:"ok"`
+	Period      string                `json:"period"`
+	Since       *time.Time            `json:"since,omitempty"`
+	Tiers       []config.DonationTier `json:"tiers"`
+	Supporters  []Supporter           `json:"supporters"`
+	Total       int                   `json:"total"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+type leaderboardEntry struct {
+	at   time.Time
+	resp LeaderboardResponse
+}
+
+var (
+	leaderboardMu    sync.Mutex
+	leaderboardCache = map[string]leaderboardEntry{}
+)
+
+// invalidateLeaderboard drops cached leaderboards (dipanggil saat donasi lunas atau data donatur dihapus).
+func invalidateLeaderboard() {
+	leaderboardMu.Lock()
+	leaderboardCache = map[string]leaderboardEntry{}
+	leaderboardMu.Unlock()
+}
+
+// periodStart returns the start of period in WIB; zero time for "all".
+func periodStart(period string, now time.Time) (time.Time, bool) {
+	now = now.In(wib)
+	switch period {
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, wib), true
+	case "year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, wib), true
+	case "all":
+		return time.Time{}, true
+	}
+	return time.Time{}, false
+}
+
+// supporterKey groups donations by donor identity: email, lalu nama. Donasi anonim tidak digabung
+// dengan identitas apa pun agar total publik donatur tidak membocorkan donasi anonimnya.
+func supporterKey(d store.Donation) string {
+	if d.Anonymous {
+		return "anon:" + d.ID
+	}
+	if e := strings.ToLower(strings.TrimSpace(d.Email)); e != "" {
+		return "email:" + e
+	}
+	if n := strings.ToLower(strings.Join(strings.Fields(d.Name), " ")); n != "" {
+		return "name:" + n
+	}
+	return "anon:" + d.ID
+}
+
+func tierFor(total int, tiers []config.DonationTier) string {
+	for _, t := range tiers {
+		if total >= t.Min {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// buildLeaderboard aggregates paid donations since start. Donations are oldest first, so the latest
+// donation's name and consent win (pilihan terakhir donatur yang berlaku).
+func buildLeaderboard(donations []store.Donation, tiers []config.DonationTier, highlight int) []Supporter {
+	byKey := make(map[string]*Supporter)
+	var order []string
+	for _, d := range donations {
+		key := supporterKey(d)
+		s, ok := byKey[key]
+		if !ok {
+			s = &Supporter{}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		s.Total += d.Amount
+		s.DonationCount++
+		s.Name = d.PublicName()
+		if c := d.PublicComment(); c != "" {
+			s.Message = c
+		} else if !d.ConsentPublish {
+			s.Message = ""
+		}
+		if d.Highlighted || d.Amount >= highlight {
+			s.Highlighted = true
+		}
+		s.LastDonatedAt = d.CreatedAt
+	}
+	out := make([]Supporter, 0, len(order))
+	for _, key := range order {
+		s := *byKey[key]
+		s.Tier = tierFor(s.Total, tiers)
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].LastDonatedAt.Before(out[j].LastDonatedAt)
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return out
+}
+
+// DonationsLeaderboard handles GET /api/donations/leaderboard?period=month|year|all&limit=50 (public supporter wall).
+// Hasil di-cache LEADERBOARD_CACHE_SECONDS per periode.
+func DonationsLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "month"
+	}
+	now := time.Now()
+	since, ok := periodStart(period, now)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "period must be month, year or all"})
+		return
+	}
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 500 {
+		limit = n
+	}
+
+	ttl := 300 * time.Second
+	var tiers []config.DonationTier
+	highlight := donateHighlightThreshold()
+	if DonateCfg != nil {
+		ttl = time.Duration(DonateCfg.LeaderboardCacheSec) * time.Second
+		tiers = DonateCfg.DonateTiers
+	}
+	leaderboardMu.Lock()
+	entry, cached := leaderboardCache[period]
+	leaderboardMu.Unlock()
+	if !cached || now.Sub(entry.at) >= ttl {
+		resp := LeaderboardResponse{OK: true, Period: period, Tiers: tiers, Supporters: []Supporter{}, GeneratedAt: now.UTC()}
+		if tiers == nil {
+			resp.Tiers = []config.DonationTier{}
+		}
+		if !since.IsZero() {
+			resp.Since = &since
+		}
+		if DonateStore != nil {
+			resp.Supporters = buildLeaderboard(DonateStore.ListPaidSince(since), tiers, highlight)
+		}
+		resp.Total = len(resp.Supporters)
+		entry = leaderboardEntry{at: now, resp: resp}
+		if ttl > 0 {
+			leaderboardMu.Lock()
+			leaderboardCache[period] = entry
+			leaderboardMu.Unlock()
+		}
+	}
+	resp := entry.resp
+	if len(resp.Supporters) > limit {
+		resp.Supporters = resp.Supporters[:limit]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(ttl.Seconds())))
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
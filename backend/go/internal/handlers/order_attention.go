@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/jobs"
+)
+
+// DeadlineWatcher is set from main.
+var DeadlineWatcher *jobs.DeadlineWatcher
+
+// OrdersAttention handles GET /api/admin/orders/attention?days=3: order belum selesai yang lewat atau mendekati deadline.
+func OrdersAttention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	days := 3
+	if DeadlineWatcher != nil {
+		days = DeadlineWatcher.WarnDays
+	}
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 365 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "days must be 0-365"})
+			return
+		}
+		days = n
+	}
+	rep := jobs.BuildAttention(nil, time.Now(), days)
+	if OrderStore != nil {
+		rep = jobs.BuildAttention(OrderStore.List(), time.Now(), days)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "attention": rep})
+}
+
+// OrdersAttentionNotify handles POST /api/admin/orders/attention/notify (kirim digest deadline sekarang).
+func OrdersAttentionNotify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if DeadlineWatcher == nil || DeadlineWatcher.Notifier == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "notifier belum dikonfigurasi"})
+		return
+	}
+	rep, sent, err := DeadlineWatcher.SendNow(r.Context())
+	if err != nil {
+		log.Printf("[deadline] manual digest via %s: %v", DeadlineWatcher.Notifier.Name(), err)
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal mengirim notifikasi"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "sent": sent, "notifier": DeadlineWatcher.Notifier.Name(), "attention": rep})
+}
@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/mail"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/notify"
+	"backend/internal/store"
+)
+
+var OrderMessageStore *store.OrderMessageStore
+
+const (
+	maxAttachmentBytes    = 10 << 20 // 10 MB per file
+	maxMessageBytes       = 25 << 20 // 25 MB total per pesan
+	maxAttachments        = 5
+	maxMessageBodyRunes   = 5000
+	ClientMessagesPerHour = 20 // pesan klien per kode lacak per jam
+)
+
+// attachmentTypes maps allowed (sniffed) content types to the default extension.
+// application/zip juga mencakup dokumen Office (docx/xlsx/pptx); ekstensi aslinya dipertahankan bila termasuk zipExtensions.
+var attachmentTypes = map[string]string{
+	"image/jpeg":                ".jpg",
+	"image/png":                 ".png",
+	"image/webp":                ".webp",
+	"image/gif":                 ".gif",
+	"application/pdf":           ".pdf",
+	"application/zip":           ".zip",
+	"text/plain; charset=utf-8": ".txt",
+}
+
+var zipExtensions = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+var textExtensions = map[string]bool{".txt": true, ".md": true, ".csv": true}
+
+// ClientMessageLimiter limits client messages per order (ClientMessagesPerHour per jam); set from main.
+var ClientMessageLimiter *ratelimit.Limiter
+
+// cleanAttachmentName keeps the base name of an uploaded file, without path or control characters.
+func cleanAttachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || strings.TrimSpace(name) == "" {
+		return "lampiran"
+	}
+	if utf8.RuneCountInString(name) > 120 {
+		name = string([]rune(name)[:120])
+	}
+	return name
+}
+
+// postOrderMessage reads a multipart message (body, files) for order and saves it with its attachments.
+// Errors are written to w; ok false berarti respons sudah dikirim.
+func postOrderMessage(w http.ResponseWriter, r *http.Request, order store.OrderItem, sender, senderName string) (store.OrderMessage, bool) {
+	fail := func(status int, msg string) (store.OrderMessage, bool) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return store.OrderMessage{}, false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMessageBytes+(1<<20))
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		return fail(http.StatusBadRequest, "invalid form or files too large (max 25MB per pesan)")
+	}
+	defer r.MultipartForm.RemoveAll()
+	body := strings.TrimSpace(r.FormValue("body"))
+	if utf8.RuneCountInString(body) > maxMessageBodyRunes {
+		return fail(http.StatusBadRequest, "pesan terlalu panjang (maks. 5000 karakter)")
+	}
+	files := r.MultipartForm.File["files"]
+	if body == "" && len(files) == 0 {
+		return fail(http.StatusBadRequest, "pesan atau lampiran wajib diisi")
+	}
+	atts, status, errMsg := saveAttachments(files, filepath.Join("order_messages", order.ID))
+	if errMsg != "" {
+		return fail(status, errMsg)
+	}
+	msg := store.OrderMessage{OrderID: order.ID, Sender: sender, SenderName: senderName, Body: body, Attachments: atts}
+	msg = OrderMessageStore.Add(msg)
+	if msg.ID == "" {
+		removeAttachments(atts)
+		return fail(http.StatusInternalServerError, "gagal menyimpan pesan")
+	}
+	return msg, true
+}
+
+// saveAttachments validates uploaded files (jumlah, ukuran, tipe hasil sniffing) and writes them under dir
+// in the private upload dir. On failure nothing is kept and the HTTP status plus message are returned.
+func saveAttachments(files []*multipart.FileHeader, dir string) ([]store.MessageAttachment, int, string) {
+	if len(files) > maxAttachments {
+		return nil, http.StatusBadRequest, "maksimal 5 lampiran"
+	}
+	type upload struct {
+		att  store.MessageAttachment
+		ext  string
+		data []byte
+	}
+	var uploads []upload
+	for _, fh := range files {
+		name := cleanAttachmentName(fh.Filename)
+		f, err := fh.Open()
+		if err != nil {
+			return nil, http.StatusBadRequest, name + ": file tidak bisa dibaca"
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxAttachmentBytes+1))
+		f.Close()
+		if err != nil || len(data) == 0 || len(data) > maxAttachmentBytes {
+			return nil, http.StatusBadRequest, name + ": file kosong atau terlalu besar (max 10MB)"
+		}
+		sniffed := http.DetectContentType(data)
+		ext, ok := attachmentTypes[sniffed]
+		if !ok {
+			return nil, http.StatusBadRequest, name + ": tipe file tidak didukung (gambar, PDF, ZIP, dokumen Office, atau teks)"
+		}
+		contentType := strings.TrimSuffix(sniffed, "; charset=utf-8")
+		orig := strings.ToLower(filepath.Ext(name))
+		if ct, isOffice := zipExtensions[orig]; isOffice && ext == ".zip" {
+			ext, contentType = orig, ct
+		} else if textExtensions[orig] && ext == ".txt" {
+			ext = orig
+		}
+		uploads = append(uploads, upload{
+			att:  store.MessageAttachment{ID: uniqueFilename(), Name: name, ContentType: contentType, Size: int64(len(data))},
+			ext:  ext,
+			data: data,
+		})
+	}
+	atts := []store.MessageAttachment{}
+	if len(uploads) == 0 {
+		return atts, 0, ""
+	}
+	if err := os.MkdirAll(filepath.Join(privateUploadDir(), dir), 0700); err != nil {
+		return nil, http.StatusInternalServerError, "gagal menyimpan lampiran"
+	}
+	for _, u := range uploads {
+		u.att.Path = filepath.Join(dir, u.att.ID+u.ext)
+		if err := os.WriteFile(filepath.Join(privateUploadDir(), u.att.Path), u.data, 0600); err != nil {
+			log.Printf("[attachments] save %s: %v", u.att.Path, err)
+			removeAttachments(atts)
+			return nil, http.StatusInternalServerError, "gagal menyimpan lampiran"
+		}
+		atts = append(atts, u.att)
+	}
+	return atts, 0, ""
+}
+
+// removeAttachments deletes the files of atts from the private upload dir.
+func removeAttachments(atts []store.MessageAttachment) int {
+	n := 0
+	for _, a := range atts {
+		if a.Path != "" && os.Remove(filepath.Join(privateUploadDir(), filepath.Clean(a.Path))) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// deleteOrderMessages removes the thread of an order and its attachment files; returns (pesan, file) dihapus.
+func deleteOrderMessages(orderID string) (int, int) {
+	if OrderMessageStore == nil {
+		return 0, 0
+	}
+	deleted := OrderMessageStore.DeleteByOrder(orderID)
+	files := 0
+	for _, m := range deleted {
+		files += removeAttachments(m.Attachments)
+	}
+	os.Remove(filepath.Join(privateUploadDir(), "order_messages", orderID))
+	return len(deleted), files
+}
+
+// serveAttachment streams a private attachment as a download (never rendered inline).
+func serveAttachment(w http.ResponseWriter, r *http.Request, a store.MessageAttachment) {
+	f, err := os.Open(filepath.Join(privateUploadDir(), filepath.Clean(a.Path)))
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
+
+// serveMessageAttachment serves attachment id of a message in order orderID.
+func serveMessageAttachment(w http.ResponseWriter, r *http.Request, orderID, id string) {
+	a, ok := OrderMessageStore.Attachment(orderID, id)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	serveAttachment(w, r, a)
+}
+
+// clientThread returns the thread as the client sees it: email admin diganti nama penyedia jasa.
+func clientThread(orderID string) []store.OrderMessage {
+	name := "Admin"
+	if OrderCfg != nil && OrderCfg.ProviderName != "" {
+		name = OrderCfg.ProviderName
+	}
+	out := []store.OrderMessage{}
+	for _, m := range OrderMessageStore.List(orderID) {
+		if m.Sender == store.MessageSenderAdmin {
+			m.SenderName = name
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// messagePreview shortens body for notifications.
+func messagePreview(body string, n int) string {
+	if utf8.RuneCountInString(body) <= n {
+		return body
+	}
+	return string([]rune(body)[:n]) + "..."
+}
+
+// trackedOrder returns the order of the tracking code in the URL, writing 404 when unknown.
+func trackedOrder(w http.ResponseWriter, r *http.Request) (store.OrderItem, bool) {
+	if OrderStore == nil || OrderMessageStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return store.OrderItem{}, false
+	}
+	o, ok := OrderStore.GetByTrackingCode(chi.URLParam(r, "code"))
+	if !ok {
+		ratelimit.Fail(r)
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Kode lacak tidak ditemukan. Cek kembali kode lacak yang kami kirimkan."})
+		return store.OrderItem{}, false
+	}
+	return o, true
+}
+
+// OrderTrackMessages handles GET /api/orders/track/{code}/messages (public, thread order untuk klien).
+func OrderTrackMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	o, ok := trackedOrder(w, r)
+	if !ok {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": clientThread(o.ID)})
+}
+
+// OrderTrackPostMessage handles POST /api/orders/track/{code}/messages (public; multipart: body, files[]).
+// Admin diberi tahu lewat notifier.
+func OrderTrackPostMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	o, ok := trackedOrder(w, r)
+	if !ok {
+		return
+	}
+	if o.Status == store.OrderStatusCancelled {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order ini sudah dibatalkan"})
+		return
+	}
+	if ok, _ := ClientMessageLimiter.Allow(r.Context(), o.ID); !ok {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Terlalu banyak pesan. Coba lagi nanti."})
+		return
+	}
+	msg, ok := postOrderMessage(w, r, o, store.MessageSenderClient, o.Pemesan)
+	if !ok {
+		return
+	}
+	text := o.Pemesan + " (" + o.Layanan + "): " + messagePreview(msg.Body, 500)
+	if n := len(msg.Attachments); n > 0 {
+		text += " [" + strconv.Itoa(n) + " lampiran]"
+	}
+	notifyAdmin(notify.Message{Key: "order-message:" + msg.ID, Subject: "Pesan baru dari " + o.Pemesan, Text: text})
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": msg})
+}
+
+// OrderTrackAttachment handles GET /api/orders/track/{code}/attachments/{id} (public, hanya lampiran order ini).
+func OrderTrackAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	o, ok := trackedOrder(w, r)
+	if !ok {
+		return
+	}
+	serveMessageAttachment(w, r, o.ID, chi.URLParam(r, "id"))
+}
+
+// OrderMessages handles GET /api/admin/orders/{id}/messages.
+func OrderMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if OrderStore == nil || OrderMessageStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	o, ok := OrderStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	list := []store.OrderMessage{}
+	list = append(list, OrderMessageStore.List(o.ID)...)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "messages": list})
+}
+
+// OrderPostMessage handles POST /api/admin/orders/{id}/messages (multipart: body, files[]).
+// Klien diberi tahu lewat email bila order punya email pemesan.
+func OrderPostMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if OrderStore == nil || OrderMessageStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	o, ok := OrderStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	msg, ok := postOrderMessage(w, r, o, store.MessageSenderAdmin, orderActor(r))
+	if !ok {
+		return
+	}
+	emailed := false
+	if o.EmailPemesan != "" && o.TrackingCode != "" {
+		m, err := mail.OrderMessage(mail.OrderMessageData{
+			Name:        o.Pemesan,
+			Email:       o.EmailPemesan,
+			Layanan:     o.Layanan,
+			Preview:     messagePreview(msg.Body, 300),
+			Attachments: len(msg.Attachments),
+			URL:         siteBaseURL() + "/lacak?" + url.Values{"code": {o.TrackingCode}}.Encode(),
+		})
+		if err != nil {
+			log.Printf("[mail] order message %s: %v", msg.ID, err)
+		} else if emailed = enqueueMail("order-message:"+msg.ID, m); emailed && MailDispatcher != nil {
+			go MailDispatcher.Run(context.Background())
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": msg, "emailed": emailed})
+}
+
+// OrderAttachment handles GET /api/admin/orders/{id}/attachments/{aid}.
+func OrderAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if OrderMessageStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	serveMessageAttachment(w, r, chi.URLParam(r, "id"), chi.URLParam(r, "aid"))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/store"
+)
+
+// orderStatusLabels are the client-facing names of order statuses (portal lacak order).
+var orderStatusLabels = map[string]string{
+	store.OrderStatusInquiry:       "Permintaan diterima",
+	store.OrderStatusQuoted:        "Penawaran dikirim",
+	store.OrderStatusAgreementSent: "Menunggu tanda tangan perjanjian",
+	store.OrderStatusDPPaid:        "DP diterima, masuk antrian",
+	store.OrderStatusInProgress:    "Sedang dikerjakan",
+	store.OrderStatusInReview:      "Menunggu tanggapan Anda",
+	store.OrderStatusRevision:      "Sedang direvisi",
+	store.OrderStatusCompleted:     "Selesai",
+	store.OrderStatusCancelled:     "Dibatalkan",
+}
+
+// TrackingEvent is one status change shown to the client (tanpa nama admin dan catatan internal).
+type TrackingEvent struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Label      string    `json:"label"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TrackingMilestone is the next date the client can expect something to happen.
+type TrackingMilestone struct {
+	Kind    string     `json:"kind"` // "mulai" | "deadline"
+	Label   string     `json:"label"`
+	Date    store.Date `json:"date"`
+	Overdue bool       `json:"overdue"`
+}
+
+// nextOrderMilestone returns the next milestone of o relative to today (WIB), or nil for finished orders.
+func nextOrderMilestone(o store.OrderItem, now time.Time) *TrackingMilestone {
+	if o.Status == store.OrderStatusCompleted || o.Status == store.OrderStatusCancelled {
+		return nil
+	}
+	today := store.NewDate(now.In(wib))
+	if o.MulaiTanggal != nil && !o.MulaiTanggal.Before(today.Time) {
+		return &TrackingMilestone{Kind: "mulai", Label: "Mulai dikerjakan", Date: *o.MulaiTanggal}
+	}
+	if o.Deadline != nil {
+		return &TrackingMilestone{Kind: "deadline", Label: "Target selesai", Date: *o.Deadline, Overdue: o.Deadline.Before(today.Time)}
+	}
+	return nil
+}
+
+// OrderTrack handles GET /api/orders/track/{code} (public). Kode lacak hanya membuka order miliknya sendiri;
+// yang dikembalikan sengaja dibatasi: tanpa email, deskripsi, catatan admin, maupun kode tiket revisi.
+func OrderTrack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	o, ok := OrderStore.GetByTrackingCode(chi.URLParam(r, "code"))
+	if !ok {
+		ratelimit.Fail(r)
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Kode lacak tidak ditemukan. Cek kembali kode lacak yang kami kirimkan."})
+		return
+	}
+	timeline := []TrackingEvent{}
+	for _, ev := range OrderStore.Events(o.ID) {
+		timeline = append(timeline, TrackingEvent{FromStatus: ev.FromStatus, ToStatus: ev.ToStatus, Label: orderStatusLabels[ev.ToStatus], CreatedAt: ev.CreatedAt})
+	}
+	sisaRevisi, totalRevisi, kedaluwarsa := 0, 0, 0
+	var berlakuSampai *time.Time
+	if RevisionTicketStore != nil {
+		now := time.Now()
+		for _, t := range RevisionTicketStore.ByOrderID(o.ID) {
+			totalRevisi++
+			switch {
+			case t.Expired(now):
+				kedaluwarsa++
+			case t.Status == "unused":
+				sisaRevisi++
+				if t.ExpiresAt != nil && (berlakuSampai == nil || t.ExpiresAt.Before(*berlakuSampai)) {
+					berlakuSampai = t.ExpiresAt
+				}
+			}
+		}
+	}
+	outstanding := o.NilaiKesepakatan - o.JumlahDibayar
+	if outstanding < 0 || o.Status == store.OrderStatusCancelled {
+		outstanding = 0
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok": true,
+		"order": map[string]interface{}{
+			"layanan":       o.Layanan,
+			"status":        o.Status,
+			"status_label":  orderStatusLabels[o.Status],
+			"mulai_tanggal": o.MulaiTanggal,
+			"deadline":      o.Deadline,
+			"completed_at":  o.CompletedAt,
+			"created_at":    o.CreatedAt,
+		},
+		"timeline":       timeline,
+		"next_milestone": nextOrderMilestone(o, time.Now()),
+		"tagihan": map[string]interface{}{
+			"nilai_kesepakatan": o.NilaiKesepakatan,
+			"sudah_dibayar":     o.JumlahDibayar,
+			"sisa":              outstanding,
+		},
+		"revisi": map[string]interface{}{
+			"sisa":           sisaRevisi,
+			"total":          totalRevisi,
+			"kedaluwarsa":    kedaluwarsa,
+			"berlaku_sampai": berlakuSampai,        // batas klaim tiket tersisa yang paling dekat; null = belum diserahkan
+			"harga_tambahan": revisionExtraPrice(), // 0 = revisi tambahan tidak dijual online
+		},
+	})
+}
@@ -2,50 +2,61 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"backend/internal/config"
+	mw "backend/internal/middleware"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/notify"
 	"backend/internal/store"
+
+	"github.com/go-chi/chi/v5"
 )
 
 var OrderStore *store.OrderStore
 var RevisionTicketStore *store.RevisionTicketStore
+var OrderCfg *config.Config
 
-// OrdersAntrian handles GET /api/orders/antrian (public: hanya nama layanan, tanpa price/detail).
+// OrdersAntrian handles GET /api/orders/antrian (public, tanpa nama pemesan/price/detail).
+// antrian = nama layanan order aktif (format lama); entries = posisi antrian dengan estimasi mulai/selesai;
+// layanan = beban dan estimasi mulai per layanan bila order hari ini (penuh = kapasitas tercapai).
 func OrdersAntrian(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if OrderStore == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "antrian": []string{}})
-		return
+	entries, summaries := currentQueue()
+	antrian := make([]string, 0, len(entries))
+	for _, e := range entries {
+		antrian = append(antrian, e.Layanan)
 	}
-	list := OrderStore.List()
-	antrian := make([]string, 0, len(list))
-	for _, item := range list {
-		if item.Status != "in_progress" {
-			continue
-		}
-		s := strings.TrimSpace(item.Layanan)
-		if s != "" {
-			antrian = append(antrian, s)
-		}
+	if entries == nil {
+		entries = []QueueEntry{}
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "antrian": antrian})
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "antrian": antrian, "entries": entries, "layanan": summaries})
 }
 
-// OrderWithTickets embeds revision tickets for admin.
+// OrderWithTickets embeds revision tickets and the allowed next statuses for admin.
 type OrderWithTickets struct {
 	store.OrderItem
-	Tickets []store.RevisionTicket `json:"tickets"`
+	Tickets      []store.RevisionTicket `json:"tickets"`
+	RevisiAktif  int                    `json:"revisi_aktif"` // revisi requested/in_progress yang perlu dikerjakan
+	NextStatuses []string               `json:"next_statuses"`
 }
 
 // OrdersListAll handles GET /api/admin/orders (full list for admin, with revision tickets).
+// ?sort=deadline mengurutkan dari deadline terdekat (tanpa deadline di akhir); default terbaru dulu.
 func OrdersListAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -58,11 +69,25 @@ func OrdersListAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	list := OrderStore.List()
+	if r.URL.Query().Get("sort") == "deadline" {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i].Deadline, list[j].Deadline
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return a.Before(b.Time)
+		})
+	}
 	out := make([]OrderWithTickets, 0, len(list))
 	for _, o := range list {
-		ow := OrderWithTickets{OrderItem: o}
+		ow := OrderWithTickets{OrderItem: o, NextStatuses: store.NextOrderStatuses(o.Status)}
 		if RevisionTicketStore != nil {
 			ow.Tickets = RevisionTicketStore.ByOrderID(o.ID)
+			for _, t := range ow.Tickets {
+				if t.RevisiStatus == store.RevisionRequested || t.RevisiStatus == store.RevisionInProgress {
+					ow.RevisiAktif++
+				}
+			}
 		}
 		out = append(out, ow)
 	}
@@ -71,15 +96,68 @@ func OrdersListAll(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "orders": out})
 }
 
-// OrderAddRequest for POST /api/admin/orders.
+// OrderAddRequest for POST /api/admin/orders and PUT /api/admin/orders/{id}.
+// Tanggal dalam format YYYY-MM-DD (format lama seperti 12/03/2026 atau "12 Maret 2026" juga diterima).
 type OrderAddRequest struct {
 	Layanan              string `json:"layanan"`
 	Pemesan              string `json:"pemesan"`
+	EmailPemesan         string `json:"email_pemesan"`
 	DeskripsiPekerjaan   string `json:"deskripsi_pekerjaan"`
 	Deadline             string `json:"deadline"`
 	MulaiTanggal         string `json:"mulai_tanggal"`
 	KesepakatanBriefUang string `json:"kesepakatan_brief_uang"`
+	NilaiKesepakatan     int64  `json:"nilai_kesepakatan"` // IDR; 0 = diambil dari kesepakatan_brief_uang bila berupa nominal
+	JumlahDibayar        int64  `json:"jumlah_dibayar"`    // IDR yang sudah dibayar klien (untuk sisa tagihan di portal lacak)
 	KapanUangMasuk       string `json:"kapan_uang_masuk"`
+	RevisiPutaran        *int   `json:"revisi_putaran,omitempty"` // hanya saat membuat order; kosong = menurut tier layanan
+}
+
+// maxRevisiPutaran caps the revision allowance and the number of extra tickets issued at once.
+const maxRevisiPutaran = 10
+
+// orderFromRequest validates req and returns the order fields, or an error message.
+func orderFromRequest(req OrderAddRequest) (store.OrderItem, string) {
+	item := store.OrderItem{
+		Layanan:              strings.TrimSpace(req.Layanan),
+		Pemesan:              strings.TrimSpace(req.Pemesan),
+		EmailPemesan:         strings.TrimSpace(req.EmailPemesan),
+		DeskripsiPekerjaan:   strings.TrimSpace(req.DeskripsiPekerjaan),
+		KesepakatanBriefUang: strings.TrimSpace(req.KesepakatanBriefUang),
+		NilaiKesepakatan:     req.NilaiKesepakatan,
+		JumlahDibayar:        req.JumlahDibayar,
+	}
+	if item.Layanan == "" {
+		return item, "layanan required"
+	}
+	if item.NilaiKesepakatan < 0 {
+		return item, "nilai_kesepakatan tidak boleh negatif"
+	}
+	if item.JumlahDibayar < 0 {
+		return item, "jumlah_dibayar tidak boleh negatif"
+	}
+	if item.NilaiKesepakatan == 0 {
+		item.NilaiKesepakatan, _ = store.ParseRupiah(item.KesepakatanBriefUang)
+	}
+	dates := []struct {
+		field string
+		raw   string
+		dst   **store.Date
+	}{
+		{"deadline", req.Deadline, &item.Deadline},
+		{"mulai_tanggal", req.MulaiTanggal, &item.MulaiTanggal},
+		{"kapan_uang_masuk", req.KapanUangMasuk, &item.KapanUangMasuk},
+	}
+	for _, d := range dates {
+		if strings.TrimSpace(d.raw) == "" {
+			continue
+		}
+		parsed, ok := store.ParseDate(d.raw)
+		if !ok {
+			return item, d.field + " harus berformat YYYY-MM-DD"
+		}
+		*d.dst = &parsed
+	}
+	return item, ""
 }
 
 // OrdersAdd handles POST /api/admin/orders.
@@ -93,86 +171,273 @@ func OrdersAdd(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
 		return
 	}
-	layanan := strings.TrimSpace(req.Layanan)
-	if layanan == "" {
+	item, msg := orderFromRequest(req)
+	if msg == "" && req.RevisiPutaran != nil && (*req.RevisiPutaran < 0 || *req.RevisiPutaran > maxRevisiPutaran) {
+		msg = "revisi_putaran harus 0-" + strconv.Itoa(maxRevisiPutaran)
+	}
+	if msg != "" {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "layanan required"})
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
 		return
 	}
 	if OrderStore == nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	item := OrderStore.Add(
-		layanan,
-		strings.TrimSpace(req.Pemesan),
-		strings.TrimSpace(req.DeskripsiPekerjaan),
-		strings.TrimSpace(req.Deadline),
-		strings.TrimSpace(req.MulaiTanggal),
-		strings.TrimSpace(req.KesepakatanBriefUang),
-		strings.TrimSpace(req.KapanUangMasuk),
-	)
-	tickets := []store.RevisionTicket{}
-	if RevisionTicketStore != nil {
-		tickets = RevisionTicketStore.CreateForOrder(item.ID, 2)
-	}
+	item.RevisiPutaran = revisionAllowance(item.Layanan, req.RevisiPutaran)
+	item = OrderStore.Add(item)
+	tickets := issueRevisionTickets(item.ID, item.RevisiPutaran)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "order": item, "tickets": tickets})
 }
 
+// serviceTier returns the agreement tier of an order's Layanan. Order dari inquiry bisa berisi beberapa layanan
+// dipisah koma: tier tertinggi yang dipakai. Layanan di luar katalog dianggap standar.
+func serviceTier(layanan string) string {
+	tier := store.ServiceTierStandar
+	if ServiceStore == nil {
+		return tier
+	}
+	services := ServiceStore.ListAll()
+	for _, name := range strings.Split(layanan, ",") {
+		name = strings.TrimSpace(name)
+		for _, svc := range services {
+			if strings.EqualFold(strings.TrimSpace(svc.Title), name) && svc.Tier == store.ServiceTierProfesional {
+				tier = store.ServiceTierProfesional
+			}
+		}
+	}
+	return tier
+}
+
+// revisionAllowance returns the number of revisions included in a new order: override bila diisi admin,
+// selain itu menurut tier layanan.
+func revisionAllowance(layanan string, override *int) int {
+	if override != nil {
+		return *override
+	}
+	return tierRevisions(serviceTier(layanan))
+}
+
+// tierRevisions returns the revisions included for an agreement tier (REVISI_STANDAR / REVISI_PROFESIONAL).
+func tierRevisions(tier string) int {
+	standar, profesional := 2, 3
+	if OrderCfg != nil {
+		standar, profesional = OrderCfg.RevisiStandar, OrderCfg.RevisiProfesional
+	}
+	if strings.EqualFold(tier, store.ServiceTierProfesional) {
+		return profesional
+	}
+	return standar
+}
+
+// issueRevisionTickets creates the n revision tickets included in a new order.
+func issueRevisionTickets(orderID string, n int) []store.RevisionTicket {
+	if RevisionTicketStore == nil {
+		return []store.RevisionTicket{}
+	}
+	tickets := RevisionTicketStore.CreateForOrder(orderID, n)
+	if tickets == nil {
+		tickets = []store.RevisionTicket{}
+	}
+	return tickets
+}
+
+// OrderTicketsRequest is the body for POST /api/admin/orders/{id}/tickets.
+type OrderTicketsRequest struct {
+	Jumlah int `json:"jumlah"` // default 1
+}
+
+// OrderIssueTickets handles POST /api/admin/orders/{id}/tickets: terbitkan tiket revisi tambahan di luar jatah order
+// (mis. revisi berbayar yang dibayar di luar sistem, atau kompensasi).
+func OrderIssueTickets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OrderTicketsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if req.Jumlah == 0 {
+		req.Jumlah = 1
+	}
+	if req.Jumlah < 1 || req.Jumlah > maxRevisiPutaran {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "jumlah harus 1-" + strconv.Itoa(maxRevisiPutaran)})
+		return
+	}
+	if OrderStore == nil || RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	o, ok := OrderStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	tickets := RevisionTicketStore.AddTickets(o.ID, req.Jumlah, store.TicketSourceAdmin)
+	if len(tickets) == 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal membuat tiket"})
+		return
+	}
+	if revisionHandedOver(o.Status) {
+		openRevisionClaimWindow(o.ID)
+		tickets = ticketsByID(o.ID, tickets)
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "tickets": tickets})
+}
+
+// OrdersUpdate handles PUT /api/admin/orders/{id} (edit semua data order kecuali status).
+func OrdersUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OrderAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	item, msg := orderFromRequest(req)
+	w.Header().Set("Content-Type", "application/json")
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	if OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	item.ID = chi.URLParam(r, "id")
+	updated, ok := OrderStore.Update(item)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "order": updated})
+}
+
 // RevisiKlaimRequest for POST /api/revisi/klaim (public: client klaim kupon revisi).
+// Bisa JSON, atau multipart (code, deskripsi, files[]) bila menyertakan lampiran referensi.
 type RevisiKlaimRequest struct {
-	Code string `json:"code"`
+	Code      string `json:"code"`
+	Deskripsi string `json:"deskripsi"` // perubahan yang diminta (wajib)
 }
 
-// RevisiKlaim handles POST /api/revisi/klaim. Client kirim kode tiket → sekali pakai, tercatat.
+// RevisiKlaim handles POST /api/revisi/klaim. Client kirim kode tiket + deskripsi perubahan → sekali pakai, tercatat;
+// revisi masuk alur requested → in_progress → delivered → accepted dan order (in_review/completed) pindah ke revision.
 func RevisiKlaim(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
 	var req RevisiKlaimRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
+	var files []*multipart.FileHeader
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxMessageBytes+(1<<20))
+		if err := r.ParseMultipartForm(8 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "invalid form or files too large (max 25MB)"})
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+		req.Code, req.Deskripsi = r.FormValue("code"), r.FormValue("deskripsi")
+		files = r.MultipartForm.File["files"]
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "invalid JSON"})
 		return
 	}
 	code := strings.TrimSpace(req.Code)
 	if code == "" {
-		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "code required"})
 		return
 	}
+	if !ratelimit.CheckCode(w, r, code) {
+		return
+	}
+	deskripsi := strings.TrimSpace(req.Deskripsi)
+	if utf8.RuneCountInString(deskripsi) < 10 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Jelaskan perubahan yang diinginkan (minimal 10 karakter)."})
+		return
+	}
+	if utf8.RuneCountInString(deskripsi) > maxMessageBodyRunes {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "deskripsi terlalu panjang (maks. 5000 karakter)"})
+		return
+	}
 	if RevisionTicketStore == nil {
-		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusServiceUnavailable)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "service unavailable"})
 		return
 	}
-	ticket, ok := RevisionTicketStore.Redeem(code)
+	invalid := map[string]interface{}{
+		"ok":      false,
+		"message": "Kode tidak valid atau sudah dipakai. Cek kembali kode tiket revisi Anda.",
+	}
+	current, ok := RevisionTicketStore.GetByCode(code)
+	if ok && current.Expired(time.Now()) {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(expiredTicketResponse(current))
+		return
+	}
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
+		ratelimit.Fail(r)
+	}
+	if !ok || current.Status != "unused" {
 		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"ok":     false,
-			"message": "Kode tidak valid atau sudah dipakai. Cek kembali kode tiket revisi Anda.",
-		})
+		_ = json.NewEncoder(w).Encode(invalid)
 		return
 	}
-	remaining := 0
+	atts, status, errMsg := saveAttachments(files, filepath.Join("revisions", current.OrderID))
+	if errMsg != "" {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": errMsg})
+		return
+	}
+	ticket, ok := RevisionTicketStore.Redeem(code, deskripsi, atts)
+	if !ok {
+		removeAttachments(atts)
+		w.WriteHeader(http.StatusOK)
+		if current, found := RevisionTicketStore.GetByCode(code); found && current.Expired(time.Now()) {
+			_ = json.NewEncoder(w).Encode(expiredTicketResponse(current))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(invalid)
+		return
+	}
+	remaining, now := 0, time.Now()
+	for _, t := range RevisionTicketStore.ByOrderID(ticket.OrderID) {
+		if t.Status == "unused" && !t.Expired(now) {
+			remaining++
+		}
+	}
 	if OrderStore != nil {
-		tickets := RevisionTicketStore.ByOrderID(ticket.OrderID)
-		for _, t := range tickets {
-			if t.Status == "unused" {
-				remaining++
+		if o, found := OrderStore.Get(ticket.OrderID); found {
+			if o.Status == store.OrderStatusInReview || o.Status == store.OrderStatusCompleted {
+				OrderStore.Transition(o.ID, o.Status, store.OrderStatusRevision, "client", "Revisi ke-"+strconv.Itoa(ticket.Sequence)+" diminta")
 			}
+			notifyAdmin(notify.Message{
+				Key:     "revision-requested:" + ticket.ID,
+				Subject: "Revisi ke-" + strconv.Itoa(ticket.Sequence) + " diminta: " + o.Layanan,
+				Text:    o.Pemesan + " (" + o.Layanan + "): " + messagePreview(deskripsi, 500),
+			})
 		}
 	}
-	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"ok":            true,
@@ -180,10 +445,12 @@ func RevisiKlaim(w http.ResponseWriter, r *http.Request) {
 		"order_id":      ticket.OrderID,
 		"revisi_ke":     ticket.Sequence,
 		"sisa_revisi":   remaining,
+		"revisi_status": ticket.RevisiStatus,
 	})
 }
 
 // OrdersComplete handles PATCH /api/admin/orders?id=xxx (body: {"complete": true}) to mark order as completed.
+// Shortcut lama untuk PATCH /api/admin/orders/{id}/status dengan status completed.
 func OrdersComplete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPatch {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -198,15 +465,106 @@ func OrdersComplete(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	ok := OrderStore.Complete(id)
+	resp, status := transitionOrder(id, store.OrderStatusCompleted, orderActor(r), "")
 	w.Header().Set("Content-Type", "application/json")
-	if ok {
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
-	} else {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// orderActor returns who is changing an order from an admin request (email admin dari JWT).
+func orderActor(r *http.Request) string {
+	if email := mw.AdminEmail(r.Context()); email != "" {
+		return email
+	}
+	return "admin"
+}
+
+// transitionOrder moves order id to status and returns the JSON response with its HTTP status.
+func transitionOrder(id, status, actor, note string) (map[string]interface{}, int) {
+	order, found := OrderStore.Get(id)
+	if !found {
+		return map[string]interface{}{"ok": false, "message": "not found"}, http.StatusNotFound
+	}
+	if !store.CanTransitionOrder(order.Status, status) {
+		return map[string]interface{}{
+			"ok":            false,
+			"message":       "status " + order.Status + " tidak bisa diubah ke " + status,
+			"status":        order.Status,
+			"next_statuses": store.NextOrderStatuses(order.Status),
+		}, http.StatusConflict
+	}
+	updated, ok := OrderStore.Transition(id, order.Status, status, actor, note)
+	if !ok {
+		return map[string]interface{}{"ok": false, "message": "order sedang diubah, muat ulang lalu coba lagi"}, http.StatusConflict
+	}
+	resp := map[string]interface{}{"ok": true, "order": updated, "next_statuses": store.NextOrderStatuses(updated.Status)}
+	if revisionHandedOver(status) {
+		openRevisionClaimWindow(updated.ID)
+	}
+	// Token ulasan sekali pakai untuk klien (dikirim admin lewat link), terikat ke layanan order.
+	if status == store.OrderStatusCompleted && ReviewStore != nil {
+		layanan := strings.TrimSpace(updated.Layanan)
+		if t, ok := ReviewStore.IssueToken(updated.ID, layanan, serviceIDByTitle(layanan)); ok && t.UsedAt == nil {
+			resp["review_token"] = t.Token
+		}
+	}
+	return resp, http.StatusOK
+}
+
+// OrderStatusRequest is the body for PATCH /api/admin/orders/{id}/status.
+type OrderStatusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"` // opsional, tercatat di riwayat
+}
+
+// OrderSetStatus handles PATCH /api/admin/orders/{id}/status: pindah status sesuai alur order, tercatat di order_events.
+func OrderSetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req OrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	req.Status = strings.TrimSpace(req.Status)
+	if !store.ValidOrderStatus(req.Status) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "status tidak dikenal"})
+		return
+	}
+	if OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	resp, status := transitionOrder(chi.URLParam(r, "id"), req.Status, orderActor(r), strings.TrimSpace(req.Note))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// OrderEvents handles GET /api/admin/orders/{id}/events (riwayat status order).
+func OrderEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+	if _, ok := OrderStore.Get(id); !ok {
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
 	}
+	events := []store.OrderEvent{}
+	events = append(events, OrderStore.Events(id)...)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "events": events})
 }
 
 // OrdersDelete handles DELETE /api/admin/orders?id=xxx.
@@ -224,6 +582,12 @@ func OrdersDelete(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	if _, found := OrderStore.Get(id); found {
+		deleteOrderMessages(id)
+		if RevisionTicketStore != nil {
+			removeAttachments(RevisionTicketStore.RedactOrder(id))
+		}
+	}
 	ok := OrderStore.Delete(id)
 	w.Header().Set("Content-Type", "application/json")
 	if ok {
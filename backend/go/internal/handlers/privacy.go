@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backend/internal/store"
+)
+
+// PrivacyExport handles GET /api/admin/privacy/export?email=xxx (permintaan akses subjek data, UU PDP).
+// Returns every donation, subscription, review, order (dengan thread pesan dan permintaan revisinya) and inquiry tied to the email.
+func PrivacyExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	email := strings.TrimSpace(r.URL.Query().Get("email"))
+	if email == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "email required"})
+		return
+	}
+	donations := []store.Donation{}
+	if DonateStore != nil {
+		donations = append(donations, DonateStore.ListByEmail(email)...)
+	}
+	orders := []store.OrderItem{}
+	if OrderStore != nil {
+		orders = append(orders, OrderStore.ListByEmail(email)...)
+	}
+	reviews := subjectReviews(email, orders, donations)
+	subscriptions := []store.Subscription{}
+	if SubscriptionStore != nil {
+		subscriptions = append(subscriptions, SubscriptionStore.ListByEmail(email)...)
+	}
+	inquiries := []store.Inquiry{}
+	if InquiryStore != nil {
+		inquiries = append(inquiries, InquiryStore.ListByEmail(email)...)
+	}
+	messages := []store.OrderMessage{}
+	if OrderMessageStore != nil {
+		for _, o := range orders {
+			messages = append(messages, OrderMessageStore.List(o.ID)...)
+		}
+	}
+	revisions := []store.RevisionTicket{}
+	if RevisionTicketStore != nil {
+		for _, o := range orders {
+			for _, t := range RevisionTicketStore.ByOrderID(o.ID) {
+				if t.Status == "used" {
+					revisions = append(revisions, t)
+				}
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="data-subjek.json"`)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":            true,
+		"email":         email,
+		"exported_at":   time.Now().UTC(),
+		"donations":     donations,
+		"subscriptions": subscriptions,
+		"reviews":       reviews,
+		"orders":        orders,
+		"inquiries":     inquiries,
+		"messages":      messages,
+		"revisions":     revisions,
+	})
+}
+
+// donationReviewPrefix is the ID prefix of reviews migrated from donation comments ("don-" + ID donasi).
+const donationReviewPrefix = "don-"
+
+// subjectReviews returns reviews submitted with email, verified reviews of the subject's orders and
+// reviews migrated from the subject's donation comments (tanpa email/order, dikenali dari ID-nya).
+func subjectReviews(email string, orders []store.OrderItem, donations []store.Donation) []store.Review {
+	out := []store.Review{}
+	if ReviewStore == nil {
+		return out
+	}
+	seen := make(map[string]bool)
+	for _, rv := range ReviewStore.ListByEmail(email) {
+		seen[rv.ID] = true
+		out = append(out, rv)
+	}
+	for _, d := range donations {
+		if rv, ok := ReviewStore.Get(donationReviewPrefix + d.ID); ok && !seen[rv.ID] {
+			seen[rv.ID] = true
+			out = append(out, rv)
+		}
+	}
+	if len(orders) == 0 {
+		return out
+	}
+	orderIDs := make(map[string]bool, len(orders))
+	for _, o := range orders {
+		orderIDs[o.ID] = true
+	}
+	for _, rv := range ReviewStore.List("") {
+		if !seen[rv.ID] && rv.OrderID != "" && orderIDs[rv.OrderID] {
+			out = append(out, rv)
+		}
+	}
+	return out
+}
+
+// PrivacyEraseRequest is the body for POST /api/admin/privacy/erase.
+type PrivacyEraseRequest struct {
+	Email   string `json:"email"`
+	Confirm bool   `json:"confirm"` // wajib true, mencegah hapus tidak sengaja
+}
+
+// PrivacyErase handles POST /api/admin/privacy/erase (permintaan hapus data subjek, UU PDP).
+// Donasi dianonimkan (nominal tetap untuk pembukuan), bukti transfer dihapus, ulasan dihapus,
+// langganan dibatalkan dan dianonimkan, identitas klien di order dan permintaan order dihapus, thread pesan order dan deskripsi revisi beserta lampirannya dihapus, email di outbox dan payload webhook terkait dibersihkan.
+func PrivacyErase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req PrivacyEraseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	w.Header().Set("Content-Type", "application/json")
+	if email == "" || !req.Confirm {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "email and confirm=true required"})
+		return
+	}
+	counts := map[string]int{"donations": 0, "proofs": 0, "subscriptions": 0, "reviews": 0, "orders": 0, "inquiries": 0, "messages": 0, "attachments": 0, "emails": 0, "webhook_events": 0}
+	// Donasi dicari sebelum dianonimkan: ulasan hasil migrasi komentar donasi dikenali dari ID donasinya.
+	var donations []store.Donation
+	if DonateStore != nil {
+		donations = DonateStore.ListByEmail(email)
+		for _, d := range donations {
+			proof, ok := DonateStore.Anonymize(d.ID)
+			if !ok {
+				continue
+			}
+			counts["donations"]++
+			if proof != "" && os.Remove(filepath.Join(privateUploadDir(), filepath.Clean(proof))) == nil {
+				counts["proofs"]++
+			}
+			if WebhookEventStore != nil {
+				counts["webhook_events"] += WebhookEventStore.RedactOrder(d.OrderID)
+			}
+		}
+	}
+	if SubscriptionStore != nil {
+		for _, sub := range SubscriptionStore.ListByEmail(email) {
+			if SubscriptionStore.Anonymize(sub.ID) {
+				counts["subscriptions"]++
+			}
+		}
+	}
+	var orders []store.OrderItem
+	if OrderStore != nil {
+		orders = OrderStore.ListByEmail(email)
+	}
+	if ReviewStore != nil {
+		for _, rv := range subjectReviews(email, orders, donations) {
+			if ReviewStore.Delete(rv.ID) {
+				counts["reviews"]++
+			}
+		}
+	}
+	for _, o := range orders {
+		if OrderStore.Anonymize(o.ID) {
+			counts["orders"]++
+		}
+		msgs, files := deleteOrderMessages(o.ID)
+		counts["messages"] += msgs
+		counts["attachments"] += files
+		if RevisionTicketStore != nil {
+			counts["attachments"] += removeAttachments(RevisionTicketStore.RedactOrder(o.ID))
+		}
+	}
+	if InquiryStore != nil {
+		for _, q := range InquiryStore.ListByEmail(email) {
+			if InquiryStore.Anonymize(q.ID) {
+				counts["inquiries"]++
+			}
+		}
+	}
+	if MailOutbox != nil {
+		counts["emails"] = MailOutbox.DeleteByRecipient(email)
+	}
+	invalidateLeaderboard()
+	// Email tidak ikut dicatat di log (data pribadi).
+	log.Printf("[privacy] erase request processed: %v", counts)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "erased": counts})
+}
@@ -0,0 +1,585 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/mail"
+	"backend/internal/notify"
+	"backend/internal/pdf"
+	"backend/internal/store"
+)
+
+var QuotationStore *store.QuotationStore
+
+// quotationPurpose is the signedToken purpose of the public quotation link (lihat, setujui, tolak).
+const quotationPurpose = "quotation"
+
+// quotationURL returns the frontend page of a quotation for the client.
+func quotationURL(id string) string {
+	q := url.Values{"id": {id}, "token": {signedToken(quotationPurpose, id)}}
+	return siteBaseURL() + "/penawaran?" + q.Encode()
+}
+
+// QuotationItemRequest is one line in QuotationRequest. HargaSatuan 0 = diambil dari harga layanan bila berupa nominal.
+type QuotationItemRequest struct {
+	ServiceID    string `json:"service_id"`
+	Deskripsi    string `json:"deskripsi"` // default judul layanan
+	Qty          int    `json:"qty"`       // default 1
+	HargaSatuan  int64  `json:"harga_satuan"`
+	DiskonPersen int    `json:"diskon_persen"`
+}
+
+// QuotationRequest is the body for POST /api/admin/quotations and PUT /api/admin/quotations/{id}.
+type QuotationRequest struct {
+	OrderID        string                 `json:"order_id"` // hanya saat membuat
+	Items          []QuotationItemRequest `json:"items"`
+	DPPercent      *int                   `json:"dp_percent"` // default 50
+	Termin2Percent int                    `json:"termin2_percent"`
+	Termin2Waktu   string                 `json:"termin2_waktu"`
+	BerlakuSampai  string                 `json:"berlaku_sampai"` // default hari ini + QUOTE_VALID_DAYS
+	Catatan        string                 `json:"catatan"`
+}
+
+// quotationFromRequest validates req and returns the quotation fields, or an error message.
+func quotationFromRequest(req QuotationRequest) (store.Quotation, string) {
+	q := store.Quotation{
+		DPPercent:      50,
+		Termin2Percent: req.Termin2Percent,
+		Termin2Waktu:   strings.TrimSpace(req.Termin2Waktu),
+		Catatan:        strings.TrimSpace(req.Catatan),
+	}
+	if req.DPPercent != nil {
+		q.DPPercent = *req.DPPercent
+	}
+	if q.DPPercent < 0 || q.Termin2Percent < 0 || q.DPPercent+q.Termin2Percent > 100 {
+		return q, "dp_percent + termin2_percent harus 0-100"
+	}
+	if q.Termin2Percent > 0 && q.Termin2Waktu == "" {
+		return q, "termin2_waktu required bila ada termin 2"
+	}
+	if len(req.Items) == 0 {
+		return q, "minimal satu item"
+	}
+	services := map[string]store.Service{}
+	if ServiceStore != nil {
+		for _, svc := range ServiceStore.ListAll() {
+			services[svc.ID] = svc
+		}
+	}
+	for i, in := range req.Items {
+		it := store.QuotationItem{
+			ServiceID:    strings.TrimSpace(in.ServiceID),
+			Deskripsi:    strings.TrimSpace(in.Deskripsi),
+			Qty:          in.Qty,
+			HargaSatuan:  in.HargaSatuan,
+			DiskonPersen: in.DiskonPersen,
+		}
+		line := "item " + strconv.Itoa(i+1) + ": "
+		if it.ServiceID != "" {
+			svc, ok := services[it.ServiceID]
+			if !ok {
+				return q, line + "layanan tidak ditemukan"
+			}
+			if it.Deskripsi == "" {
+				it.Deskripsi = svc.Title
+			}
+			if it.HargaSatuan == 0 {
+				it.HargaSatuan, _ = store.ParseRupiah(strings.TrimSuffix(strings.TrimSpace(svc.PriceAwal), "(mulai dari)"))
+			}
+		}
+		if it.Qty == 0 {
+			it.Qty = 1
+		}
+		switch {
+		case it.Deskripsi == "":
+			return q, line + "deskripsi required"
+		case it.Qty < 0 || it.Qty > 10000:
+			return q, line + "qty harus 1-10000"
+		case it.HargaSatuan <= 0:
+			return q, line + "harga_satuan required"
+		case it.DiskonPersen < 0 || it.DiskonPersen > 100:
+			return q, line + "diskon_persen harus 0-100"
+		}
+		q.Items = append(q.Items, it)
+	}
+	today := store.NewDate(time.Now().In(wib))
+	if strings.TrimSpace(req.BerlakuSampai) == "" {
+		days := 14
+		if OrderCfg != nil && OrderCfg.QuoteValidDays > 0 {
+			days = OrderCfg.QuoteValidDays
+		}
+		q.BerlakuSampai = store.NewDate(today.AddDate(0, 0, days))
+	} else {
+		d, ok := store.ParseDate(req.BerlakuSampai)
+		if !ok {
+			return q, "berlaku_sampai: format tanggal tidak valid (gunakan YYYY-MM-DD)"
+		}
+		if d.Before(today.Time) {
+			return q, "berlaku_sampai sudah lewat"
+		}
+		q.BerlakuSampai = d
+	}
+	return q, ""
+}
+
+// QuotationsList handles GET /api/admin/quotations?order_id=xxx.
+func QuotationsList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list := []store.Quotation{}
+	if QuotationStore != nil {
+		list = append(list, QuotationStore.List(r.URL.Query().Get("order_id"))...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "quotations": list})
+}
+
+// QuotationCreate handles POST /api/admin/quotations (draft baru untuk order_id).
+func QuotationCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req QuotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if QuotationStore == nil || OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, ok := OrderStore.Get(strings.TrimSpace(req.OrderID)); !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order_id tidak ditemukan"})
+		return
+	}
+	q, msg := quotationFromRequest(req)
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	q.OrderID = strings.TrimSpace(req.OrderID)
+	q = QuotationStore.Add(q)
+	if q.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan penawaran"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "quotation": q})
+}
+
+// QuotationUpdate handles PUT /api/admin/quotations/{id} (hanya draft; penawaran terkirim dibuat ulang).
+func QuotationUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req QuotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	q, msg := quotationFromRequest(req)
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	if QuotationStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q.ID = chi.URLParam(r, "id")
+	updated, ok := QuotationStore.Update(q)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found or not a draft"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "quotation": updated})
+}
+
+// QuotationSend handles POST /api/admin/quotations/{id}/send: status sent, order inquiry -> quoted,
+// dan link penawaran dikirim ke email pemesan (link juga dikembalikan untuk dibagikan lewat WhatsApp).
+func QuotationSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if QuotationStore == nil || OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q, ok := QuotationStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	order, ok := OrderStore.Get(q.OrderID)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order not found"})
+		return
+	}
+	if order.Status != store.OrderStatusInquiry && order.Status != store.OrderStatusQuoted {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order berstatus " + order.Status + ", penawaran hanya untuk order inquiry/quoted"})
+		return
+	}
+	if q.Expired(store.NewDate(time.Now().In(wib))) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "masa berlaku penawaran sudah lewat, buat penawaran baru"})
+		return
+	}
+	q, ok = QuotationStore.MarkSent(q.ID)
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "penawaran sudah dijawab klien"})
+		return
+	}
+	if order.Status == store.OrderStatusInquiry {
+		if updated, ok := OrderStore.Transition(order.ID, order.Status, store.OrderStatusQuoted, orderActor(r), "Penawaran "+q.Nomor+" dikirim"); ok {
+			order = updated
+		}
+	}
+	link := quotationURL(q.ID)
+	emailed := false
+	if order.EmailPemesan != "" {
+		msg, err := mail.QuotationLink(mail.QuotationData{
+			Name:          order.Pemesan,
+			Email:         order.EmailPemesan,
+			Nomor:         q.Nomor,
+			Layanan:       order.Layanan,
+			Total:         int(q.Total),
+			BerlakuSampai: q.BerlakuSampai.Time,
+			URL:           link,
+		})
+		if err != nil {
+			log.Printf("[mail] quotation %s: %v", q.ID, err)
+		} else if emailed = enqueueMail("quotation:"+q.ID+":"+q.SentAt.Format(time.RFC3339), msg); emailed && MailDispatcher != nil {
+			go MailDispatcher.Run(context.Background())
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "quotation": q, "order": order, "url": link, "emailed": emailed})
+}
+
+// quotationPDFData converts a quotation and its order to the PDF fields.
+func quotationPDFData(q store.Quotation, order store.OrderItem) *pdf.QuotationData {
+	data := &pdf.QuotationData{
+		Nomor:         q.Nomor,
+		Tanggal:       mail.DateID(q.CreatedAt.In(wib)),
+		BerlakuSampai: mail.DateID(q.BerlakuSampai.Time),
+		KepadaNama:    order.Pemesan,
+		KepadaEmail:   order.EmailPemesan,
+		Perihal:       "Penawaran harga " + order.Layanan,
+		Total:         mail.FormatIDR(int(q.Total)),
+		Terbilang:     capitalize(pdf.Terbilang(q.Total)) + " rupiah",
+		Catatan:       q.Catatan,
+	}
+	if q.SentAt != nil {
+		data.Tanggal = mail.DateID(q.SentAt.In(wib))
+	}
+	if OrderCfg != nil && OrderCfg.ProviderName != "" {
+		data.Penyedia = OrderCfg.ProviderName
+	}
+	if q.Diskon > 0 {
+		data.Bruto = mail.FormatIDR(int(q.Bruto))
+		data.Diskon = mail.FormatIDR(int(q.Diskon))
+	}
+	for _, it := range q.Items {
+		line := pdf.QuotationLine{
+			Deskripsi: it.Deskripsi,
+			Qty:       strconv.Itoa(it.Qty),
+			Harga:     mail.FormatIDR(int(it.HargaSatuan)),
+			Subtotal:  mail.FormatIDR(int(it.Subtotal)),
+		}
+		if it.DiskonPersen > 0 {
+			line.Diskon = strconv.Itoa(it.DiskonPersen) + "%"
+		}
+		data.Lines = append(data.Lines, line)
+	}
+	dp, termin2, pelunasan := q.PaymentSplit()
+	if q.DPPercent > 0 {
+		data.Pembayaran = append(data.Pembayaran, "Uang muka (DP) "+strconv.Itoa(q.DPPercent)+"%: "+mail.FormatIDR(int(dp))+", sebelum pekerjaan dimulai")
+	}
+	if q.Termin2Percent > 0 {
+		data.Pembayaran = append(data.Pembayaran, "Termin progress "+strconv.Itoa(q.Termin2Percent)+"%: "+mail.FormatIDR(int(termin2))+", "+q.Termin2Waktu)
+	}
+	data.Pembayaran = append(data.Pembayaran, "Pelunasan "+strconv.Itoa(100-q.DPPercent-q.Termin2Percent)+"%: "+mail.FormatIDR(int(pelunasan))+", saat serah terima")
+	return data
+}
+
+// capitalize upper-cases the first letter of s ("lima juta" -> "Lima juta").
+func capitalize(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// writeQuotationPDF renders q as PDF to w (inline; nama file dari nomor penawaran).
+func writeQuotationPDF(w http.ResponseWriter, q store.Quotation) {
+	order, _ := OrderStore.Get(q.OrderID)
+	pdfBytes, err := pdf.GenerateQuotationPDF(quotationPDFData(q, order))
+	if err != nil {
+		http.Error(w, `{"ok":false,"message":"failed to generate PDF"}`, http.StatusInternalServerError)
+		return
+	}
+	filename := "penawaran_" + strings.ReplaceAll(q.Nomor, "/", "-") + ".pdf"
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=\""+filename+"\"")
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
+
+// QuotationPDFAdmin handles GET /api/admin/quotations/{id}/pdf (termasuk draft, untuk pratinjau).
+func QuotationPDFAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if QuotationStore == nil || OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q, ok := QuotationStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		http.Error(w, `{"ok":false,"message":"not found"}`, http.StatusNotFound)
+		return
+	}
+	writeQuotationPDF(w, q)
+}
+
+// agreementFromQuotation prefills the agreement (perjanjian) from an accepted quotation:
+// para pihak dari config + order, tier layanan, nilai proyek, pembagian pembayaran dan jatah revisi order.
+func agreementFromQuotation(q store.Quotation, order store.OrderItem) pdf.AgreementData {
+	thousands := func(n int64) string { return strings.TrimPrefix(mail.FormatIDR(int(n)), "Rp ") }
+	dp, termin2, pelunasan := q.PaymentSplit()
+	data := pdf.AgreementData{
+		Tier:                 serviceTier(order.Layanan),
+		NomorPerjanjian:      strings.Replace(q.Nomor, "RP-PN", "RP-PJ", 1),
+		P2Nama:               order.Pemesan,
+		P2Email:              order.EmailPemesan,
+		NilaiProyekAngka:     thousands(q.Total),
+		NilaiProyekTerbilang: capitalize(pdf.Terbilang(q.Total)) + " rupiah",
+		DPPercent:            strconv.Itoa(q.DPPercent) + "%",
+		DPAmount:             thousands(dp),
+		Termin2Percent:       strconv.Itoa(q.Termin2Percent) + "%",
+		Termin2Amount:        thousands(termin2),
+		Termin2Waktu:         q.Termin2Waktu,
+		PelunasanPercent:     strconv.Itoa(100-q.DPPercent-q.Termin2Percent) + "%",
+		PelunasanAmount:      thousands(pelunasan),
+	}
+	if q.Termin2Percent == 0 {
+		data.Termin2Waktu = "-"
+	}
+	if cfg := OrderCfg; cfg != nil {
+		data.P1Nama, data.P1Alamat, data.P1Email, data.P1Telepon = cfg.ProviderName, cfg.ProviderAddress, cfg.ProviderEmail, cfg.ProviderPhone
+		data.Tempat = cfg.ProviderCity
+		data.BankName, data.BankNumber, data.BankAccount = cfg.BankName, cfg.BankNumber, cfg.BankAccount
+	}
+	data.RevisiPutaran = jumlahTerbilang(order.RevisiPutaran)
+	data.RevisiHari = revisionClaimDaysText()
+	return data
+}
+
+// QuotationAgreement handles GET /api/admin/quotations/{id}/agreement: AgreementData terisi dari penawaran,
+// siap diedit lalu dikirim ke POST /api/admin/agreement/pdf.
+func QuotationAgreement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if QuotationStore == nil || OrderStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	q, ok := QuotationStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	order, _ := OrderStore.Get(q.OrderID)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "agreement": agreementFromQuotation(q, order), "quotation_status": q.Status})
+}
+
+// publicQuotation returns the quotation for a valid public token; draft tidak pernah terlihat klien.
+func publicQuotation(w http.ResponseWriter, id, token string) (store.Quotation, bool) {
+	if QuotationStore == nil || !verifySignedToken(quotationPurpose, id, token) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "link penawaran tidak valid"})
+		return store.Quotation{}, false
+	}
+	q, ok := QuotationStore.Get(id)
+	if !ok || q.Status == store.QuotationStatusDraft {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "penawaran tidak ditemukan"})
+		return store.Quotation{}, false
+	}
+	return q, true
+}
+
+// QuotationPublic handles GET /api/quotations/{id}?token=xxx (halaman penawaran untuk klien).
+func QuotationPublic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q, ok := publicQuotation(w, chi.URLParam(r, "id"), r.URL.Query().Get("token"))
+	if !ok {
+		return
+	}
+	var layanan, pemesan string
+	if OrderStore != nil {
+		if order, found := OrderStore.Get(q.OrderID); found {
+			layanan, pemesan = order.Layanan, order.Pemesan
+		}
+	}
+	dp, termin2, pelunasan := q.PaymentSplit()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":             true,
+		"nomor":          q.Nomor,
+		"layanan":        layanan,
+		"kepada":         pemesan,
+		"items":          q.Items,
+		"bruto":          q.Bruto,
+		"diskon":         q.Diskon,
+		"total":          q.Total,
+		"pembayaran":     map[string]interface{}{"dp_percent": q.DPPercent, "dp": dp, "termin2_percent": q.Termin2Percent, "termin2": termin2, "termin2_waktu": q.Termin2Waktu, "pelunasan": pelunasan},
+		"berlaku_sampai": q.BerlakuSampai,
+		"expired":        q.Status == store.QuotationStatusSent && q.Expired(store.NewDate(time.Now().In(wib))),
+		"catatan":        q.Catatan,
+		"status":         q.Status,
+	})
+}
+
+// QuotationPDFPublic handles GET /api/quotations/{id}/pdf?token=xxx.
+func QuotationPDFPublic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q, ok := publicQuotation(w, chi.URLParam(r, "id"), r.URL.Query().Get("token"))
+	if !ok {
+		return
+	}
+	writeQuotationPDF(w, q)
+}
+
+// QuotationRespondRequest is the body for POST /api/quotations/{id}/accept and /decline.
+type QuotationRespondRequest struct {
+	Token  string `json:"token"`
+	Alasan string `json:"alasan"` // opsional, saat menolak
+}
+
+// QuotationAccept handles POST /api/quotations/{id}/accept (public): nilai order = total penawaran,
+// order quoted -> agreement_sent, lalu admin menyiapkan perjanjian dari GET /api/admin/quotations/{id}/agreement.
+func QuotationAccept(w http.ResponseWriter, r *http.Request) {
+	quotationRespond(w, r, store.QuotationStatusAccepted)
+}
+
+// QuotationDecline handles POST /api/quotations/{id}/decline (public): order kembali ke inquiry agar bisa ditawar ulang.
+func QuotationDecline(w http.ResponseWriter, r *http.Request) {
+	quotationRespond(w, r, store.QuotationStatusDeclined)
+}
+
+func quotationRespond(w http.ResponseWriter, r *http.Request, status string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req QuotationRespondRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	q, ok := publicQuotation(w, chi.URLParam(r, "id"), req.Token)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if q.Status != store.QuotationStatusSent {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "penawaran ini sudah dijawab", "status": q.Status})
+		return
+	}
+	if status == store.QuotationStatusAccepted && q.Expired(store.NewDate(time.Now().In(wib))) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "masa berlaku penawaran sudah lewat, silakan hubungi kami untuk penawaran baru"})
+		return
+	}
+	order, found := OrderStore.Get(q.OrderID)
+	if !found || order.Status != store.OrderStatusQuoted {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "penawaran ini sudah tidak berlaku, silakan hubungi kami"})
+		return
+	}
+	alasan := ""
+	if status == store.QuotationStatusDeclined {
+		alasan = strings.TrimSpace(req.Alasan)
+		if utf8.RuneCountInString(alasan) > 1000 {
+			alasan = string([]rune(alasan)[:1000])
+		}
+	}
+	q, ok = QuotationStore.Respond(q.ID, status, alasan)
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "penawaran ini sudah dijawab"})
+		return
+	}
+	var message string
+	if status == store.QuotationStatusAccepted {
+		order.NilaiKesepakatan = q.Total
+		if updated, ok := OrderStore.Update(order); ok {
+			order = updated
+		}
+		OrderStore.Transition(order.ID, store.OrderStatusQuoted, store.OrderStatusAgreementSent, "client", "Penawaran "+q.Nomor+" disetujui")
+		message = "Terima kasih, penawaran disetujui. Kami akan segera mengirimkan surat perjanjian kerja."
+		notifyAdmin(notify.Message{
+			Key:     "quotation-accepted:" + q.ID,
+			Subject: "Penawaran " + q.Nomor + " disetujui",
+			Text:    order.Pemesan + " menyetujui penawaran " + q.Nomor + " (" + order.Layanan + ") senilai " + mail.FormatIDR(int(q.Total)) + ". Siapkan perjanjian dari menu penawaran.",
+		})
+	} else {
+		note := "Penawaran " + q.Nomor + " ditolak"
+		if alasan != "" {
+			note += ": " + alasan
+		}
+		OrderStore.Transition(order.ID, store.OrderStatusQuoted, store.OrderStatusInquiry, "client", note)
+		message = "Terima kasih atas tanggapannya. Kami akan menghubungi Anda untuk menyesuaikan penawaran."
+		notifyAdmin(notify.Message{Key: "quotation-declined:" + q.ID, Subject: note, Text: order.Pemesan + " (" + order.Layanan + "): " + note})
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": message, "status": q.Status})
+}
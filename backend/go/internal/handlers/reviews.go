@@ -3,27 +3,339 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/middleware/ratelimit"
 	"backend/internal/store"
 )
 
+// ReviewStore is the review (ulasan) store (injected in main).
+var ReviewStore *store.ReviewStore
+
+// PublicReview is a review as shown publicly (tanpa email/order/catatan admin).
+type PublicReview struct {
+	ID          string    `json:"id"`
+	Rating      int       `json:"rating"`
+	DisplayName string    `json:"display_name"`
+	Comment     string    `json:"comment"`
+	ServiceID   string    `json:"service_id,omitempty"`
+	Layanan     string    `json:"layanan,omitempty"`
+	Verified    bool      `json:"verified_client"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 // ReviewsResponse is the JSON for GET /api/reviews.
 type ReviewsResponse struct {
-	OK      bool            `json:"ok"`
-	Reviews []store.Donation `json:"reviews"`
+	OK      bool           `json:"ok"`
+	Reviews []PublicReview `json:"reviews"`
 }
 
-// ReviewsList handles GET /api/reviews (donations < threshold with comment, for public ulasan).
+func toPublicReview(rv store.Review) PublicReview {
+	return PublicReview{
+		ID:          rv.ID,
+		Rating:      rv.Rating,
+		DisplayName: rv.DisplayName,
+		Comment:     rv.Comment,
+		ServiceID:   rv.ServiceID,
+		Layanan:     rv.Layanan,
+		Verified:    rv.Verified,
+		CreatedAt:   rv.CreatedAt,
+	}
+}
+
+// ReviewsList handles GET /api/reviews (public: hanya ulasan approved, tanpa data pribadi). Optional ?service_id=.
 func ReviewsList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	list := DonateStore.ListReviews()
-	if list == nil {
-		list = []store.Donation{}
+	serviceID := r.URL.Query().Get("service_id")
+	list := []PublicReview{}
+	if ReviewStore != nil {
+		for _, rv := range ReviewStore.List(store.ReviewStatusApproved) {
+			if serviceID != "" && rv.ServiceID != serviceID {
+				continue
+			}
+			list = append(list, toPublicReview(rv))
+		}
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(ReviewsResponse{OK: true, Reviews: list})
 }
+
+// ReviewSubmitRequest is the body for POST /api/reviews and POST /api/admin/reviews.
+type ReviewSubmitRequest struct {
+	Rating      int    `json:"rating"` // 1-5
+	DisplayName string `json:"display_name"`
+	Comment     string `json:"comment"`
+	Email       string `json:"email"`      // opsional, hanya untuk admin
+	ServiceID   string `json:"service_id"` // opsional
+	OrderID     string `json:"order_id"`   // hanya admin
+	Status      string `json:"status"`     // hanya admin; default approved
+}
+
+// reviewFromRequest validates the common fields; returns an error message or "".
+func reviewFromRequest(req ReviewSubmitRequest) (store.Review, string) {
+	rv := store.Review{
+		Rating:      req.Rating,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Comment:     strings.TrimSpace(req.Comment),
+		Email:       strings.TrimSpace(req.Email),
+		ServiceID:   strings.TrimSpace(req.ServiceID),
+	}
+	if rv.Rating < 1 || rv.Rating > 5 {
+		return rv, "rating harus 1-5"
+	}
+	if rv.DisplayName == "" || utf8.RuneCountInString(rv.DisplayName) > 60 {
+		return rv, "display_name wajib (maks 60 karakter)"
+	}
+	if rv.Comment == "" || utf8.RuneCountInString(rv.Comment) > 2000 {
+		return rv, "comment wajib (maks 2000 karakter)"
+	}
+	if rv.ServiceID != "" && !serviceExists(rv.ServiceID) {
+		return rv, "layanan tidak ditemukan"
+	}
+	return rv, ""
+}
+
+func serviceExists(id string) bool {
+	if ServiceStore == nil {
+		return false
+	}
+	for _, s := range ServiceStore.ListAll() {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceIDByTitle maps an order's Layanan text to a service ID (judul sama, tanpa beda huruf besar/kecil); "" bila tidak ada.
+func serviceIDByTitle(title string) string {
+	if ServiceStore == nil || title == "" {
+		return ""
+	}
+	for _, s := range ServiceStore.ListAll() {
+		if strings.EqualFold(strings.TrimSpace(s.Title), title) {
+			return s.ID
+		}
+	}
+	return ""
+}
+
+// ReviewSubmit handles POST /api/reviews (public). Ulasan masuk sebagai pending sampai dimoderasi admin.
+func ReviewSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ReviewSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if ReviewStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rv, msg := reviewFromRequest(req)
+	w.Header().Set("Content-Type", "application/json")
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	rv.Source = "form"
+	rv.Status = store.ReviewStatusPending
+	rv = ReviewStore.Add(rv)
+	if rv.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "failed to save"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": "Terima kasih! Ulasan akan tampil setelah ditinjau admin."})
+}
+
+// ReviewVerifiedRequest is the body for POST /api/reviews/verified.
+type ReviewVerifiedRequest struct {
+	Token       string `json:"token"`
+	Rating      int    `json:"rating"`
+	DisplayName string `json:"display_name"`
+	Comment     string `json:"comment"`
+}
+
+// ReviewSubmitVerified handles POST /api/reviews/verified (public). Token dari order yang selesai, sekali pakai;
+// ulasan ditandai "verified client" dan terhubung ke layanan order. Tetap lewat moderasi admin.
+func ReviewSubmitVerified(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ReviewVerifiedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if ReviewStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rv, msg := reviewFromRequest(ReviewSubmitRequest{Rating: req.Rating, DisplayName: req.DisplayName, Comment: req.Comment})
+	w.Header().Set("Content-Type", "application/json")
+	if msg == "" && strings.TrimSpace(req.Token) == "" {
+		msg = "token required"
+	}
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	rv.Status = store.ReviewStatusPending
+	rv, ok := ReviewStore.RedeemToken(strings.TrimSpace(req.Token), rv)
+	if !ok {
+		ratelimit.Fail(r)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Link ulasan tidak valid atau sudah dipakai."})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": "Terima kasih! Ulasan akan tampil setelah ditinjau admin.", "layanan": rv.Layanan})
+}
+
+// ReviewsListAdmin handles GET /api/admin/reviews (semua ulasan lengkap). Optional ?status=pending|approved|hidden.
+func ReviewsListAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && !store.ValidReviewStatus(status) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "invalid status"})
+		return
+	}
+	list := []store.Review{}
+	if ReviewStore != nil {
+		if l := ReviewStore.List(status); l != nil {
+			list = l
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "reviews": list})
+}
+
+// ReviewsAdd handles POST /api/admin/reviews (admin memasukkan testimoni, mis. dari chat). Default status approved.
+func ReviewsAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ReviewSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if ReviewStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rv, msg := reviewFromRequest(req)
+	if msg == "" && req.Status != "" && !store.ValidReviewStatus(req.Status) {
+		msg = "invalid status"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if msg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return
+	}
+	rv.OrderID = strings.TrimSpace(req.OrderID)
+	rv.Source = "admin"
+	rv.Status = req.Status
+	if rv.Status == "" {
+		rv.Status = store.ReviewStatusApproved
+	}
+	if rv.Status != store.ReviewStatusPending {
+		now := time.Now().UTC()
+		rv.ModeratedAt = &now
+	}
+	rv = ReviewStore.Add(rv)
+	if rv.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "failed to save"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "review": rv})
+}
+
+// ReviewModerateRequest is the body for POST /api/admin/reviews/{id}/moderate.
+type ReviewModerateRequest struct {
+	Status string `json:"status"` // pending | approved | hidden
+	Note   string `json:"note"`
+}
+
+// ReviewModerate handles POST /api/admin/reviews/{id}/moderate.
+func ReviewModerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ReviewModerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !store.ValidReviewStatus(req.Status) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "status must be pending, approved or hidden"})
+		return
+	}
+	if ReviewStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rv, ok := ReviewStore.Moderate(chi.URLParam(r, "id"), req.Status, strings.TrimSpace(req.Note))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "review": rv})
+}
+
+// ReviewsDelete handles DELETE /api/admin/reviews?id=xxx.
+func ReviewsDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if ReviewStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ok := ReviewStore.Delete(id)
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+	}
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/mail"
+	"backend/internal/notify"
+	"backend/internal/payment"
+	"backend/internal/store"
+)
+
+// RevisionPurchaseStore holds online purchases of extra revision tickets (injected in main).
+var RevisionPurchaseStore *store.RevisionPurchaseStore
+
+// revisionPurchasePrefix marks payment gateway order IDs of revision purchases (donasi memakai "donate-").
+const revisionPurchasePrefix = "revisi-"
+
+// maxRevisionPurchaseQty caps the tickets bought in one payment.
+const maxRevisionPurchaseQty = 5
+
+// revisionPurchasableStatuses are the order statuses in which extra revisions can be bought (pekerjaan sudah berjalan).
+var revisionPurchasableStatuses = map[string]bool{
+	store.OrderStatusDPPaid:     true,
+	store.OrderStatusInProgress: true,
+	store.OrderStatusInReview:   true,
+	store.OrderStatusRevision:   true,
+	store.OrderStatusCompleted:  true,
+}
+
+// revisionExtraPrice returns the price of one extra revision ticket (0 = tidak dijual).
+func revisionExtraPrice() int {
+	if OrderCfg == nil {
+		return 0
+	}
+	return OrderCfg.RevisiExtraPrice
+}
+
+// RevisiTambahanRequest is the optional body for POST /api/orders/track/{code}/revisi-tambahan.
+type RevisiTambahanRequest struct {
+	Qty int `json:"qty"` // default 1
+}
+
+// OrderTrackRevisionPurchase handles POST /api/orders/track/{code}/revisi-tambahan (public): beli tiket revisi
+// tambahan lewat payment gateway. Tiket terbit otomatis saat webhook pembayaran lunas, dan kodenya dikirim ke email pemesan.
+func OrderTrackRevisionPurchase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req RevisiTambahanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if req.Qty == 0 {
+		req.Qty = 1
+	}
+	if req.Qty < 1 || req.Qty > maxRevisionPurchaseQty {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "qty harus 1-" + strconv.Itoa(maxRevisionPurchaseQty)})
+		return
+	}
+	price := revisionExtraPrice()
+	if price <= 0 || RevisionPurchaseStore == nil || RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Revisi tambahan tidak dijual online. Hubungi kami lewat pesan order."})
+		return
+	}
+	o, ok := trackedOrder(w, r)
+	if !ok {
+		return
+	}
+	if !revisionPurchasableStatuses[o.Status] {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Revisi tambahan baru bisa dibeli setelah pengerjaan order dimulai."})
+		return
+	}
+	if strings.TrimSpace(o.EmailPemesan) == "" {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Order ini belum punya email pemesan untuk pengiriman kode tiket. Hubungi kami lewat pesan order."})
+		return
+	}
+	if PaymentGateway == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Pembayaran online sedang tidak tersedia."})
+		return
+	}
+
+	paymentOrderID := fmt.Sprintf("%s%d-%s", revisionPurchasePrefix, time.Now().Unix(), randomHex(6))
+	amount := price * req.Qty
+	tx, err := PaymentGateway.CreateTransaction(r.Context(), payment.CreateRequest{
+		OrderID:     paymentOrderID,
+		Amount:      amount,
+		Description: strconv.Itoa(req.Qty) + "x revisi tambahan " + o.Layanan,
+		Customer:    payment.Customer{Name: o.Pemesan, Email: o.EmailPemesan},
+		Metadata:    map[string]string{"order_id": o.ID, "qty": strconv.Itoa(req.Qty)},
+	})
+	if err != nil {
+		log.Printf("[revisi] create transaction via %s: %v", PaymentGateway.Name(), err)
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "payment gateway tidak mengembalikan token"})
+		return
+	}
+	p := RevisionPurchaseStore.Add(store.RevisionPurchase{OrderID: o.ID, PaymentOrderID: paymentOrderID, Qty: req.Qty, Amount: amount})
+	if p.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan pembelian"})
+		return
+	}
+
+	out := DonateCreateTransactionResponse{
+		OK:          true,
+		Gateway:     PaymentGateway.Name(),
+		RedirectURL: tx.RedirectURL,
+		OrderID:     paymentOrderID,
+		ClientKey:   tx.ClientKey,
+	}
+	if PaymentGateway.Name() == "midtrans" {
+		out.SnapToken = tx.Token
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// OrderTrackRevisionPurchases handles GET /api/orders/track/{code}/revisi-tambahan (public): harga dan riwayat pembelian.
+// Kode tiket tidak ditampilkan di sini (sama seperti OrderTrack); kode hanya dikirim ke email pemesan.
+func OrderTrackRevisionPurchases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	o, ok := trackedOrder(w, r)
+	if !ok {
+		return
+	}
+	purchases := []map[string]interface{}{}
+	if RevisionPurchaseStore != nil {
+		for _, p := range RevisionPurchaseStore.ListByOrder(o.ID) {
+			purchases = append(purchases, map[string]interface{}{
+				"qty":        p.Qty,
+				"amount":     p.Amount,
+				"status":     p.Status,
+				"paid_at":    p.PaidAt,
+				"created_at": p.CreatedAt,
+			})
+		}
+	}
+	price := revisionExtraPrice()
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":        true,
+		"harga":     price,
+		"dijual":    price > 0 && PaymentGateway != nil && revisionPurchasableStatuses[o.Status],
+		"maks_qty":  maxRevisionPurchaseQty,
+		"purchases": purchases,
+	})
+}
+
+// applyRevisionPurchaseEvent settles a revision purchase from a verified gateway event: saat lunas tiket diterbitkan
+// (MarkPaid menjaga agar hanya satu proses yang menandai lunas) dan kodenya dikirim ke email pemesan.
+// Bila tiket yang terbit kurang dari jumlah yang dibayar, hasilnya "error:" sehingga event dicatat gagal
+// dan pengiriman ulang/replay menerbitkan sisanya.
+func applyRevisionPurchaseEvent(ev payment.Event) string {
+	if RevisionPurchaseStore == nil || RevisionTicketStore == nil {
+		return "ignored: revision purchases not configured"
+	}
+	p, ok := RevisionPurchaseStore.FindByPaymentOrderID(ev.OrderID)
+	if !ok {
+		return "ignored: unknown revision purchase"
+	}
+	switch {
+	case payment.IsPaid(ev.Status):
+		if ev.Amount > 0 && ev.Amount < p.Amount {
+			return "error: paid amount " + strconv.Itoa(ev.Amount) + " < " + strconv.Itoa(p.Amount)
+		}
+		if paid, ok := RevisionPurchaseStore.MarkPaid(p.ID); ok {
+			p = paid
+		} else if p.Status != store.RevisionPurchasePaid || len(p.TicketCodes) >= p.Qty {
+			return "unchanged: " + p.Status
+		}
+		return issueRevisionPurchaseTickets(p)
+	case ev.Status == payment.StatusExpire || ev.Status == payment.StatusCancel || ev.Status == payment.StatusDeny || ev.Status == payment.StatusFailure:
+		if RevisionPurchaseStore.MarkFailed(p.ID) {
+			return "revision purchase " + p.ID + " failed (" + ev.Status + ")"
+		}
+		return "unchanged: " + p.Status
+	default:
+		return "unchanged: " + p.Status
+	}
+}
+
+// issueRevisionPurchaseTickets issues the tickets of a paid purchase that are still missing (bisa dijalankan ulang).
+func issueRevisionPurchaseTickets(p store.RevisionPurchase) string {
+	missing := p.Qty - len(p.TicketCodes)
+	if missing <= 0 {
+		return "unchanged: " + p.Status
+	}
+	codes := append([]string{}, p.TicketCodes...)
+	for _, t := range RevisionTicketStore.AddTickets(p.OrderID, missing, store.TicketSourcePurchase) {
+		codes = append(codes, t.Code)
+	}
+	if len(codes) > len(p.TicketCodes) {
+		RevisionPurchaseStore.SetTicketCodes(p.ID, codes)
+		if OrderStore != nil {
+			if o, found := OrderStore.Get(p.OrderID); found && revisionHandedOver(o.Status) {
+				openRevisionClaimWindow(o.ID)
+			}
+		}
+	}
+	if len(codes) < p.Qty {
+		return "error: issued " + strconv.Itoa(len(codes)) + " of " + strconv.Itoa(p.Qty) + " tickets for purchase " + p.ID
+	}
+	notifyRevisionPurchase(p, codes)
+	return "issued " + strconv.Itoa(missing) + " revision tickets for purchase " + p.ID
+}
+
+// notifyRevisionPurchase emails the ticket codes to the client and tells admin about the sale.
+func notifyRevisionPurchase(p store.RevisionPurchase, codes []string) {
+	if OrderStore == nil {
+		return
+	}
+	o, ok := OrderStore.Get(p.OrderID)
+	if !ok {
+		return
+	}
+	notifyAdmin(notify.Message{
+		Key:     "revision-purchase:" + p.ID,
+		Subject: "Revisi tambahan terjual: " + o.Layanan,
+		Text:    o.Pemesan + " membeli " + strconv.Itoa(p.Qty) + " tiket revisi tambahan (" + mail.FormatIDR(p.Amount) + ") untuk " + o.Layanan + ".",
+	})
+	if o.EmailPemesan == "" || len(codes) == 0 {
+		return
+	}
+	m, err := mail.RevisionPurchase(mail.RevisionPurchaseData{
+		Name:    o.Pemesan,
+		Email:   o.EmailPemesan,
+		Layanan: o.Layanan,
+		Amount:  p.Amount,
+		Codes:   codes,
+		URL:     siteBaseURL() + "/revisi",
+	})
+	if err != nil {
+		log.Printf("[mail] revision purchase %s: %v", p.ID, err)
+		return
+	}
+	if enqueueMail("revision-purchase:"+p.ID, m) && MailDispatcher != nil {
+		go MailDispatcher.Run(context.Background())
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/mail"
+	"backend/internal/pdf"
+	"backend/internal/store"
+)
+
+// revisionClaimURL returns the /revisi page link with the ticket code prefilled (dipakai sebagai isi QR code).
+func revisionClaimURL(code string) string {
+	return siteBaseURL() + "/revisi?" + url.Values{"kode": {code}}.Encode()
+}
+
+// revisionVoucherData returns the voucher sheet of an order's tickets that can still be claimed (bisa kosong).
+func revisionVoucherData(o store.OrderItem, now time.Time) *pdf.RevisionVoucherData {
+	data := &pdf.RevisionVoucherData{
+		Nomor:   o.ID,
+		Tanggal: mail.DateID(now.In(wib)),
+		Pemesan: o.Pemesan,
+		Layanan: o.Layanan,
+		URL:     siteBaseURL() + "/revisi",
+	}
+	if days, ok := revisionClaimDays(); ok {
+		data.MasaKlaim = jumlahTerbilang(days) + " hari"
+	}
+	for _, t := range RevisionTicketStore.ByOrderID(o.ID) {
+		if t.Status != "unused" || t.Expired(now) {
+			continue
+		}
+		v := pdf.RevisionVoucher{Kode: t.Code, Ke: t.Sequence, URL: revisionClaimURL(t.Code)}
+		if t.ExpiresAt != nil {
+			v.BerlakuSampai = mail.DateID(t.ExpiresAt.Add(-time.Second).In(wib))
+		}
+		data.Vouchers = append(data.Vouchers, v)
+	}
+	return data
+}
+
+// revisionVoucherOrder loads the order of {id} and its voucher sheet, writing the JSON error when there is nothing to print.
+func revisionVoucherOrder(w http.ResponseWriter, r *http.Request) (store.OrderItem, *pdf.RevisionVoucherData, bool) {
+	if OrderStore == nil || RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return store.OrderItem{}, nil, false
+	}
+	o, ok := OrderStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return store.OrderItem{}, nil, false
+	}
+	data := revisionVoucherData(o, time.Now())
+	if len(data.Vouchers) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order ini tidak punya tiket revisi yang masih bisa dipakai"})
+		return store.OrderItem{}, nil, false
+	}
+	return o, data, true
+}
+
+// RevisionVoucherPDF handles GET /api/admin/orders/{id}/tickets/pdf: lembar tiket revisi order yang masih bisa dipakai,
+// tiap kode dengan QR code ke halaman klaim. Untuk dikirim ke klien lewat email, lihat RevisionVoucherSend.
+func RevisionVoucherPDF(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	o, data, ok := revisionVoucherOrder(w, r)
+	if !ok {
+		return
+	}
+	pdfBytes, err := pdf.GenerateRevisionVoucherPDF(data)
+	if err != nil {
+		log.Printf("[revisi] voucher pdf %s: %v", o.ID, err)
+		http.Error(w, "failed to generate PDF", http.StatusInternalServerError)
+		return
+	}
+	filename := buildAgreementFilename(o.Pemesan, "tiket-revisi-"+o.ID)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdfBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
+
+// RevisionVoucherSend handles POST /api/admin/orders/{id}/tickets/send: mengirim lembar tiket revisi (PDF terlampir)
+// ke email pemesan lewat outbox, misalnya bersama surat perjanjian. Bisa dikirim ulang; tiap kiriman satu pesan outbox.
+func RevisionVoucherSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	o, data, ok := revisionVoucherOrder(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if o.EmailPemesan == "" {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "order ini belum punya email pemesan"})
+		return
+	}
+	if MailOutbox == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "mail outbox tidak tersedia"})
+		return
+	}
+	pdfBytes, err := pdf.GenerateRevisionVoucherPDF(data)
+	if err != nil {
+		log.Printf("[revisi] voucher pdf %s: %v", o.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "failed to generate PDF"})
+		return
+	}
+	msg, err := mail.RevisionVoucher(mail.RevisionVoucherData{
+		Name:      o.Pemesan,
+		Email:     o.EmailPemesan,
+		Layanan:   o.Layanan,
+		Count:     len(data.Vouchers),
+		MasaKlaim: data.MasaKlaim,
+		URL:       data.URL,
+	})
+	if err != nil {
+		log.Printf("[mail] revision voucher %s: %v", o.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "failed to build email"})
+		return
+	}
+	msg.Attachments = append(msg.Attachments, mail.Attachment{
+		Filename:    buildAgreementFilename(o.Pemesan, "tiket-revisi-"+o.ID),
+		ContentType: "application/pdf",
+		Data:        pdfBytes,
+	})
+	if !enqueueMail("revision-voucher:"+o.ID+":"+time.Now().UTC().Format(time.RFC3339Nano), msg) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan email ke outbox"})
+		return
+	}
+	if MailDispatcher != nil {
+		go MailDispatcher.Run(context.Background())
+	}
+	log.Printf("[revisi] voucher sheet %s (%d tiket) emailed to client by %s", o.ID, len(data.Vouchers), orderActor(r))
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "emailed": true, "count": len(data.Vouchers)})
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/mail"
+	"backend/internal/middleware/ratelimit"
+	"backend/internal/notify"
+	"backend/internal/store"
+)
+
+// revisionStatusLabels are the client-facing names of revision statuses.
+var revisionStatusLabels = map[string]string{
+	store.RevisionRequested:  "Permintaan revisi diterima",
+	store.RevisionInProgress: "Sedang direvisi",
+	store.RevisionDelivered:  "Hasil revisi dikirim, menunggu persetujuan Anda",
+	store.RevisionAccepted:   "Revisi diterima",
+}
+
+// revisionHandedOver reports whether an order in status has its result with the client (masa klaim revisi berjalan).
+func revisionHandedOver(status string) bool {
+	return status == store.OrderStatusInReview || status == store.OrderStatusCompleted
+}
+
+// revisionClaimDays returns REVISI_HARI, the days a client has to claim revisions after handover;
+// false bila REVISI_HARI=0 (tiket tidak kedaluwarsa). Dipakai tiket, surat perjanjian, dan lembar tiket revisi.
+func revisionClaimDays() (int, bool) {
+	if OrderCfg == nil || OrderCfg.RevisiHari <= 0 {
+		return 0, false
+	}
+	return OrderCfg.RevisiHari, true
+}
+
+// revisionClaimDaysText returns the claim window for agreement clauses, e.g. "7 (tujuh)"; "" bila tanpa batas waktu.
+func revisionClaimDaysText() string {
+	if days, ok := revisionClaimDays(); ok {
+		return jumlahTerbilang(days)
+	}
+	return ""
+}
+
+// revisionClaimDeadline returns the end (WIB) of the REVISI_HARI-th day after from; false bila tiket tidak kedaluwarsa.
+func revisionClaimDeadline(from time.Time) (time.Time, bool) {
+	days, ok := revisionClaimDays()
+	if !ok {
+		return time.Time{}, false
+	}
+	d := from.In(wib)
+	return time.Date(d.Year(), d.Month(), d.Day()+days+1, 0, 0, 0, 0, wib), true
+}
+
+// openRevisionClaimWindow starts the claim window of an order's unused tickets when its result is handed over
+// (in_review, completed, atau tiket baru untuk order yang sudah diserahkan). Penyerahan berikutnya memperpanjang masa klaim.
+func openRevisionClaimWindow(orderID string) {
+	if RevisionTicketStore == nil {
+		return
+	}
+	if until, ok := revisionClaimDeadline(time.Now()); ok {
+		RevisionTicketStore.OpenClaimWindow(orderID, until)
+	}
+}
+
+// ticketsByID returns the current state of tickets (setelah expiry diisi).
+func ticketsByID(orderID string, tickets []store.RevisionTicket) []store.RevisionTicket {
+	want := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		want[t.ID] = true
+	}
+	out := make([]store.RevisionTicket, 0, len(tickets))
+	for _, t := range RevisionTicketStore.ByOrderID(orderID) {
+		if want[t.ID] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// expiredTicketResponse is the redeem answer for a ticket past its claim window.
+func expiredTicketResponse(t store.RevisionTicket) map[string]interface{} {
+	msg := "Masa klaim tiket revisi ini sudah berakhir."
+	if t.ExpiresAt != nil {
+		msg = "Masa klaim tiket revisi ini berakhir " + mail.DateID(t.ExpiresAt.Add(-time.Second).In(wib)) + "."
+	}
+	return map[string]interface{}{
+		"ok":         false,
+		"expired":    true,
+		"expires_at": t.ExpiresAt,
+		"message":    msg + " Hubungi kami lewat pesan order bila masih membutuhkan revisi.",
+	}
+}
+
+// ticketByCode returns the ticket of the code in the URL, writing 404 when unknown.
+func ticketByCode(w http.ResponseWriter, r *http.Request) (store.RevisionTicket, bool) {
+	if RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return store.RevisionTicket{}, false
+	}
+	t, ok := RevisionTicketStore.GetByCode(chi.URLParam(r, "code"))
+	if !ok {
+		ratelimit.Fail(r)
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Kode tiket revisi tidak ditemukan."})
+		return store.RevisionTicket{}, false
+	}
+	return t, true
+}
+
+// RevisiStatus handles GET /api/revisi/{code} (public): status tiket dan revisinya untuk klien.
+func RevisiStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	t, ok := ticketByCode(w, r)
+	if !ok {
+		return
+	}
+	layanan := ""
+	if OrderStore != nil {
+		if o, found := OrderStore.Get(t.OrderID); found {
+			layanan = o.Layanan
+		}
+	}
+	remaining, now := 0, time.Now()
+	for _, x := range RevisionTicketStore.ByOrderID(t.OrderID) {
+		if x.Status == "unused" && !x.Expired(now) {
+			remaining++
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":                  true,
+		"layanan":             layanan,
+		"revisi_ke":           t.Sequence,
+		"status":              t.Status,
+		"revisi_status":       t.RevisiStatus,
+		"revisi_status_label": revisionStatusLabels[t.RevisiStatus],
+		"deskripsi":           t.Note,
+		"attachments":         t.Attachments,
+		"delivery_note":       t.DeliveryNote,
+		"used_at":             t.UsedAt,
+		"revisi_updated_at":   t.RevisiUpdatedAt,
+		"sisa_revisi":         remaining,
+		"expires_at":          t.ExpiresAt,
+	})
+}
+
+// RevisiAttachment handles GET /api/revisi/{code}/attachments/{id} (public, lampiran tiket ini saja).
+func RevisiAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	t, ok := ticketByCode(w, r)
+	if !ok {
+		return
+	}
+	serveTicketAttachment(w, r, t, chi.URLParam(r, "id"))
+}
+
+func serveTicketAttachment(w http.ResponseWriter, r *http.Request, t store.RevisionTicket, id string) {
+	for _, a := range t.Attachments {
+		if a.ID == id {
+			serveAttachment(w, r, a)
+			return
+		}
+	}
+	http.Error(w, "not found", http.StatusNotFound)
+}
+
+// RevisiAccept handles POST /api/revisi/{code}/accept (public): klien menerima hasil revisi yang sudah dikirim.
+func RevisiAccept(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	t, ok := ticketByCode(w, r)
+	if !ok {
+		return
+	}
+	if t.RevisiStatus != store.RevisionDelivered {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Hasil revisi belum dikirim atau sudah diterima.", "revisi_status": t.RevisiStatus})
+		return
+	}
+	t, ok = RevisionTicketStore.SetRevisionStatus(t.ID, store.RevisionDelivered, store.RevisionAccepted, "")
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Status revisi sudah berubah, muat ulang halaman."})
+		return
+	}
+	if OrderStore != nil {
+		if o, found := OrderStore.Get(t.OrderID); found {
+			notifyAdmin(notify.Message{
+				Key:     "revision-accepted:" + t.ID,
+				Subject: "Revisi ke-" + strconv.Itoa(t.Sequence) + " diterima: " + o.Layanan,
+				Text:    o.Pemesan + " menerima hasil revisi ke-" + strconv.Itoa(t.Sequence) + " untuk " + o.Layanan + ".",
+			})
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "message": "Terima kasih, revisi sudah diterima.", "revisi_status": t.RevisiStatus})
+}
+
+// RevisionStatusRequest is the body for PATCH /api/admin/revisions/{id}.
+type RevisionStatusRequest struct {
+	Status string `json:"status"` // in_progress | delivered | accepted
+	Note   string `json:"note"`   // catatan/link hasil revisi, ditampilkan ke klien saat delivered
+}
+
+// RevisionSetStatus handles PATCH /api/admin/revisions/{id} (id tiket): pindah status revisi sesuai alur.
+// Saat delivered, order yang sedang revision kembali ke in_review.
+func RevisionSetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req RevisionStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t, ok := RevisionTicketStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	status := strings.TrimSpace(req.Status)
+	if !store.CanTransitionRevision(t.RevisiStatus, status) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "status revisi " + t.RevisiStatus + " tidak bisa diubah ke " + status})
+		return
+	}
+	note := ""
+	if status == store.RevisionDelivered {
+		note = strings.TrimSpace(req.Note)
+	}
+	t, ok = RevisionTicketStore.SetRevisionStatus(t.ID, t.RevisiStatus, status, note)
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "revisi sedang diubah, muat ulang lalu coba lagi"})
+		return
+	}
+	if status == store.RevisionDelivered && OrderStore != nil {
+		if o, found := OrderStore.Get(t.OrderID); found && o.Status == store.OrderStatusRevision {
+			if _, ok := OrderStore.Transition(o.ID, o.Status, store.OrderStatusInReview, orderActor(r), "Revisi ke-"+strconv.Itoa(t.Sequence)+" dikirim"); ok {
+				openRevisionClaimWindow(o.ID)
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "ticket": t})
+}
+
+// RevisionExtendRequest is the body for POST /api/admin/revisions/{id}/extend.
+type RevisionExtendRequest struct {
+	Hari int    `json:"hari"` // tambahan hari, dihitung dari batas lama (atau hari ini bila sudah lewat)
+	Note string `json:"note"` // alasan perpanjangan (wajib)
+}
+
+// RevisionExtend handles POST /api/admin/revisions/{id}/extend: perpanjang batas klaim tiket; tiket expired aktif kembali.
+func RevisionExtend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req RevisionExtendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	note := strings.TrimSpace(req.Note)
+	if req.Hari < 1 || req.Hari > 90 || note == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "hari harus 1-90 dan note wajib diisi"})
+		return
+	}
+	if RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t, ok := RevisionTicketStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	if t.Status == "used" {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "tiket sudah dipakai"})
+		return
+	}
+	base := time.Now()
+	if t.ExpiresAt != nil && t.ExpiresAt.After(base) {
+		base = *t.ExpiresAt
+	}
+	until := base.AddDate(0, 0, req.Hari)
+	t, ok = RevisionTicketStore.ExtendExpiry(t.ID, until, note)
+	if !ok {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "tiket sedang diubah, muat ulang lalu coba lagi"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "ticket": t})
+}
+
+// RevisionAttachment handles GET /api/admin/revisions/{id}/attachments/{aid}.
+func RevisionAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if RevisionTicketStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t, ok := RevisionTicketStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	serveTicketAttachment(w, r, t, chi.URLParam(r, "aid"))
+}
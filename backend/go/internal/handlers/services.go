@@ -2,13 +2,23 @@ package handlers
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strings"
 
 	"backend/internal/store"
 )
 
 var ServiceStore *store.ServiceStore
 
+// ServiceWithRating is a service plus its average rating from verified client reviews.
+type ServiceWithRating struct {
+	store.Service
+	RatingAverage float64 `json:"rating_average"` // 0 = belum ada ulasan
+	RatingCount   int     `json:"rating_count"`
+	Penuh         bool    `json:"penuh"` // kapasitas antrian tercapai; closed ikut true selama penuh
+}
+
 // ServicesList handles GET /api/services (public). Returns all services (open + closed); frontend menampilkan closed dengan teks "closed can't order".
 func ServicesList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -16,10 +26,29 @@ func ServicesList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if ServiceStore == nil {
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "services": []store.Service{}})
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "services": []ServiceWithRating{}})
 		return
 	}
-	list := ServiceStore.ListAll()
+	ratings := map[string]store.RatingSummary{}
+	if ReviewStore != nil {
+		ratings = ReviewStore.ServiceRatings()
+	}
+	full := map[string]bool{}
+	_, queues := currentQueue()
+	for _, q := range queues {
+		if q.Penuh && q.ServiceID != "" {
+			full[q.ServiceID] = true
+		}
+	}
+	all := ServiceStore.ListAll()
+	list := make([]ServiceWithRating, 0, len(all))
+	for _, svc := range all {
+		rt := ratings[svc.ID]
+		if full[svc.ID] {
+			svc.Closed = true // tutup otomatis sampai ada order yang selesai
+		}
+		list = append(list, ServiceWithRating{Service: svc, RatingAverage: math.Round(rt.Average*10) / 10, RatingCount: rt.Count, Penuh: full[svc.ID]})
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "services": list})
@@ -79,6 +108,44 @@ func ServicesSetClosed(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ServiceTierRequest for POST /api/admin/services/tier.
+type ServiceTierRequest struct {
+	ID   string `json:"id"`
+	Tier string `json:"tier"` // standar | profesional
+}
+
+// ServicesSetTier handles POST /api/admin/services/tier (tier perjanjian layanan, menentukan jatah revisi order baru).
+func ServicesSetTier(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ServiceTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	tier := strings.ToLower(strings.TrimSpace(req.Tier))
+	if strings.TrimSpace(req.ID) == "" || (tier != store.ServiceTierStandar && tier != store.ServiceTierProfesional) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "id required; tier harus standar atau profesional"})
+		return
+	}
+	if ServiceStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	svc, ok := ServiceStore.SetTier(strings.TrimSpace(req.ID), tier)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "service": svc})
+}
+
 // ServiceAddRequest for POST /api/admin/services.
 type ServiceAddRequest struct {
 	Title     string `json:"title"`
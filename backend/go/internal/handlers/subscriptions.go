@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/mail"
+	"backend/internal/payment"
+	"backend/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SubscriptionStore and SubscriptionBiller are set from main (biller nil = payment gateway belum dikonfigurasi).
+var SubscriptionStore *store.SubscriptionStore
+var SubscriptionBiller *jobs.SubscriptionBiller
+
+// subscriptionCancelPurpose is the signedToken purpose for self-service cancel links.
+const subscriptionCancelPurpose = "subscription-cancel"
+
+// siteBaseURL returns SITE_URL (frontend) for links in emails.
+func siteBaseURL() string {
+	if DonateCfg != nil && DonateCfg.SiteURL != "" {
+		return DonateCfg.SiteURL
+	}
+	return "http://localhost:3000"
+}
+
+// subscriptionCancelURL returns the frontend cancel page link sent in every payment email.
+func subscriptionCancelURL(id string) string {
+	q := url.Values{"id": {id}, "token": {signedToken(subscriptionCancelPurpose, id)}}
+	return siteBaseURL() + "/support/langganan/batal?" + q.Encode()
+}
+
+// NotifySubscriptionPeriod queues the payment link email for a newly billed period (Notify pada SubscriptionBiller).
+func NotifySubscriptionPeriod(sub store.Subscription, tx payment.Transaction, pastDue bool) {
+	if sub.Email == "" || tx.RedirectURL == "" {
+		return
+	}
+	period := time.Now()
+	if sub.PeriodStartedAt != nil {
+		period = *sub.PeriodStartedAt
+	}
+	msg, err := mail.SubscriptionPaymentLink(mail.SubscriptionData{
+		Name:      sub.Name,
+		Email:     sub.Email,
+		PlanName:  sub.PlanName,
+		Amount:    sub.Amount,
+		Period:    period.In(wib),
+		PayURL:    tx.RedirectURL,
+		CancelURL: subscriptionCancelURL(sub.ID),
+		PastDue:   pastDue,
+	})
+	if err != nil {
+		log.Printf("[mail] subscription link %s: %v", sub.ID, err)
+		return
+	}
+	if enqueueMail("subscription-link:"+tx.OrderID, msg) && MailDispatcher != nil {
+		go MailDispatcher.Run(context.Background())
+	}
+}
+
+// markSubscriptionPeriodPaid records payment of a subscription period donation.
+func markSubscriptionPeriodPaid(d store.Donation) {
+	if d.SubscriptionID == "" || SubscriptionStore == nil {
+		return
+	}
+	if !SubscriptionStore.MarkPaid(d.SubscriptionID, d.OrderID, time.Now().UTC()) {
+		log.Printf("[subscription] mark paid %s (%s): subscription not found", d.SubscriptionID, d.OrderID)
+	}
+}
+
+// SubscriptionPlansList handles GET /api/subscriptions/plans (public: paket aktif saja).
+func SubscriptionPlansList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	plans := []store.SubscriptionPlan{}
+	if SubscriptionStore != nil {
+		plans = append(plans, SubscriptionStore.ListPlans(true)...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "plans": plans})
+}
+
+// SubscriptionCreateRequest is the body for POST /api/subscriptions.
+type SubscriptionCreateRequest struct {
+	PlanID         string `json:"plan_id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"` // wajib: link bayar tiap bulan dikirim ke sini
+	Anonymous      bool   `json:"anonymous"`
+	ConsentPublish bool   `json:"consent_publish"`
+}
+
+// SubscriptionCreate handles POST /api/subscriptions: daftar langganan bulanan dan langsung menagih periode pertama.
+// Response berisi token/URL bayar seperti create-transaction donasi, plus cancel_token untuk batal mandiri.
+func SubscriptionCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SubscriptionCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	req.Email = strings.TrimSpace(req.Email)
+	if !strings.Contains(req.Email, "@") {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "email valid wajib diisi"})
+		return
+	}
+	if SubscriptionStore == nil || SubscriptionBiller == nil {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "Langganan belum tersedia: payment gateway belum dikonfigurasi."})
+		return
+	}
+	plan, ok := SubscriptionStore.GetPlan(strings.TrimSpace(req.PlanID))
+	if !ok || !plan.Active {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "paket tidak ditemukan"})
+		return
+	}
+	sub := SubscriptionStore.Add(store.Subscription{
+		PlanID:         plan.ID,
+		PlanName:       plan.Name,
+		Amount:         plan.Amount,
+		Name:           strings.TrimSpace(req.Name),
+		Email:          req.Email,
+		Anonymous:      req.Anonymous,
+		ConsentPublish: req.ConsentPublish,
+	})
+	if sub.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan langganan"})
+		return
+	}
+	tx, err := SubscriptionBiller.ChargePeriod(r.Context(), sub)
+	if err != nil {
+		log.Printf("[subscription] first period %s: %v", sub.ID, err)
+		SubscriptionStore.Cancel(sub.ID)
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "payment gateway tidak mengembalikan token"})
+		return
+	}
+	if updated, ok := SubscriptionStore.Get(sub.ID); ok {
+		sub = updated
+	}
+	out := map[string]interface{}{
+		"ok":           true,
+		"subscription": sub,
+		"gateway":      PaymentGateway.Name(),
+		"redirect_url": tx.RedirectURL,
+		"order_id":     tx.OrderID,
+		"client_key":   tx.ClientKey,
+		"cancel_token": signedToken(subscriptionCancelPurpose, sub.ID),
+	}
+	if PaymentGateway.Name() == "midtrans" {
+		out["snap_token"] = tx.Token
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// SubscriptionGet handles GET /api/subscriptions/{id}?token=xxx (halaman batal langganan menampilkan status).
+func SubscriptionGet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+	if !verifySignedToken(subscriptionCancelPurpose, id, r.URL.Query().Get("token")) {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "link tidak valid"})
+		return
+	}
+	if SubscriptionStore == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	sub, ok := SubscriptionStore.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "subscription": sub})
+}
+
+// SubscriptionCancelRequest is the body for POST /api/subscriptions/{id}/cancel.
+type SubscriptionCancelRequest struct {
+	Token string `json:"token"` // dari link di email atau cancel_token saat daftar
+}
+
+// SubscriptionCancel handles POST /api/subscriptions/{id}/cancel (batal mandiri, idempotent).
+// Sengaja POST, bukan GET dari link email langsung, agar pemindai link di email tidak membatalkan langganan.
+func SubscriptionCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SubscriptionCancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+	if !verifySignedToken(subscriptionCancelPurpose, id, req.Token) {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "link tidak valid"})
+		return
+	}
+	cancelSubscription(w, id)
+}
+
+func cancelSubscription(w http.ResponseWriter, id string) {
+	if SubscriptionStore == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	sub, ok := SubscriptionStore.Cancel(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "subscription": sub})
+}
+
+// SubscriptionPlansListAdmin handles GET /api/admin/subscriptions/plans (termasuk paket nonaktif).
+func SubscriptionPlansListAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	plans := []store.SubscriptionPlan{}
+	if SubscriptionStore != nil {
+		plans = append(plans, SubscriptionStore.ListPlans(false)...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "plans": plans})
+}
+
+// SubscriptionPlanRequest is the body for creating or editing a plan.
+type SubscriptionPlanRequest struct {
+	Name        string `json:"name"`
+	Amount      int    `json:"amount"` // IDR per bulan, minimal 1000
+	Description string `json:"description"`
+	Active      *bool  `json:"active"` // default true
+}
+
+func decodeSubscriptionPlan(w http.ResponseWriter, r *http.Request) (store.SubscriptionPlan, bool) {
+	var req SubscriptionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"ok":false,"message":"invalid JSON"}`, http.StatusBadRequest)
+		return store.SubscriptionPlan{}, false
+	}
+	p := store.SubscriptionPlan{Name: strings.TrimSpace(req.Name), Amount: req.Amount, Description: strings.TrimSpace(req.Description), Active: true}
+	if req.Active != nil {
+		p.Active = *req.Active
+	}
+	msg := ""
+	switch {
+	case p.Name == "":
+		msg = "name required"
+	case p.Amount < 1000:
+		msg = "amount minimal 1000"
+	}
+	if msg != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": msg})
+		return store.SubscriptionPlan{}, false
+	}
+	return p, true
+}
+
+// SubscriptionPlanAdd handles POST /api/admin/subscriptions/plans.
+func SubscriptionPlanAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p, ok := decodeSubscriptionPlan(w, r)
+	if !ok {
+		return
+	}
+	if SubscriptionStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	p = SubscriptionStore.AddPlan(p)
+	w.Header().Set("Content-Type", "application/json")
+	if p.ID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "gagal menyimpan paket"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "plan": p})
+}
+
+// SubscriptionPlanUpdate handles PUT /api/admin/subscriptions/plans/{id}. Nominal baru hanya berlaku untuk pendaftar baru.
+func SubscriptionPlanUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p, ok := decodeSubscriptionPlan(w, r)
+	if !ok {
+		return
+	}
+	if SubscriptionStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	p.ID = chi.URLParam(r, "id")
+	p, ok = SubscriptionStore.UpdatePlan(p)
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "plan": p})
+}
+
+// SubscriptionsListAdmin handles GET /api/admin/subscriptions?status=active|past_due|cancelled.
+func SubscriptionsListAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list := []store.Subscription{}
+	if SubscriptionStore != nil {
+		list = append(list, SubscriptionStore.List(r.URL.Query().Get("status"))...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "subscriptions": list})
+}
+
+// SubscriptionCancelAdmin handles POST /api/admin/subscriptions/{id}/cancel.
+func SubscriptionCancelAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	cancelSubscription(w, chi.URLParam(r, "id"))
+}
+
+// SubscriptionsRun handles POST /api/admin/subscriptions/run (tagih semua langganan yang jatuh tempo sekarang).
+func SubscriptionsRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if SubscriptionBiller == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "payment gateway belum dikonfigurasi"})
+		return
+	}
+	res := SubscriptionBiller.Run(r.Context())
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": res})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 
 	"backend/internal/config"
+	"backend/internal/middleware/ratelimit"
 	"backend/internal/store"
 )
 
@@ -63,6 +64,9 @@ func TaperVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.OTP = strings.TrimSpace(req.OTP)
+	if !ratelimit.CheckCode(w, r, req.OTP) {
+		return
+	}
 	if TaperStore == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -73,6 +77,7 @@ func TaperVerify(w http.ResponseWriter, r *http.Request) {
 	_ = label
 	if !ok {
 		log.Printf("[taper] verify failed: OTP len=%d (invalid or expired)", len(req.OTP))
+		ratelimit.Fail(r)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		_ = json.NewEncoder(w).Encode(TaperVerifyResponse{OK: false, Msg: "OTP tidak valid atau sudah kedaluwarsa"})
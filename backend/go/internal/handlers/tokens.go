@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+)
+
+// tokenSecret returns JWT_SECRET untuk token bertanda tangan; "" bila belum diset (link bertoken dinonaktifkan,
+// bukan ditandatangani dengan kunci yang tertulis di repo).
+func tokenSecret() string {
+	if AuthCfg != nil && AuthCfg.JWTSecret != "" {
+		return AuthCfg.JWTSecret
+	}
+	if DonateCfg != nil && DonateCfg.JWTSecret != "" {
+		return DonateCfg.JWTSecret
+	}
+	return ""
+}
+
+// signedToken returns an HMAC token binding value to purpose (mis. "donation-proof" + donation ID), tanpa perlu disimpan.
+// Returns "" when JWT_SECRET is not configured.
+func signedToken(purpose, value string) string {
+	secret := tokenSecret()
+	if secret == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(hmacSHA256(purpose+":"+value, secret))
+}
+
+// verifySignedToken checks a token created by signedToken. Selalu false bila JWT_SECRET belum diset.
+func verifySignedToken(purpose, value, token string) bool {
+	secret := tokenSecret()
+	if secret == "" {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, hmacSHA256(purpose+":"+value, secret))
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/payment"
+	"backend/internal/store"
+)
+
+// WebhookEventStore is the webhook inbox (injected in main).
+var WebhookEventStore *store.WebhookEventStore
+
+// redactedWebhookHeaders are hidden in admin responses (tetap tersimpan untuk replay).
+var redactedWebhookHeaders = []string{"Authorization", "X-Callback-Token"}
+
+// processWebhookEvent verifies and applies a stored event, records the outcome and returns the resulting status,
+// the outcome, and whether the notification was decoded and verified. force=true (replay) skips the idempotency check.
+func processWebhookEvent(ev store.WebhookEvent, force bool) (status, outcome string, verified bool) {
+	if PaymentGateway == nil {
+		return store.WebhookStatusFailed, "payment gateway not configured", false
+	}
+	status = store.WebhookStatusProcessed
+	orderID, key := "", ""
+	parsed, err := PaymentGateway.ParseNotification([]byte(ev.Payload), http.Header(ev.Headers))
+	switch {
+	case ev.Gateway != "" && ev.Gateway != PaymentGateway.Name():
+		status, outcome = store.WebhookStatusFailed, "gateway "+ev.Gateway+" is not active"
+	case errors.Is(err, payment.ErrInvalidSignature):
+		status, outcome = store.WebhookStatusRejected, err.Error()
+		log.Printf("[webhook] %s rejected: invalid signature", ev.ID)
+	case err != nil:
+		status, outcome = store.WebhookStatusFailed, err.Error()
+	default:
+		verified, orderID = true, parsed.OrderID
+		key = PaymentGateway.Name() + ":" + parsed.OrderID + ":" + parsed.Status
+		// Kunci diklaim atomik sebelum handler jalan: pengiriman ganda yang bersamaan hanya diproses sekali.
+		claimed := WebhookEventStore == nil || WebhookEventStore.Claim(ev.ID, key)
+		if !claimed && !force {
+			status, outcome = store.WebhookStatusDuplicate, "already processed"
+		} else {
+			if strings.HasPrefix(parsed.OrderID, revisionPurchasePrefix) {
+				outcome = applyRevisionPurchaseEvent(parsed)
+			} else {
+				outcome = applyDonationEvent(parsed)
+			}
+			if strings.HasPrefix(outcome, "error:") {
+				status = store.WebhookStatusFailed
+			}
+		}
+		if status == store.WebhookStatusFailed && claimed && WebhookEventStore != nil {
+			WebhookEventStore.Release(ev.ID)
+		}
+	}
+	if WebhookEventStore != nil && ev.ID != "" {
+		WebhookEventStore.SetResult(ev.ID, orderID, key, verified, status, outcome)
+	}
+	return status, outcome, verified
+}
+
+func redactWebhookEvent(ev store.WebhookEvent) store.WebhookEvent {
+	hdr := make(map[string][]string, len(ev.Headers))
+	for k, v := range ev.Headers {
+		hdr[k] = v
+	}
+	for _, k := range redactedWebhookHeaders {
+		if _, ok := http.Header(hdr)[http.CanonicalHeaderKey(k)]; ok {
+			hdr[http.CanonicalHeaderKey(k)] = []string{"[redacted]"}
+		}
+	}
+	ev.Headers = hdr
+	return ev
+}
+
+// WebhookEventsList handles GET /api/admin/webhooks?status=&order_id=&limit= (newest first).
+func WebhookEventsList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if WebhookEventStore == nil {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "events": []store.WebhookEvent{}})
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list := WebhookEventStore.List(strings.TrimSpace(q.Get("status")), strings.TrimSpace(q.Get("order_id")), limit)
+	out := make([]store.WebhookEvent, 0, len(list))
+	for _, ev := range list {
+		out = append(out, redactWebhookEvent(ev))
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "events": out})
+}
+
+// WebhookEventGet handles GET /api/admin/webhooks/{id} (payload + headers lengkap).
+func WebhookEventGet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if WebhookEventStore == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ev, ok := WebhookEventStore.Get(chi.URLParam(r, "id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "event": redactWebhookEvent(ev)})
+}
+
+// WebhookEventReplay handles POST /api/admin/webhooks/{id}/replay (jalankan ulang handler dengan payload tersimpan).
+func WebhookEventReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if WebhookEventStore == nil || PaymentGateway == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "service unavailable"})
+		return
+	}
+	id := chi.URLParam(r, "id")
+	ev, ok := WebhookEventStore.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "message": "not found"})
+		return
+	}
+	status, _, _ := processWebhookEvent(ev, true)
+	ev, _ = WebhookEventStore.Get(id)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":     status == store.WebhookStatusProcessed,
+		"status": status,
+		"event":  redactWebhookEvent(ev),
+	})
+}
@@ -0,0 +1,92 @@
+// Package ics writes iCalendar (RFC 5545) feeds.
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one all-day VEVENT.
+type Event struct {
+	UID         string // harus stabil antar refresh agar kalender memperbarui, bukan menduplikasi
+	Date        time.Time
+	Summary     string
+	Description string
+	Categories  string
+}
+
+// Calendar is a feed with a display name.
+type Calendar struct {
+	Name    string
+	Refresh time.Duration // saran interval refresh untuk aplikasi kalender
+	Events  []Event
+}
+
+// Write writes cal as an iCalendar document stamped with now.
+func Write(w io.Writer, cal Calendar, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	stamp := now.UTC().Format("20060102T150405Z")
+	line := func(s string) { writeFolded(bw, s) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Raspro//Orders//ID")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escape(cal.Name))
+	if cal.Refresh > 0 {
+		d := duration(cal.Refresh)
+		line("REFRESH-INTERVAL;VALUE=DURATION:" + d)
+		line("X-PUBLISHED-TTL:" + d)
+	}
+	for _, ev := range cal.Events {
+		line("BEGIN:VEVENT")
+		line("UID:" + ev.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + ev.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + ev.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escape(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION:" + escape(ev.Description))
+		}
+		if ev.Categories != "" {
+			line("CATEGORIES:" + escape(ev.Categories))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// writeFolded writes s with CRLF, folding lines longer than 75 octets without splitting UTF-8 characters.
+func writeFolded(w *bufio.Writer, s string) {
+	const max = 75
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > max {
+			w.WriteString("\r\n ")
+			n = 1
+		}
+		w.WriteRune(r)
+		n += size
+	}
+	w.WriteString("\r\n")
+}
+
+// duration formats d as an iCalendar DURATION (PT1H, PT30M).
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return "PT" + strconv.Itoa(int(d/time.Hour)) + "H"
+	}
+	return "PT" + strconv.Itoa(int(d/time.Minute)) + "M"
+}
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/notify"
+	"backend/internal/store"
+)
+
+// OrderAttention is an unfinished order whose deadline is near or past.
+type OrderAttention struct {
+	ID       string     `json:"id"`
+	Layanan  string     `json:"layanan"`
+	Pemesan  string     `json:"pemesan"`
+	Status   string     `json:"status"`
+	Deadline store.Date `json:"deadline"`
+	DaysLeft int        `json:"days_left"` // negatif = terlambat
+}
+
+// AttentionReport lists orders needing attention, most urgent first.
+type AttentionReport struct {
+	Overdue  []OrderAttention `json:"overdue"`
+	DueSoon  []OrderAttention `json:"due_soon"`
+	WarnDays int              `json:"warn_days"`
+	Today    store.Date       `json:"today"` // tanggal WIB
+}
+
+// Empty reports whether no order needs attention.
+func (r AttentionReport) Empty() bool {
+	return len(r.Overdue) == 0 && len(r.DueSoon) == 0
+}
+
+// BuildAttention returns unfinished orders that are overdue or due within warnDays days of now (WIB).
+func BuildAttention(orders []store.OrderItem, now time.Time, warnDays int) AttentionReport {
+	today := store.NewDate(now.In(wib))
+	rep := AttentionReport{Overdue: []OrderAttention{}, DueSoon: []OrderAttention{}, WarnDays: warnDays, Today: today}
+	for _, o := range orders {
+		if o.Deadline == nil || o.Status == store.OrderStatusCompleted || o.Status == store.OrderStatusCancelled {
+			continue
+		}
+		days := int(o.Deadline.Sub(today.Time).Hours() / 24)
+		a := OrderAttention{ID: o.ID, Layanan: o.Layanan, Pemesan: o.Pemesan, Status: o.Status, Deadline: *o.Deadline, DaysLeft: days}
+		switch {
+		case days < 0:
+			rep.Overdue = append(rep.Overdue, a)
+		case days <= warnDays:
+			rep.DueSoon = append(rep.DueSoon, a)
+		}
+	}
+	for _, list := range [][]OrderAttention{rep.Overdue, rep.DueSoon} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].DaysLeft < list[j].DaysLeft })
+	}
+	return rep
+}
+
+// DeadlineWatcher sends a daily digest of overdue and approaching order deadlines through Notifier.
+type DeadlineWatcher struct {
+	Orders   *store.OrderStore
+	Notifier notify.Notifier
+	WarnDays int
+	Hour     int // jam kirim digest (WIB); -1 = tidak dikirim otomatis
+
+	mu         sync.Mutex
+	lastDigest string // tanggal digest terakhir (YYYY-MM-DD), agar sekali sehari
+}
+
+// Report returns the current attention report.
+func (d *DeadlineWatcher) Report(now time.Time) AttentionReport {
+	if d.Orders == nil {
+		return BuildAttention(nil, now, d.WarnDays)
+	}
+	return BuildAttention(d.Orders.List(), now, d.WarnDays)
+}
+
+// Tick sends today's digest once when the configured hour is reached (dipanggil scheduler berkala).
+// Digest hanya dikirim bila ada order yang perlu perhatian.
+func (d *DeadlineWatcher) Tick(ctx context.Context) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	today := store.NewDate(now.In(wib)).String()
+	if d.Hour < 0 || now.In(wib).Hour() != d.Hour || d.lastDigest == today {
+		return
+	}
+	rep := d.Report(now)
+	if !rep.Empty() {
+		if err := d.send(ctx, rep, "deadline-digest:"+today); err != nil {
+			log.Printf("[deadline] digest via %s: %v", d.Notifier.Name(), err)
+			return // dicoba lagi pada tick berikutnya dalam jam yang sama
+		}
+	}
+	d.lastDigest = today
+}
+
+// SendNow sends the digest immediately regardless of hour (tombol admin). Returns false if nothing needs attention.
+func (d *DeadlineWatcher) SendNow(ctx context.Context) (AttentionReport, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	rep := d.Report(now)
+	if rep.Empty() {
+		return rep, false, nil
+	}
+	err := d.send(ctx, rep, fmt.Sprintf("deadline-digest:manual:%d", now.UnixNano()))
+	return rep, err == nil, err
+}
+
+func (d *DeadlineWatcher) send(ctx context.Context, rep AttentionReport, key string) error {
+	if d.Notifier == nil {
+		return fmt.Errorf("no notifier")
+	}
+	subject := fmt.Sprintf("Deadline order: %d terlambat, %d mendekat", len(rep.Overdue), len(rep.DueSoon))
+	return d.Notifier.Notify(ctx, notify.Message{Key: key, Subject: subject, Text: FormatAttention(rep)})
+}
+
+// FormatAttention renders rep as plain text for the digest.
+func FormatAttention(rep AttentionReport) string {
+	var b strings.Builder
+	line := func(a OrderAttention, when string) {
+		who := ""
+		if a.Pemesan != "" {
+			who = " - " + a.Pemesan
+		}
+		fmt.Fprintf(&b, "- %s%s [%s], deadline %s, %s\n", a.Layanan, who, a.Status, a.Deadline.Format("02 Jan 2006"), when)
+	}
+	if len(rep.Overdue) > 0 {
+		fmt.Fprintf(&b, "Lewat deadline (%d):\n", len(rep.Overdue))
+		for _, a := range rep.Overdue {
+			line(a, fmt.Sprintf("terlambat %d hari", -a.DaysLeft))
+		}
+	}
+	if len(rep.DueSoon) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Deadline dalam %d hari (%d):\n", rep.WarnDays, len(rep.DueSoon))
+		for _, a := range rep.DueSoon {
+			when := fmt.Sprintf("sisa %d hari", a.DaysLeft)
+			if a.DaysLeft == 0 {
+				when = "hari ini"
+			}
+			line(a, when)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// wib is the timezone (UTC+7) schedules and billing dates are anchored in.
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// Every runs fn once per interval until ctx is cancelled. interval <= 0 disables the job.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context)) {
+	if interval <= 0 {
+		log.Printf("[jobs] %s disabled", name)
+		return
+	}
+	log.Printf("[jobs] %s scheduled every %s", name, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/mail"
+	"backend/internal/store"
+)
+
+// mailMaxAttempts is how many times an outbox message is tried before it is marked failed.
+const mailMaxAttempts = 8
+
+// MailDispatcher sends due outbox messages through Mailer, with exponential backoff on failure.
+type MailDispatcher struct {
+	Outbox *store.MailOutboxStore
+	Mailer mail.Mailer
+
+	mu sync.Mutex // satu run dalam satu waktu (scheduler vs kirim langsung setelah enqueue)
+}
+
+// Run sends every due message once and returns how many were sent.
+func (d *MailDispatcher) Run(ctx context.Context) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Outbox == nil || d.Mailer == nil {
+		return 0
+	}
+	sent := 0
+	for _, m := range d.Outbox.Due(time.Now().UTC(), 50) {
+		if ctx.Err() != nil {
+			break
+		}
+		msg := mail.Message{To: []string{m.To}, Subject: m.Subject, Text: m.Text, HTML: m.HTML}
+		for _, a := range m.Attachments {
+			msg.Attachments = append(msg.Attachments, mail.Attachment{Filename: a.Filename, ContentType: a.ContentType, Data: a.Data})
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		err := d.Mailer.Send(sendCtx, msg)
+		cancel()
+		if err == nil {
+			d.Outbox.MarkSent(m.ID)
+			sent++
+			continue
+		}
+		var next *time.Time
+		if m.Attempts+1 < mailMaxAttempts {
+			t := time.Now().UTC().Add(mailBackoff(m.Attempts + 1))
+			next = &t
+		}
+		log.Printf("[mail] %s to %s attempt %d: %v", m.Key, m.To, m.Attempts+1, err)
+		d.Outbox.MarkFailed(m.ID, err.Error(), next)
+	}
+	return sent
+}
+
+// mailBackoff returns the wait after the n-th failed attempt: 1m, 2m, 4m, ... maks 6 jam.
+func mailBackoff(n int) time.Duration {
+	wait := time.Minute << (n - 1)
+	if wait > 6*time.Hour || wait <= 0 {
+		wait = 6 * time.Hour
+	}
+	return wait
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/payment"
+	"backend/internal/store"
+)
+
+// snapTokenLifetime is how long an unpaid transaction may stay unknown to the gateway before we mark it expired.
+const snapTokenLifetime = 24 * time.Hour
+
+// DonationReconciler asks the payment gateway's status API about every non-final donation and
+// updates the stored status (cadangan bila webhook hilang).
+type DonationReconciler struct {
+	Store   *store.Store
+	Gateway payment.Gateway
+	OnPaid  func(d store.Donation) // opsional, dipanggil saat donasi berubah jadi lunas (kuitansi email)
+
+	mu sync.Mutex // satu run dalam satu waktu (scheduler vs tombol admin)
+}
+
+// ReconcileChange is one status change applied by a run.
+type ReconcileChange struct {
+	OrderID string `json:"order_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// ReconcileResult summarizes one reconcile run.
+type ReconcileResult struct {
+	Checked int               `json:"checked"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Changes []ReconcileChange `json:"changes"`
+}
+
+// Run checks all unsettled donations once.
+func (r *DonationReconciler) Run(ctx context.Context) ReconcileResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := ReconcileResult{Changes: []ReconcileChange{}}
+	if r.Store == nil || r.Gateway == nil {
+		return res
+	}
+	for _, d := range r.Store.ListUnsettled() {
+		if ctx.Err() != nil {
+			break
+		}
+		res.Checked++
+		ev, err := r.Gateway.Status(ctx, d.OrderID)
+		next := ""
+		switch {
+		case errors.Is(err, payment.ErrNotFound):
+			if time.Since(d.CreatedAt) > snapTokenLifetime {
+				next = store.DonationStatusExpire
+			}
+		case err != nil:
+			log.Printf("[reconcile] %s: %v", d.OrderID, err)
+			res.Failed++
+			continue
+		default:
+			next = ev.Status
+		}
+		if next == "" || next == d.Status {
+			continue
+		}
+		if !r.Store.UpdateStatus(d.OrderID, next) {
+			res.Failed++
+			continue
+		}
+		res.Updated++
+		if r.OnPaid != nil && payment.IsPaid(next) {
+			if updated, ok := r.Store.FindByOrderID(d.OrderID); ok {
+				r.OnPaid(updated)
+			}
+		}
+		res.Changes = append(res.Changes, ReconcileChange{OrderID: d.OrderID, From: d.Status, To: next})
+	}
+	if res.Checked > 0 {
+		log.Printf("[reconcile] checked=%d updated=%d failed=%d", res.Checked, res.Updated, res.Failed)
+	}
+	return res
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/internal/store"
+)
+
+// RevisionExpirer marks revision tickets whose claim window has passed as expired.
+// Redeem sudah menolak tiket lewat batas; job ini membuat statusnya terlihat di admin dan portal klien.
+type RevisionExpirer struct {
+	Tickets *store.RevisionTicketStore
+}
+
+// Run expires all due tickets once and returns how many were marked.
+func (e *RevisionExpirer) Run(ctx context.Context) int {
+	if e.Tickets == nil || ctx.Err() != nil {
+		return 0
+	}
+	expired := e.Tickets.ExpireDue(time.Now())
+	for _, t := range expired {
+		log.Printf("[revisi] ticket %s (order %s, revisi ke-%d) expired", t.Code, t.OrderID, t.Sequence)
+	}
+	return len(expired)
+}
@@ -0,0 +1,173 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/payment"
+	"backend/internal/store"
+)
+
+// subscriptionMaxMissed is how many consecutive unpaid periods cancel a subscription.
+const subscriptionMaxMissed = 3
+
+// SubscriptionBiller creates each due subscription period as a pending donation with its own gateway transaction.
+// Snap dan Invoice tidak menyimpan kartu (tidak ada charge berulang otomatis), jadi setiap periode
+// pendukung menerima link bayar lewat Notify.
+type SubscriptionBiller struct {
+	Subscriptions *store.SubscriptionStore
+	Donations     *store.Store
+	Gateway       payment.Gateway
+	Highlight     int // batas IDR donasi highlight (DONATE_HIGHLIGHT_IDR)
+	// Notify sends the period's payment link to the supporter.
+	Notify func(sub store.Subscription, tx payment.Transaction, pastDue bool)
+
+	mu sync.Mutex // satu run dalam satu waktu (scheduler vs tombol admin vs pendaftaran baru)
+}
+
+// BillingResult summarizes one billing run.
+type BillingResult struct {
+	Checked   int `json:"checked"`
+	Charged   int `json:"charged"`
+	Cancelled int `json:"cancelled"` // dibatalkan karena terlalu banyak periode tidak dibayar
+	Failed    int `json:"failed"`
+}
+
+// ErrSubscriptionCancelled is returned by ChargePeriod for a cancelled subscription.
+var ErrSubscriptionCancelled = errors.New("subscription cancelled")
+
+// Run bills every subscription whose next charge is due.
+func (b *SubscriptionBiller) Run(ctx context.Context) BillingResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var res BillingResult
+	if b.Subscriptions == nil || b.Donations == nil || b.Gateway == nil {
+		return res
+	}
+	now := time.Now().UTC()
+	for _, sub := range b.Subscriptions.ListDue(now) {
+		if ctx.Err() != nil {
+			break
+		}
+		res.Checked++
+		_, err := b.charge(ctx, sub, now)
+		switch {
+		case errors.Is(err, ErrSubscriptionCancelled):
+			res.Cancelled++
+		case err != nil:
+			log.Printf("[subscription] %s: %v", sub.ID, err)
+			res.Failed++
+		default:
+			res.Charged++
+		}
+	}
+	if res.Checked > 0 {
+		log.Printf("[subscription] checked=%d charged=%d cancelled=%d failed=%d", res.Checked, res.Charged, res.Cancelled, res.Failed)
+	}
+	return res
+}
+
+// ChargePeriod bills sub's current period immediately (dipakai saat pendaftaran untuk periode pertama).
+func (b *SubscriptionBiller) ChargePeriod(ctx context.Context, sub store.Subscription) (payment.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.Subscriptions == nil || b.Donations == nil || b.Gateway == nil {
+		return payment.Transaction{}, payment.ErrNotConfigured
+	}
+	return b.charge(ctx, sub, time.Now().UTC())
+}
+
+func (b *SubscriptionBiller) charge(ctx context.Context, sub store.Subscription, now time.Time) (payment.Transaction, error) {
+	if sub.Status == store.SubscriptionStatusCancelled {
+		return payment.Transaction{}, ErrSubscriptionCancelled
+	}
+	status, missed := store.SubscriptionStatusActive, 0
+	if sub.PeriodOrderID != "" && !b.periodPaid(sub) {
+		status, missed = store.SubscriptionStatusPastDue, sub.MissedPeriods+1
+		if missed >= subscriptionMaxMissed {
+			b.Subscriptions.Cancel(sub.ID)
+			log.Printf("[subscription] %s cancelled after %d unpaid periods", sub.ID, missed)
+			return payment.Transaction{}, ErrSubscriptionCancelled
+		}
+	}
+
+	orderID := fmt.Sprintf("sub-%s-%s-%s", sub.ID, now.In(wib).Format("200601"), randomSuffix())
+	tx, err := b.Gateway.CreateTransaction(ctx, payment.CreateRequest{
+		OrderID:     orderID,
+		Amount:      sub.Amount,
+		Description: "Dukungan bulanan " + sub.PlanName,
+		Customer:    payment.Customer{Name: sub.Name, Email: sub.Email},
+		Metadata:    map[string]string{"name": sub.Name, "email": sub.Email, "comment": ""},
+	})
+	if err != nil {
+		return payment.Transaction{}, err
+	}
+	d := b.Donations.Add(store.Donation{
+		OrderID:        orderID,
+		Amount:         sub.Amount,
+		Name:           sub.Name,
+		Email:          sub.Email,
+		Highlighted:    b.Highlight > 0 && sub.Amount >= b.Highlight,
+		Status:         store.DonationStatusPending,
+		SubscriptionID: sub.ID,
+		Anonymous:      sub.Anonymous,
+		ConsentPublish: sub.ConsentPublish,
+	})
+	if d.ID == "" {
+		return payment.Transaction{}, errors.New("insert donation failed")
+	}
+	next := nextChargeAt(sub.AnchorDay, sub.NextChargeAt, now)
+	if !b.Subscriptions.StartPeriod(sub.ID, orderID, now, next, status, missed) {
+		return payment.Transaction{}, errors.New("start period failed")
+	}
+	if updated, ok := b.Subscriptions.Get(sub.ID); ok {
+		sub = updated
+	}
+	if b.Notify != nil {
+		b.Notify(sub, tx, status == store.SubscriptionStatusPastDue)
+	}
+	return tx, nil
+}
+
+// periodPaid reports whether the current period's donation has been paid (flag dari hook lunas,
+// dengan cek ulang ke donasinya bila hook terlewat).
+func (b *SubscriptionBiller) periodPaid(sub store.Subscription) bool {
+	if sub.PeriodPaid {
+		return true
+	}
+	d, ok := b.Donations.FindByOrderID(sub.PeriodOrderID)
+	return ok && d.IsPaid()
+}
+
+// nextChargeAt returns the first billing date after now that falls on anchorDay of a month following prev
+// (tanggal 31 menjadi hari terakhir di bulan yang lebih pendek).
+func nextChargeAt(anchorDay int, prev, now time.Time) time.Time {
+	p := prev.In(wib)
+	year, month := p.Year(), p.Month()
+	for {
+		month++
+		if month > time.December {
+			month, year = time.January, year+1
+		}
+		day := anchorDay
+		if last := time.Date(year, month+1, 0, 0, 0, 0, 0, wib).Day(); day > last {
+			day = last
+		}
+		next := time.Date(year, month, day, 8, 0, 0, 0, wib).UTC()
+		if next.After(now) {
+			return next
+		}
+	}
+}
+
+func randomSuffix() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File writes each message as an .eml file and logs it (untuk development tanpa SMTP).
+type File struct {
+	Dir  string
+	From string
+}
+
+// NewFile returns a file mailer writing to dir.
+func NewFile(dir, from string) *File {
+	return &File{Dir: dir, From: from}
+}
+
+// Name implements Mailer.
+func (f *File) Name() string { return "file" }
+
+// Send implements Mailer.
+func (f *File) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipient
+	}
+	raw, err := build(f.From, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(f.Dir, fmt.Sprintf("%d.eml", time.Now().UnixNano()))
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return err
+	}
+	log.Printf("[mail] to=%s subject=%q -> %s", strings.Join(msg.To, ","), msg.Subject, path)
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package mail sends email through a pluggable Mailer (SMTP, atau file .eml untuk dev).
+package mail
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/config"
+)
+
+// ErrNoRecipient is returned when a message has no To address.
+var ErrNoRecipient = errors.New("mail: no recipient")
+
+// Attachment is one file attached to a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is one email. Text dan HTML boleh diisi salah satu atau keduanya.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	// Name returns the mailer identifier (smtp, file).
+	Name() string
+	// Send delivers msg or returns an error (dicoba ulang oleh outbox).
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromConfig returns an SMTP mailer when SMTP_HOST is set, otherwise a file mailer writing to MAIL_DIR.
+func FromConfig(cfg *config.Config) Mailer {
+	from := cfg.MailFrom
+	if from == "" {
+		from = cfg.SMTPUsername
+	}
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	if cfg.SMTPHost != "" {
+		return NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, from)
+	}
+	return NewFile(cfg.MailDir, from)
+}
@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// build renders msg as an RFC 5322 message: multipart/mixed berisi multipart/alternative (text + HTML) dan lampiran.
+func build(from string, msg Message) ([]byte, error) {
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	if err := writeQP(altWriter, "text/plain; charset=utf-8", msg.Text); err != nil {
+		return nil, err
+	}
+	if msg.HTML != "" {
+		if err := writeQP(altWriter, "text/html; charset=utf-8", msg.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := altWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mixed := multipart.NewWriter(&body)
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + altWriter.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alt.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, a := range msg.Attachments {
+		ct := a.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {ct},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			fmt.Fprintf(part, "%s\r\n", enc[:76])
+			enc = enc[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", enc)
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func writeQP(w *multipart.Writer, contentType, body string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
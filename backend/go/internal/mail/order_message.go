@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// OrderMessageData is the data for the "pesan baru di order Anda" email.
+type OrderMessageData struct {
+	Name        string
+	Email       string
+	Layanan     string
+	Preview     string // cuplikan pesan (maks. beberapa ratus karakter)
+	Attachments int    // jumlah lampiran
+	URL         string // portal lacak order (dengan kode lacak)
+}
+
+var orderMessageText = texttemplate.Must(texttemplate.New("order_message").Parse(`Halo {{if .Name}}{{.Name}}{{else}}Kak{{end}},
+
+Ada pesan baru dari kami untuk order {{.Layanan}}:
+
+{{.Preview}}
+{{- if .Attachments}}
+
+({{.Attachments}} lampiran){{end}}
+
+Baca dan balas pesan lewat halaman lacak order:
+{{.URL}}
+
+Salam,
+Raspro
+`))
+
+var orderMessageHTML = htmltemplate.Must(htmltemplate.New("order_message").Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Halo, {{if .Name}}{{.Name}}{{else}}Kak{{end}}!</h2>
+<p>Ada pesan baru dari kami untuk order <b>{{.Layanan}}</b>:</p>
+<blockquote style="border-left:3px solid #ccc;margin:0;padding:4px 12px;white-space:pre-wrap">{{.Preview}}</blockquote>
+{{if .Attachments}}<p style="font-size:13px;color:#666">{{.Attachments}} lampiran</p>{{end}}
+<p><a href="{{.URL}}" style="background:#222;color:#fff;padding:10px 18px;text-decoration:none;border-radius:4px">Baca &amp; balas</a></p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+// OrderMessage builds the email that tells the client about a new message from admin.
+func OrderMessage(d OrderMessageData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := orderMessageText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := orderMessageHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Pesan baru untuk order " + d.Layanan,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
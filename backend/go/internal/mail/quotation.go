@@ -0,0 +1,69 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+)
+
+// QuotationData is the data for the quotation (penawaran harga) email.
+type QuotationData struct {
+	Name          string
+	Email         string
+	Nomor         string
+	Layanan       string
+	Total         int       // IDR
+	BerlakuSampai time.Time // tanggal terakhir penawaran bisa disetujui
+	URL           string    // halaman penawaran (setujui/tolak) dengan token bertanda tangan
+}
+
+// DateID formats t as "7 Maret 2026".
+func DateID(t time.Time) string {
+	return strconv.Itoa(t.Day()) + " " + monthYear(t)
+}
+
+var quotationFuncs = map[string]any{"idr": FormatIDR, "date": DateID}
+
+var quotationText = texttemplate.Must(texttemplate.New("quotation").Funcs(quotationFuncs).Parse(`Halo {{if .Name}}{{.Name}}{{else}}Kak{{end}},
+
+Terima kasih sudah menghubungi kami. Penawaran harga {{.Nomor}} untuk {{.Layanan}} sudah siap:
+
+Total          : {{idr .Total}}
+Berlaku sampai : {{date .BerlakuSampai}}
+
+Lihat rincian, unduh PDF, lalu setujui atau tolak penawaran lewat link berikut:
+{{.URL}}
+
+Salam,
+Raspro
+`))
+
+var quotationHTML = htmltemplate.Must(htmltemplate.New("quotation").Funcs(quotationFuncs).Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Halo, {{if .Name}}{{.Name}}{{else}}Kak{{end}}!</h2>
+<p>Terima kasih sudah menghubungi kami. Penawaran harga <b>{{.Nomor}}</b> untuk <b>{{.Layanan}}</b> sudah siap.</p>
+<p style="font-size:18px"><b>{{idr .Total}}</b></p>
+<p style="font-size:13px;color:#666">Berlaku sampai {{date .BerlakuSampai}}</p>
+<p><a href="{{.URL}}" style="background:#222;color:#fff;padding:10px 18px;text-decoration:none;border-radius:4px">Lihat penawaran</a></p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+// QuotationLink builds the email that sends the quotation link to the client.
+func QuotationLink(d QuotationData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := quotationText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := quotationHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Penawaran harga " + d.Nomor + " - " + d.Layanan,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// RevisionPurchaseData is the data for the "tiket revisi tambahan" email sent after payment settles.
+type RevisionPurchaseData struct {
+	Name    string
+	Email   string
+	Layanan string
+	Amount  int      // IDR yang dibayar
+	Codes   []string // kode tiket revisi yang diterbitkan
+	URL     string   // halaman klaim revisi
+}
+
+var revisionPurchaseFuncs = map[string]any{"idr": FormatIDR}
+
+var revisionPurchaseText = texttemplate.Must(texttemplate.New("revision_purchase").Funcs(revisionPurchaseFuncs).Parse(`Halo {{if .Name}}{{.Name}}{{else}}Kak{{end}},
+
+Pembayaran {{idr .Amount}} untuk revisi tambahan order {{.Layanan}} sudah kami terima. Berikut kode tiket revisi Anda:
+{{range .Codes}}
+- {{.}}{{end}}
+
+Setiap kode berlaku untuk satu kali revisi. Klaim revisi dengan menuliskan perubahan yang diinginkan di:
+{{.URL}}
+
+Salam,
+Raspro
+`))
+
+var revisionPurchaseHTML = htmltemplate.Must(htmltemplate.New("revision_purchase").Funcs(revisionPurchaseFuncs).Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Halo, {{if .Name}}{{.Name}}{{else}}Kak{{end}}!</h2>
+<p>Pembayaran <b>{{idr .Amount}}</b> untuk revisi tambahan order <b>{{.Layanan}}</b> sudah kami terima. Berikut kode tiket revisi Anda:</p>
+<ul>{{range .Codes}}<li style="font-family:monospace;font-size:16px">{{.}}</li>{{end}}</ul>
+<p>Setiap kode berlaku untuk satu kali revisi.</p>
+<p><a href="{{.URL}}" style="background:#222;color:#fff;padding:10px 18px;text-decoration:none;border-radius:4px">Klaim revisi</a></p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+// RevisionPurchase builds the email with the ticket codes of a paid extra-revision purchase.
+func RevisionPurchase(d RevisionPurchaseData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := revisionPurchaseText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := revisionPurchaseHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Tiket revisi tambahan untuk order " + d.Layanan,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// RevisionVoucherData is the data for the email that carries the revision voucher sheet (PDF terlampir).
+type RevisionVoucherData struct {
+	Name      string
+	Email     string
+	Layanan   string
+	Count     int    // jumlah tiket di lembar
+	MasaKlaim string // e.g. "7 (tujuh) hari"; kosong = tanpa batas waktu
+	URL       string // halaman klaim revisi
+}
+
+var revisionVoucherText = texttemplate.Must(texttemplate.New("revision_voucher").Parse(`Halo {{if .Name}}{{.Name}}{{else}}Kak{{end}},
+
+Terlampir lembar tiket revisi untuk order {{.Layanan}} ({{.Count}} tiket). Setiap kode berlaku untuk satu kali revisi.
+{{if .MasaKlaim}}Tiket dapat diklaim paling lambat {{.MasaKlaim}} setelah hasil pekerjaan diserahkan.
+{{end}}
+Pindai QR code pada lembar tiket atau buka halaman berikut lalu masukkan kode tiket:
+{{.URL}}
+
+Simpan kode ini dan jangan dibagikan kepada pihak lain.
+
+Salam,
+Raspro
+`))
+
+var revisionVoucherHTML = htmltemplate.Must(htmltemplate.New("revision_voucher").Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Halo, {{if .Name}}{{.Name}}{{else}}Kak{{end}}!</h2>
+<p>Terlampir lembar tiket revisi untuk order <b>{{.Layanan}}</b> ({{.Count}} tiket). Setiap kode berlaku untuk satu kali revisi.</p>
+{{if .MasaKlaim}}<p>Tiket dapat diklaim paling lambat <b>{{.MasaKlaim}}</b> setelah hasil pekerjaan diserahkan.</p>{{end}}
+<p>Pindai QR code pada lembar tiket atau buka halaman klaim lalu masukkan kode tiket.</p>
+<p><a href="{{.URL}}" style="background:#222;color:#fff;padding:10px 18px;text-decoration:none;border-radius:4px">Klaim revisi</a></p>
+<p style="color:#666;font-size:13px">Simpan kode ini dan jangan dibagikan kepada pihak lain.</p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+// RevisionVoucher builds the email for the revision voucher sheet; PDF-nya dilampirkan pemanggil.
+func RevisionVoucher(d RevisionVoucherData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := revisionVoucherText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := revisionVoucherHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Tiket revisi untuk order " + d.Layanan,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,94 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTP sends mail through an SMTP server. Port 465 memakai TLS langsung, port lain STARTTLS bila tersedia.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewSMTP returns an SMTP mailer.
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	return &SMTP{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+// Name implements Mailer.
+func (s *SMTP) Name() string { return "smtp" }
+
+// Send implements Mailer.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipient
+	}
+	raw, err := build(s.From, msg)
+	if err != nil {
+		return err
+	}
+	sender, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("mail: invalid from address: %w", err)
+	}
+	addr := net.JoinHostPort(s.Host, fmt.Sprint(s.Port))
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	var conn net.Conn
+	if s.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && s.Port != 465 {
+		if err := c.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sender.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
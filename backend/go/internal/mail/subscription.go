@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+// SubscriptionData is the data for the monthly subscription payment email.
+type SubscriptionData struct {
+	Name      string
+	Email     string
+	PlanName  string
+	Amount    int       // IDR
+	Period    time.Time // awal periode yang ditagih
+	PayURL    string    // halaman bayar payment gateway
+	CancelURL string    // link batal langganan (token bertanda tangan)
+	PastDue   bool      // periode sebelumnya belum dibayar
+}
+
+var idMonths = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// monthYear formats t as "Maret 2026".
+func monthYear(t time.Time) string {
+	return idMonths[t.Month()-1] + " " + t.Format("2006")
+}
+
+var subscriptionFuncs = map[string]any{"idr": FormatIDR, "month": monthYear}
+
+var subscriptionText = texttemplate.Must(texttemplate.New("subscription").Funcs(subscriptionFuncs).Parse(`Halo {{if .Name}}{{.Name}}{{else}}Pendukung{{end}},
+
+Terima kasih sudah menjadi pendukung bulanan ({{.PlanName}}).
+Tagihan dukungan untuk periode {{month .Period}} sudah siap:
+
+Jumlah : {{idr .Amount}}
+Bayar  : {{.PayURL}}
+{{- if .PastDue}}
+
+Catatan: pembayaran periode sebelumnya belum kami terima.{{end}}
+
+Ingin berhenti? Batalkan langganan kapan saja lewat link berikut:
+{{.CancelURL}}
+
+Salam,
+Raspro
+`))
+
+var subscriptionHTML = htmltemplate.Must(htmltemplate.New("subscription").Funcs(subscriptionFuncs).Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Halo, {{if .Name}}{{.Name}}{{else}}Pendukung{{end}}!</h2>
+<p>Terima kasih sudah menjadi pendukung bulanan (<b>{{.PlanName}}</b>). Tagihan dukungan untuk periode <b>{{month .Period}}</b> sudah siap.</p>
+<p style="font-size:18px"><b>{{idr .Amount}}</b></p>
+<p><a href="{{.PayURL}}" style="background:#222;color:#fff;padding:10px 18px;text-decoration:none;border-radius:4px">Bayar sekarang</a></p>
+{{if .PastDue}}<p style="color:#a00">Pembayaran periode sebelumnya belum kami terima.</p>{{end}}
+<p style="font-size:12px;color:#666">Ingin berhenti? <a href="{{.CancelURL}}">Batalkan langganan</a> kapan saja.</p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+// SubscriptionPaymentLink returns the email with the payment link for one subscription period.
+func SubscriptionPaymentLink(d SubscriptionData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := subscriptionText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := subscriptionHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Tagihan dukungan bulanan " + monthYear(d.Period) + " - " + FormatIDR(d.Amount),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package mail
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+)
+
+// DonationData is the data for donation emails.
+type DonationData struct {
+	ReceiptNo string // nomor kuitansi (ID donasi)
+	Name      string
+	Email     string
+	Amount    int // IDR
+	Method    string
+	Campaign  string // judul campaign, kosong = donasi umum
+	Comment   string
+	PaidAt    time.Time
+}
+
+// FormatIDR formats n as "Rp 1.234.567".
+func FormatIDR(n int) string {
+	s := strconv.Itoa(n)
+	neg := false
+	if n < 0 {
+		neg = true
+		s = s[1:]
+	}
+	var out []byte
+	for i := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, s[i])
+	}
+	if neg {
+		return "Rp -" + string(out)
+	}
+	return "Rp " + string(out)
+}
+
+var funcs = map[string]any{
+	"idr":  FormatIDR,
+	"date": func(t time.Time) string { return t.Format("02 Jan 2006 15:04 MST") },
+}
+
+var receiptText = texttemplate.Must(texttemplate.New("receipt").Funcs(funcs).Parse(`Halo {{if .Name}}{{.Name}}{{else}}Donatur{{end}},
+
+Terima kasih atas donasi Anda. Pembayaran sudah kami terima.
+
+No. kuitansi : {{.ReceiptNo}}
+Jumlah       : {{idr .Amount}}
+Metode       : {{.Method}}
+{{- if .Campaign}}
+Campaign     : {{.Campaign}}{{end}}
+Tanggal      : {{date .PaidAt}}
+
+Dukungan Anda sangat berarti bagi kami.
+
+Salam,
+Raspro
+`))
+
+var receiptHTML = htmltemplate.Must(htmltemplate.New("receipt").Funcs(funcs).Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">
+<h2 style="margin-bottom:4px">Terima kasih, {{if .Name}}{{.Name}}{{else}}Donatur{{end}}!</h2>
+<p>Donasi Anda sudah kami terima. Berikut kuitansinya:</p>
+<table cellpadding="6" style="border-collapse:collapse;border:1px solid #ddd">
+<tr><td>No. kuitansi</td><td><b>{{.ReceiptNo}}</b></td></tr>
+<tr><td>Jumlah</td><td><b>{{idr .Amount}}</b></td></tr>
+<tr><td>Metode</td><td>{{.Method}}</td></tr>
+{{if .Campaign}}<tr><td>Campaign</td><td>{{.Campaign}}</td></tr>{{end}}
+<tr><td>Tanggal</td><td>{{date .PaidAt}}</td></tr>
+</table>
+<p>Dukungan Anda sangat berarti bagi kami.</p>
+<p>Salam,<br>Raspro</p>
+</body></html>
+`))
+
+var highlightText = texttemplate.Must(texttemplate.New("highlight").Funcs(funcs).Parse(`Donasi highlight baru masuk.
+
+Dari    : {{if .Name}}{{.Name}}{{else}}(tanpa nama){{end}}{{if .Email}} <{{.Email}}>{{end}}
+Jumlah  : {{idr .Amount}}
+Metode  : {{.Method}}
+{{- if .Campaign}}
+Campaign: {{.Campaign}}{{end}}
+Tanggal : {{date .PaidAt}}
+{{- if .Comment}}
+
+Pesan:
+{{.Comment}}{{end}}
+`))
+
+var highlightHTML = htmltemplate.Must(htmltemplate.New("highlight").Funcs(funcs).Parse(`<!doctype html>
+<html><body style="font-family:Arial,sans-serif;color:#222">
+<h3>Donasi highlight baru: {{idr .Amount}}</h3>
+<p>Dari <b>{{if .Name}}{{.Name}}{{else}}(tanpa nama){{end}}</b>{{if .Email}} &lt;{{.Email}}&gt;{{end}} via {{.Method}}{{if .Campaign}} untuk campaign <b>{{.Campaign}}</b>{{end}}, {{date .PaidAt}}.</p>
+{{if .Comment}}<blockquote style="border-left:3px solid #ccc;padding-left:8px">{{.Comment}}</blockquote>{{end}}
+</body></html>
+`))
+
+// DonationReceipt returns the thank-you receipt email for a paid donation (tanpa lampiran).
+func DonationReceipt(d DonationData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := receiptText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := receiptHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{d.Email},
+		Subject: "Kuitansi donasi " + d.ReceiptNo + " - terima kasih!",
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// HighlightAlert returns the admin alert for a highlighted donation.
+func HighlightAlert(to string, d DonationData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := highlightText.Execute(&text, d); err != nil {
+		return Message{}, err
+	}
+	if err := highlightHTML.Execute(&html, d); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      []string{to},
+		Subject: "Donasi highlight " + FormatIDR(d.Amount) + " dari " + nameOr(d.Name, "donatur"),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func nameOr(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -12,6 +13,16 @@ import (
 	"backend/internal/config"
 )
 
+type ctxKey int
+
+const adminEmailKey ctxKey = 0
+
+// AdminEmail returns the email in the admin JWT that authorized the request ("" bila tidak ada).
+func AdminEmail(ctx context.Context) string {
+	email, _ := ctx.Value(adminEmailKey).(string)
+	return email
+}
+
 // AdminKey checks Authorization Bearer (JWT) or X-Admin-Key (JWT or legacy key). Only allowed if JWT valid or key matches.
 func AdminKey(cfg *config.Config) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -31,8 +42,8 @@ func AdminKey(cfg *config.Config) func(http.Handler) http.Handler {
 
 			// If it looks like a JWT (three parts), verify it
 			if parts := strings.Split(tokenStr, "."); len(parts) == 3 && cfg != nil && cfg.JWTSecret != "" {
-				if verifyAdminToken(tokenStr, cfg.JWTSecret) {
-					next.ServeHTTP(w, r)
+				if claims, ok := verifyAdminToken(tokenStr, cfg.JWTSecret); ok {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminEmailKey, claims.Email)))
 					return
 				}
 			}
@@ -48,30 +59,30 @@ type adminTokenClaims struct {
 	Iat   int64  `json:"iat"`
 }
 
-func verifyAdminToken(token, secret string) bool {
+func verifyAdminToken(token, secret string) (adminTokenClaims, bool) {
+	var claims adminTokenClaims
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
-		return false
+		return claims, false
 	}
 	enc := base64.RawURLEncoding
 	unsigned := parts[0] + "." + parts[1]
 	gotSig, err := enc.DecodeString(parts[2])
 	if err != nil {
-		return false
+		return claims, false
 	}
 	wantSig := hmacSHA256(unsigned, secret)
 	if !hmac.Equal(gotSig, wantSig) {
-		return false
+		return claims, false
 	}
 	payload, err := enc.DecodeString(parts[1])
 	if err != nil {
-		return false
+		return claims, false
 	}
-	var claims adminTokenClaims
 	if err := json.Unmarshal(payload, &claims); err != nil {
-		return false
+		return claims, false
 	}
-	return claims.Exp > time.Now().Unix()
+	return claims, claims.Exp > time.Now().Unix()
 }
 
 func hmacSHA256(data, secret string) []byte {
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is the state of one key.
+type entry struct {
+	tokens       float64
+	refilledAt   time.Time
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+	touched      time.Time
+}
+
+// MemoryStore keeps limiter state in memory (per proses; hilang saat restart).
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry)}
+}
+
+func (s *MemoryStore) get(key string, now time.Time) *entry {
+	e := s.entries[key]
+	if e == nil {
+		e = &entry{tokens: -1}
+		s.entries[key] = e
+	}
+	e.touched = now
+	return e
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, burst int, every time.Duration, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.get(key, now)
+	e.tokens, e.refilledAt = refill(e.tokens, e.refilledAt, burst, every, now)
+	if e.tokens < 1 {
+		return false, time.Duration((1 - e.tokens) * float64(every)), nil
+	}
+	e.tokens--
+	return true, 0, nil
+}
+
+// refill returns the tokens of a bucket at now; tokens < 0 means a new bucket (penuh).
+func refill(tokens float64, refilledAt time.Time, burst int, every time.Duration, now time.Time) (float64, time.Time) {
+	if tokens < 0 || refilledAt.IsZero() {
+		return float64(burst), now
+	}
+	if elapsed := now.Sub(refilledAt); elapsed > 0 {
+		tokens += float64(elapsed) / float64(every)
+	}
+	if tokens > float64(burst) {
+		tokens = float64(burst)
+	}
+	return tokens, now
+}
+
+// Blocked implements Store.
+func (s *MemoryStore) Blocked(_ context.Context, key string, _ time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.entries[key]; e != nil {
+		return e.blockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+// Fail implements Store.
+func (s *MemoryStore) Fail(_ context.Context, key string, now time.Time, window time.Duration, block func(int) time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.get(key, now)
+	if now.Sub(e.lastFailure) > window {
+		e.failures = 0
+	}
+	e.failures++
+	e.lastFailure = now
+	e.blockedUntil = now.Add(block(e.failures))
+	return e.failures, e.blockedUntil, nil
+}
+
+// Prune implements Store.
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, e := range s.entries {
+		if e.touched.Before(before) && e.blockedUntil.Before(before) {
+			delete(s.entries, key)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps limiter state in the rate_limits table, shared by all API instances.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore returns a store backed by PostgreSQL (tabel dibuat oleh db.Migrate).
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// lockKey ensures the row of key exists and locks it for the rest of tx.
+func lockKey(ctx context.Context, tx pgx.Tx, key string, now time.Time) (tokens float64, refilledAt *time.Time, failures int, lastFailure *time.Time, err error) {
+	if _, err = tx.Exec(ctx, `INSERT INTO rate_limits (key, touched_at) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING`, key, now); err != nil {
+		return
+	}
+	err = tx.QueryRow(ctx, `SELECT tokens, refilled_at, failures, last_failure_at FROM rate_limits WHERE key = $1 FOR UPDATE`, key).
+		Scan(&tokens, &refilledAt, &failures, &lastFailure)
+	return
+}
+
+// Take implements Store.
+func (s *PostgresStore) Take(ctx context.Context, key string, burst int, every time.Duration, now time.Time) (bool, time.Duration, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return true, 0, err
+	}
+	defer tx.Rollback(ctx)
+	tokens, refilledAt, _, _, err := lockKey(ctx, tx, key, now)
+	if err != nil {
+		return true, 0, err
+	}
+	var last time.Time
+	if refilledAt != nil {
+		last = *refilledAt
+	}
+	tokens, _ = refill(tokens, last, burst, every, now)
+	ok, wait := tokens >= 1, time.Duration(0)
+	if ok {
+		tokens--
+	} else {
+		wait = time.Duration((1 - tokens) * float64(every))
+	}
+	if _, err := tx.Exec(ctx, `UPDATE rate_limits SET tokens = $2, refilled_at = $3, touched_at = $3 WHERE key = $1`, key, tokens, now); err != nil {
+		return true, 0, err
+	}
+	return ok, wait, tx.Commit(ctx)
+}
+
+// Blocked implements Store.
+func (s *PostgresStore) Blocked(ctx context.Context, key string, _ time.Time) (time.Time, error) {
+	var until *time.Time
+	err := s.pool.QueryRow(ctx, `SELECT blocked_until FROM rate_limits WHERE key = $1`, key).Scan(&until)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && until == nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return *until, nil
+}
+
+// Fail implements Store.
+func (s *PostgresStore) Fail(ctx context.Context, key string, now time.Time, window time.Duration, block func(int) time.Duration) (int, time.Time, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer tx.Rollback(ctx)
+	_, _, failures, lastFailure, err := lockKey(ctx, tx, key, now)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if lastFailure == nil || now.Sub(*lastFailure) > window {
+		failures = 0
+	}
+	failures++
+	until := now.Add(block(failures))
+	if _, err := tx.Exec(ctx, `UPDATE rate_limits SET failures = $2, last_failure_at = $3, blocked_until = $4, touched_at = $3 WHERE key = $1`,
+		key, failures, now, until); err != nil {
+		return 0, time.Time{}, err
+	}
+	return failures, until, tx.Commit(ctx)
+}
+
+// Prune implements Store.
+func (s *PostgresStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM rate_limits WHERE touched_at < $1 AND (blocked_until IS NULL OR blocked_until < $1)`, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,367 @@
+// Package ratelimit limits public endpoints with a token bucket per client IP (dan opsional per prefix kode),
+// plus exponential backoff and temporary lockouts after failed attempts such as wrong codes.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/config"
+)
+
+// Store keeps bucket and failure state per key (memory atau PostgreSQL).
+type Store interface {
+	// Take removes one token from the bucket of key, which holds at most burst tokens and regains one every interval.
+	// Returns false and the wait until the next token when the bucket is empty.
+	Take(ctx context.Context, key string, burst int, every time.Duration, now time.Time) (bool, time.Duration, error)
+	// Blocked returns until when key is blocked after failed attempts (zero = tidak diblokir).
+	Blocked(ctx context.Context, key string, now time.Time) (time.Time, error)
+	// Fail records a failed attempt of key and blocks it for block(failures). Failures older than window are forgotten.
+	Fail(ctx context.Context, key string, now time.Time, window time.Duration, block func(failures int) time.Duration) (int, time.Time, error)
+	// Prune removes state not touched since before.
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// Limiter is one rate limit policy applied to a group of routes.
+type Limiter struct {
+	Name  string        // namespace kunci dan label log, e.g. "code"
+	Store Store         // nil = tidak membatasi
+	Burst int           // token maksimum per kunci (permintaan beruntun yang diizinkan)
+	Every time.Duration // satu token kembali setiap Every; 0 = tanpa token bucket
+
+	// Code returns the code a request is guessing (opsional). Bila diisi dan CodePrefix > 0, kode salah juga dihitung
+	// per prefix kode (lintas IP): setelah Burst kode salah dalam Window, tiap kode salah berikutnya memblokir prefix
+	// itu selama Every. Hanya kegagalan yang dihitung, jadi pemilik kode yang valid tidak ikut terblokir oleh lalu lintas biasa.
+	Code       func(r *http.Request) string
+	CodePrefix int
+
+	// Kegagalan (kode salah) per IP: jeda Backoff, 2x Backoff, 4x ... sampai MaxBackoff,
+	// dan setelah LockAfter kegagalan diblokir selama Lockout. LockAfter 0 = tanpa lockout.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	LockAfter  int
+	Lockout    time.Duration
+	Window     time.Duration // kegagalan dilupakan setelah Window tanpa kegagalan baru (default 1 jam)
+}
+
+type ctxKey int
+
+const attemptKey ctxKey = 0
+
+// attempt is stored in the request context so handlers can report the outcome.
+type attempt struct {
+	l    *Limiter
+	ip   string
+	code string // kode yang ditebak (dari URL, atau diisi handler lewat CheckCode)
+}
+
+// Handler returns the middleware. Permintaan yang ditolak mendapat 429 dengan header Retry-After.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l == nil || l.Store == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, now := r.Context(), time.Now()
+		a := &attempt{l: l, ip: ClientIP(r)}
+		if l.Code != nil {
+			a.code = l.Code(r)
+		}
+		for _, key := range []string{l.Name + ":ip:" + a.ip, l.codeKey(a.code)} {
+			if wait, blocked := l.blocked(ctx, key, now); blocked {
+				tooMany(w, wait)
+				return
+			}
+		}
+		if l.Every > 0 && l.Burst > 0 {
+			key := l.Name + ":ip:" + a.ip
+			ok, wait, err := l.Store.Take(ctx, key, l.Burst, l.Every, now)
+			if err != nil {
+				log.Printf("[ratelimit] %s: %v", l.Name, err)
+			} else if !ok {
+				log.Printf("[ratelimit] %s: %s over limit (%s %s)", l.Name, key, r.Method, r.URL.Path)
+				tooMany(w, wait)
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, attemptKey, a)))
+	})
+}
+
+// CheckCode registers a code read by the handler (body JSON atau multipart) for the request's attempt and writes 429
+// bila prefix kode itu sedang diblokir karena terlalu banyak kode salah. Fail lalu menghitung kegagalan untuk prefix ini.
+// No-op bila route tidak memakai Limiter.
+func CheckCode(w http.ResponseWriter, r *http.Request, code string) bool {
+	a, _ := r.Context().Value(attemptKey).(*attempt)
+	if a == nil || a.l.Store == nil {
+		return true
+	}
+	a.code = code
+	if wait, blocked := a.l.blocked(r.Context(), a.l.codeKey(code), time.Now()); blocked {
+		tooMany(w, wait)
+		return false
+	}
+	return true
+}
+
+// blocked reports whether key is blocked after failed attempts and for how long (key "" tidak pernah diblokir).
+func (l *Limiter) blocked(ctx context.Context, key string, now time.Time) (time.Duration, bool) {
+	if key == "" {
+		return 0, false
+	}
+	until, err := l.Store.Blocked(ctx, key, now)
+	if err != nil {
+		log.Printf("[ratelimit] %s: %v", l.Name, err)
+		return 0, false
+	}
+	if !until.After(now) {
+		return 0, false
+	}
+	log.Printf("[ratelimit] %s: %s blocked until %s", l.Name, key, until.Format(time.RFC3339))
+	return until.Sub(now), true
+}
+
+// Allow takes a token from the bucket of an arbitrary key, e.g. per IP atau per order untuk batas di dalam handler.
+// Returns the wait until the next token when refused. Limiter nil atau tanpa Store selalu mengizinkan.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration) {
+	if l == nil || l.Store == nil || l.Every <= 0 || l.Burst <= 0 {
+		return true, 0
+	}
+	ok, wait, err := l.Store.Take(ctx, l.Name+":"+key, l.Burst, l.Every, time.Now())
+	if err != nil {
+		log.Printf("[ratelimit] %s: %v", l.Name, err)
+		return true, 0
+	}
+	if !ok {
+		log.Printf("[ratelimit] %s: %s over limit", l.Name, key)
+	}
+	return ok, wait
+}
+
+// codeKey returns the failure key for the prefix of code ("" bila kode kosong atau CodePrefix tidak diisi).
+func (l *Limiter) codeKey(code string) string {
+	code = normalizeCode(code)
+	if code == "" || l.CodePrefix <= 0 {
+		return ""
+	}
+	if len(code) > l.CodePrefix {
+		code = code[:l.CodePrefix]
+	}
+	return l.Name + ":code:" + code
+}
+
+// Fail records a failed attempt (kode/OTP salah) for the request's IP and the prefix of the guessed code.
+// No-op bila route tidak memakai Limiter.
+// Tidak ada reset saat kode benar: pemilik satu kode valid tidak bisa memakainya untuk menghapus jejak tebakan;
+// kegagalan hilang sendiri setelah Window.
+func Fail(r *http.Request) {
+	a, _ := r.Context().Value(attemptKey).(*attempt)
+	if a == nil || a.l.Store == nil {
+		return
+	}
+	l, now := a.l, time.Now()
+	window := l.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+	if key := l.codeKey(a.code); key != "" {
+		if _, until, err := l.Store.Fail(r.Context(), key, now, window, l.codeBlockFor); err != nil {
+			log.Printf("[ratelimit] %s: %v", l.Name, err)
+		} else if until.After(now) {
+			log.Printf("[ratelimit] %s: %s blocked until %s after repeated wrong codes", l.Name, key, until.Format(time.RFC3339))
+		}
+	}
+	n, until, err := l.Store.Fail(r.Context(), l.Name+":ip:"+a.ip, now, window, l.blockFor)
+	if err != nil {
+		log.Printf("[ratelimit] %s: %v", l.Name, err)
+		return
+	}
+	if l.LockAfter > 0 && n >= l.LockAfter {
+		log.Printf("[ratelimit] %s: %s locked out until %s after %d failed attempts (%s)", l.Name, a.ip, until.Format(time.RFC3339), n, r.URL.Path)
+		return
+	}
+	log.Printf("[ratelimit] %s: failed attempt %d from %s (%s), next try in %s", l.Name, n, a.ip, r.URL.Path, until.Sub(now).Round(time.Second))
+}
+
+// blockFor returns how long a key is blocked after its n-th failure.
+func (l *Limiter) blockFor(n int) time.Duration {
+	if l.LockAfter > 0 && n >= l.LockAfter {
+		return l.Lockout
+	}
+	if l.Backoff <= 0 || n <= 0 {
+		return 0
+	}
+	d := time.Duration(float64(l.Backoff) * math.Pow(2, float64(n-1)))
+	if l.MaxBackoff > 0 && (d > l.MaxBackoff || d <= 0) {
+		d = l.MaxBackoff
+	}
+	return d
+}
+
+// codeBlockFor returns how long a code prefix is blocked after its n-th failure: bebas sampai Burst kegagalan,
+// lalu Every per kegagalan (paling banyak satu tebakan salah per Every untuk prefix itu).
+func (l *Limiter) codeBlockFor(n int) time.Duration {
+	if l.Burst <= 0 || n <= l.Burst {
+		return 0
+	}
+	return l.Every
+}
+
+func tooMany(w http.ResponseWriter, wait time.Duration) {
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":          false,
+		"message":     "Terlalu banyak percobaan. Coba lagi dalam " + humanWait(secs) + ".",
+		"retry_after": secs,
+	})
+}
+
+func humanWait(secs int) string {
+	if secs < 60 {
+		return strconv.Itoa(secs) + " detik"
+	}
+	return strconv.Itoa((secs+59)/60) + " menit"
+}
+
+// normalizeCode uppercases a code and drops spaces, seperti pencocokan kode tiket.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// trustedProxies are the reverse proxies whose X-Forwarded-For/X-Real-IP headers are believed (lihat TrustProxies).
+var trustedProxies []*net.IPNet
+
+// TrustProxies sets the reverse proxies allowed to report the client IP, dari TRUSTED_PROXIES (CIDR atau IP, pisah koma).
+// Entri yang tidak valid dilewati dan dicatat di log. Dipanggil sekali saat start, sebelum server menerima request.
+func TrustProxies(list string) {
+	trustedProxies = nil
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			if ip := net.ParseIP(part); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, n, err := net.ParseCIDR(part)
+		if err != nil {
+			log.Printf("[ratelimit] TRUSTED_PROXIES: invalid entry %q ignored", part)
+			continue
+		}
+		trustedProxies = append(trustedProxies, n)
+	}
+}
+
+func trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the caller's IP from RemoteAddr. Hanya bila koneksi datang dari proxy tepercaya, X-Forwarded-For
+// dibaca dari kanan dan hop pertama yang bukan proxy tepercaya dipakai (entri kiri bisa dipalsukan klien);
+// tanpa X-Forwarded-For, X-Real-IP dari proxy tersebut dipakai.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !trusted(host) {
+		return host
+	}
+	if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+		hops := strings.Split(strings.Join(fwd, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !trusted(hop) {
+				return hop
+			}
+			host = hop
+		}
+		return host
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return host
+}
+
+// URLParam reads the code from a route parameter, e.g. {code} di /api/revisi/{code}.
+// Hanya berfungsi bila Limiter dipasang per route (r.With), setelah chi mencocokkan route.
+func URLParam(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return chi.URLParam(r, name)
+	}
+}
+
+// PerHour returns a keyed limiter allowing n requests per hour per key (dipakai lewat Allow); n <= 0 = tidak membatasi.
+func PerHour(name string, st Store, n int) *Limiter {
+	l := &Limiter{Name: name, Store: st}
+	if n > 0 {
+		l.Burst, l.Every = n, time.Hour/time.Duration(n)
+	}
+	return l
+}
+
+// Public returns the limiter for public endpoints that change data: RATE_LIMIT_PER_MINUTE permintaan per menit per IP.
+func Public(cfg *config.Config, st Store) *Limiter {
+	l := &Limiter{Name: "public", Store: st}
+	if cfg != nil && cfg.RateLimitPerMinute > 0 {
+		l.Burst, l.Every = cfg.RateLimitPerMinute, time.Minute/time.Duration(cfg.RateLimitPerMinute)
+	}
+	return l
+}
+
+// Codes returns the stricter limiter for endpoints that check a secret code (tiket revisi, kode lacak, OTP, login):
+// CODE_GUESS_PER_MINUTE per IP, jeda 1s, 2s, 4s ... (maks. 1 menit) setiap kode salah, lalu lockout LOCKOUT_MINUTES
+// setelah LOCKOUT_AFTER kode salah. Kode salah juga dihitung per prefix kode (lihat Limiter.Code).
+func Codes(cfg *config.Config, st Store) *Limiter {
+	l := &Limiter{
+		Name:       "code",
+		Store:      st,
+		Code:       URLParam("code"), // kode di body (klaim revisi, OTP) didaftarkan handler lewat CheckCode
+		CodePrefix: 6,
+		Backoff:    time.Second,
+		MaxBackoff: time.Minute,
+		Window:     time.Hour,
+	}
+	if cfg != nil {
+		if cfg.GuessPerMinute > 0 {
+			l.Burst, l.Every = cfg.GuessPerMinute, time.Minute/time.Duration(cfg.GuessPerMinute)
+		}
+		l.LockAfter, l.Lockout = cfg.LockoutAfter, time.Duration(cfg.LockoutMinutes)*time.Minute
+	}
+	return l
+}
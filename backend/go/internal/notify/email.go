@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/store"
+)
+
+// Email queues notifications in the mail outbox so they are retried like other emails.
+type Email struct {
+	Outbox *store.MailOutboxStore
+	To     string
+	Kick   func() // opsional: jalankan dispatcher sekarang
+}
+
+// NewEmail returns an email notifier sending to to.
+func NewEmail(outbox *store.MailOutboxStore, to string, kick func()) *Email {
+	return &Email{Outbox: outbox, To: to, Kick: kick}
+}
+
+// Name implements Notifier.
+func (e *Email) Name() string { return "email" }
+
+// Notify implements Notifier. Pesan dengan Key yang sudah pernah diantrikan tidak dikirim lagi.
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	key := msg.Key
+	if key == "" {
+		key = "notify:" + time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if _, ok := e.Outbox.Enqueue(store.OutboxMessage{Key: key, To: e.To, Subject: msg.Subject, Text: msg.Text}); ok && e.Kick != nil {
+		e.Kick()
+	}
+	return nil
+}
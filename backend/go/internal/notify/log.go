@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Log writes notifications to the server log (untuk development).
+type Log struct{}
+
+// NewLog returns a log notifier.
+func NewLog() *Log { return &Log{} }
+
+// Name implements Notifier.
+func (l *Log) Name() string { return "log" }
+
+// Notify implements Notifier.
+func (l *Log) Notify(ctx context.Context, msg Message) error {
+	log.Printf("[notify] %s\n%s", msg.Subject, msg.Text)
+	return nil
+}
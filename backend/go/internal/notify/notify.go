@@ -0,0 +1,46 @@
+// Package notify sends short admin notifications through a pluggable channel (email, Telegram, atau log untuk lokal).
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/store"
+)
+
+// Message is one notification. Key dipakai untuk dedupe oleh channel yang mendukung (email outbox).
+type Message struct {
+	Key     string
+	Subject string
+	Text    string
+}
+
+// Notifier delivers notifications to the admin.
+type Notifier interface {
+	// Name returns the channel identifier (email, telegram, log).
+	Name() string
+	// Notify delivers msg or returns an error.
+	Notify(ctx context.Context, msg Message) error
+}
+
+// FromConfig returns the notifier selected by NOTIFIER (email | telegram | log, default log).
+// kick dipanggil setelah email masuk outbox agar langsung dikirim (boleh nil).
+func FromConfig(cfg *config.Config, outbox *store.MailOutboxStore, kick func()) (Notifier, error) {
+	switch strings.ToLower(cfg.Notifier) {
+	case "", "log":
+		return NewLog(), nil
+	case "email":
+		if cfg.AdminNotifyEmail == "" || outbox == nil {
+			return nil, fmt.Errorf("notify: email needs ADMIN_NOTIFY_EMAIL")
+		}
+		return NewEmail(outbox, cfg.AdminNotifyEmail, kick), nil
+	case "telegram":
+		if cfg.TelegramBotToken == "" || cfg.TelegramChatID == "" {
+			return nil, fmt.Errorf("notify: telegram needs TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID")
+		}
+		return NewTelegram(cfg.TelegramBotToken, cfg.TelegramChatID, cfg.TelegramAPIURL), nil
+	}
+	return nil, fmt.Errorf("notify: unknown notifier %q", cfg.Notifier)
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// telegramMaxText is the Bot API message length limit.
+const telegramMaxText = 4096
+
+// Telegram sends notifications to a chat through a Telegram bot.
+type Telegram struct {
+	Token  string
+	ChatID string
+	APIURL string // default https://api.telegram.org; override untuk mock lokal
+	Client *http.Client
+}
+
+// NewTelegram returns a Telegram notifier. apiURL boleh kosong.
+func NewTelegram(token, chatID, apiURL string) *Telegram {
+	if apiURL == "" {
+		apiURL = "https://api.telegram.org"
+	}
+	return &Telegram{Token: token, ChatID: chatID, APIURL: strings.TrimRight(apiURL, "/"), Client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Name implements Notifier.
+func (t *Telegram) Name() string { return "telegram" }
+
+// Notify implements Notifier.
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	text := msg.Text
+	if msg.Subject != "" {
+		text = msg.Subject + "\n\n" + text
+	}
+	if r := []rune(text); len(r) > telegramMaxText {
+		text = string(r[:telegramMaxText-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]interface{}{"chat_id": t.ChatID, "text": text, "disable_web_page_preview": true})
+	if err != nil {
+		return err
+	}
+	// URL memuat token bot: error dibungkus tanpa URL agar token tidak ikut tercatat di log.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.APIURL+"/bot"+t.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram: sendMessage: %w", stripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram: sendMessage: %w", stripURL(err))
+	}
+	defer resp.Body.Close()
+	var out struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err := json.Unmarshal(raw, &out); err != nil || !out.OK {
+		return fmt.Errorf("telegram: sendMessage %d: %s", resp.StatusCode, out.Description)
+	}
+	return nil
+}
+
+// stripURL drops the request URL from err (net/http dan net/url menyertakan URL lengkap di pesan error).
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
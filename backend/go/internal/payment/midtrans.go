@@ -0,0 +1,246 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const midtransSnapSandboxURL = "https://app.sandbox.midtrans.com/snap/v1/transactions"
+const midtransSnapProductionURL = "https://app.midtrans.com/snap/v1/transactions"
+const midtransAPISandboxURL = "https://api.sandbox.midtrans.com"
+const midtransAPIProductionURL = "https://api.midtrans.com"
+
+// Midtrans implements Gateway with Snap (create) and Core API (status, refund).
+type Midtrans struct {
+	ServerKey  string
+	ClientKey  string
+	SnapURL    string
+	APIBaseURL string // tanpa trailing slash; bisa diarahkan ke mock lokal
+	HTTPClient *http.Client
+}
+
+// NewMidtrans returns a Midtrans gateway for sandbox or production. snapURL/apiURL override the defaults when non-empty.
+func NewMidtrans(serverKey, clientKey string, isProduction bool, snapURL, apiURL string) *Midtrans {
+	if snapURL == "" {
+		snapURL = midtransSnapSandboxURL
+		if isProduction {
+			snapURL = midtransSnapProductionURL
+		}
+	}
+	if apiURL == "" {
+		apiURL = midtransAPISandboxURL
+		if isProduction {
+			apiURL = midtransAPIProductionURL
+		}
+	}
+	return &Midtrans{
+		ServerKey:  serverKey,
+		ClientKey:  clientKey,
+		SnapURL:    snapURL,
+		APIBaseURL: strings.TrimSuffix(apiURL, "/"),
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implements Gateway.
+func (m *Midtrans) Name() string { return "midtrans" }
+
+// CreateTransaction creates a Snap transaction (GoPay) and returns the snap token.
+func (m *Midtrans) CreateTransaction(ctx context.Context, req CreateRequest) (Transaction, error) {
+	payload := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.OrderID,
+			"gross_amount": req.Amount,
+		},
+		"customer_details": map[string]interface{}{
+			"first_name": req.Customer.Name,
+			"email":      req.Customer.Email,
+		},
+		"enabled_payments": []string{"gopay"},
+	}
+	// Snap hanya punya custom_field1..3; urutan mengikuti webhook donasi lama (name, email, comment).
+	for i, key := range midtransCustomFields {
+		if v := req.Metadata[key]; v != "" {
+			payload["custom_field"+strconv.Itoa(i+1)] = v
+		}
+	}
+	var snapResp struct {
+		Token       string   `json:"token"`
+		RedirectURL string   `json:"redirect_url"`
+		Errors      []string `json:"error_messages,omitempty"`
+	}
+	if err := m.do(ctx, http.MethodPost, m.SnapURL, payload, &snapResp); err != nil {
+		return Transaction{}, err
+	}
+	if snapResp.Token == "" {
+		return Transaction{}, fmt.Errorf("midtrans: snap returned no token: %s", strings.Join(snapResp.Errors, "; "))
+	}
+	return Transaction{OrderID: req.OrderID, Token: snapResp.Token, RedirectURL: snapResp.RedirectURL, ClientKey: m.ClientKey}, nil
+}
+
+// midtransCustomFields maps Metadata keys to custom_field1..3.
+var midtransCustomFields = []string{"name", "email", "comment"}
+
+type midtransStatus struct {
+	StatusCode        string      `json:"status_code"`
+	StatusMessage     string      `json:"status_message"`
+	OrderID           string      `json:"order_id"`
+	TransactionStatus string      `json:"transaction_status"`
+	FraudStatus       string      `json:"fraud_status"`
+	GrossAmount       interface{} `json:"gross_amount"` // Midtrans bisa kirim string "50000.00" atau number
+	SignatureKey      string      `json:"signature_key"`
+	CustomField1      string      `json:"custom_field1"`
+	CustomField2      string      `json:"custom_field2"`
+	CustomField3      string      `json:"custom_field3"`
+}
+
+func (s midtransStatus) event() Event {
+	ev := Event{
+		OrderID:  s.OrderID,
+		Status:   strings.ToLower(s.TransactionStatus),
+		Amount:   ParseAmount(s.GrossAmount),
+		Metadata: map[string]string{},
+	}
+	// Kartu yang ditahan fraud detection belum dianggap lunas.
+	if ev.Status == StatusCapture && strings.ToLower(s.FraudStatus) == "challenge" {
+		ev.Status = StatusPending
+	}
+	if ev.Status == "partial_refund" {
+		ev.Status = StatusRefund
+	}
+	for i, v := range []string{s.CustomField1, s.CustomField2, s.CustomField3} {
+		if v != "" {
+			ev.Metadata[midtransCustomFields[i]] = v
+		}
+	}
+	return ev
+}
+
+// ParseNotification verifies signature_key = SHA512(order_id + status_code + gross_amount + server_key).
+func (m *Midtrans) ParseNotification(body []byte, _ http.Header) (Event, error) {
+	var n midtransStatus
+	if err := json.Unmarshal(body, &n); err != nil {
+		return Event{}, fmt.Errorf("midtrans: decode notification: %w", err)
+	}
+	gross := ""
+	switch v := n.GrossAmount.(type) {
+	case string:
+		gross = v
+	case float64:
+		gross = strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + gross + m.ServerKey))
+	want := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(want), []byte(strings.ToLower(n.SignatureKey))) != 1 {
+		return Event{}, ErrInvalidSignature
+	}
+	return n.event(), nil
+}
+
+// Status implements Gateway using GET /v2/{order_id}/status.
+func (m *Midtrans) Status(ctx context.Context, orderID string) (Event, error) {
+	var s midtransStatus
+	if err := m.do(ctx, http.MethodGet, m.APIBaseURL+"/v2/"+url.PathEscape(orderID)+"/status", nil, &s); err != nil {
+		return Event{}, err
+	}
+	// Midtrans membalas HTTP 200 dengan status_code di body.
+	if s.StatusCode == "404" {
+		return Event{}, ErrNotFound
+	}
+	if s.TransactionStatus == "" {
+		return Event{}, fmt.Errorf("midtrans: status %s: %s", s.StatusCode, s.StatusMessage)
+	}
+	return s.event(), nil
+}
+
+// Refund implements Gateway using POST /v2/{order_id}/refund.
+func (m *Midtrans) Refund(ctx context.Context, orderID string, amount int, reason string) error {
+	payload := map[string]interface{}{
+		"refund_key": fmt.Sprintf("%s-refund-%d", orderID, time.Now().Unix()),
+		"reason":     reason,
+	}
+	if amount > 0 {
+		payload["amount"] = amount
+	}
+	var resp midtransStatus
+	if err := m.do(ctx, http.MethodPost, m.APIBaseURL+"/v2/"+url.PathEscape(orderID)+"/refund", payload, &resp); err != nil {
+		return err
+	}
+	if resp.StatusCode == "404" {
+		return ErrNotFound
+	}
+	if resp.StatusCode != "200" {
+		return fmt.Errorf("midtrans: refund %s: %s", resp.StatusCode, resp.StatusMessage)
+	}
+	return nil
+}
+
+func (m *Midtrans) do(ctx context.Context, method, endpoint string, payload interface{}, out interface{}) error {
+	var body []byte
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(m.ServerKey, "")
+	resp, err := httpClient(m.HTTPClient).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("midtrans: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("midtrans: decode response: %w", err)
+	}
+	return nil
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
+
+// ParseAmount converts a gateway amount ("50000.00", 50000, ...) to whole IDR.
+func ParseAmount(v interface{}) int {
+	switch x := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return 0
+		}
+		return int(f)
+	case float64:
+		return int(x)
+	case int:
+		return x
+	case int64:
+		return int(x)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,102 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// Normalized transaction statuses (mengikuti istilah Midtrans karena itu yang sudah tersimpan di DB).
+const (
+	StatusPending    = "pending"
+	StatusSettlement = "settlement"
+	StatusCapture    = "capture"
+	StatusExpire     = "expire"
+	StatusCancel     = "cancel"
+	StatusDeny       = "deny"
+	StatusFailure    = "failure"
+	StatusRefund     = "refund"
+)
+
+var (
+	// ErrNotFound is returned by Status when the gateway does not know the order_id.
+	ErrNotFound = errors.New("payment: transaction not found")
+	// ErrInvalidSignature is returned by ParseNotification when the webhook cannot be verified.
+	ErrInvalidSignature = errors.New("payment: invalid notification signature")
+	// ErrNotConfigured is returned by FromConfig when the selected gateway has no credentials.
+	ErrNotConfigured = errors.New("payment: gateway not configured")
+)
+
+// Customer is the payer shown on the gateway's checkout page.
+type Customer struct {
+	Name  string
+	Email string
+}
+
+// CreateRequest is one payment to create (donasi, invoice, dll).
+type CreateRequest struct {
+	OrderID     string
+	Amount      int // IDR
+	Description string
+	Customer    Customer
+	Metadata    map[string]string // dikembalikan lagi di notifikasi bila gateway mendukung
+}
+
+// Transaction is what the frontend needs to continue the payment.
+type Transaction struct {
+	OrderID     string `json:"order_id"`
+	Token       string `json:"token,omitempty"`        // Midtrans Snap token / id invoice Xendit
+	RedirectURL string `json:"redirect_url,omitempty"` // halaman bayar hosted
+	ClientKey   string `json:"client_key,omitempty"`   // public key untuk SDK frontend (Snap)
+}
+
+// Event is a transaction status reported by a webhook or a status query.
+type Event struct {
+	OrderID  string
+	Status   string // salah satu Status*
+	Amount   int
+	Metadata map[string]string
+}
+
+// Gateway is a payment provider.
+type Gateway interface {
+	// Name returns the gateway identifier (midtrans, xendit).
+	Name() string
+	// CreateTransaction starts a payment and returns the checkout token/URL.
+	CreateTransaction(ctx context.Context, req CreateRequest) (Transaction, error)
+	// ParseNotification verifies and parses a webhook body with its headers.
+	ParseNotification(body []byte, header http.Header) (Event, error)
+	// Status asks the gateway for the current status of orderID.
+	Status(ctx context.Context, orderID string) (Event, error)
+	// Refund refunds amount (0 = full) of a paid transaction.
+	Refund(ctx context.Context, orderID string, amount int, reason string) error
+}
+
+// FromConfig returns the gateway selected by PAYMENT_GATEWAY (default midtrans).
+func FromConfig(cfg *config.Config) (Gateway, error) {
+	if cfg == nil {
+		return nil, ErrNotConfigured
+	}
+	switch strings.ToLower(cfg.PaymentGateway) {
+	case "", "midtrans":
+		if cfg.MidtransServerKey == "" {
+			return nil, ErrNotConfigured
+		}
+		return NewMidtrans(cfg.MidtransServerKey, cfg.MidtransClientKey, cfg.MidtransIsProduction, cfg.MidtransSnapURL, cfg.MidtransAPIURL), nil
+	case "xendit":
+		if cfg.XenditSecretKey == "" {
+			return nil, ErrNotConfigured
+		}
+		return NewXendit(cfg.XenditSecretKey, cfg.XenditCallbackToken, cfg.XenditAPIURL), nil
+	}
+	return nil, fmt.Errorf("payment: unknown gateway %q", cfg.PaymentGateway)
+}
+
+// IsPaid reports whether status means the money has been received.
+func IsPaid(status string) bool {
+	return status == StatusSettlement || status == StatusCapture
+}
@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const xenditAPIURL = "https://api.xendit.co"
+
+// Xendit implements Gateway with the Xendit Invoice API (hosted checkout page).
+type Xendit struct {
+	SecretKey     string
+	CallbackToken string // x-callback-token dari dashboard Xendit, untuk verifikasi webhook
+	APIBaseURL    string
+	HTTPClient    *http.Client
+}
+
+// NewXendit returns a Xendit gateway. apiURL overrides https://api.xendit.co when non-empty.
+func NewXendit(secretKey, callbackToken, apiURL string) *Xendit {
+	if apiURL == "" {
+		apiURL = xenditAPIURL
+	}
+	return &Xendit{
+		SecretKey:     secretKey,
+		CallbackToken: callbackToken,
+		APIBaseURL:    strings.TrimSuffix(apiURL, "/"),
+		HTTPClient:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implements Gateway.
+func (x *Xendit) Name() string { return "xendit" }
+
+type xenditInvoice struct {
+	ID         string            `json:"id"`
+	ExternalID string            `json:"external_id"`
+	Status     string            `json:"status"`
+	Amount     float64           `json:"amount"`
+	PaidAmount float64           `json:"paid_amount"`
+	InvoiceURL string            `json:"invoice_url"`
+	Metadata   map[string]string `json:"metadata"`
+	ErrorCode  string            `json:"error_code"`
+	Message    string            `json:"message"`
+}
+
+func (inv xenditInvoice) event() Event {
+	ev := Event{OrderID: inv.ExternalID, Amount: int(inv.Amount), Metadata: inv.Metadata}
+	if inv.PaidAmount > 0 {
+		ev.Amount = int(inv.PaidAmount)
+	}
+	if ev.Metadata == nil {
+		ev.Metadata = map[string]string{}
+	}
+	switch strings.ToUpper(inv.Status) {
+	case "PAID", "SETTLED":
+		ev.Status = StatusSettlement
+	case "EXPIRED":
+		ev.Status = StatusExpire
+	default:
+		ev.Status = StatusPending
+	}
+	return ev
+}
+
+// CreateTransaction creates an invoice and returns its hosted invoice_url.
+func (x *Xendit) CreateTransaction(ctx context.Context, req CreateRequest) (Transaction, error) {
+	payload := map[string]interface{}{
+		"external_id": req.OrderID,
+		"amount":      req.Amount,
+		"description": req.Description,
+		"currency":    "IDR",
+		"customer": map[string]interface{}{
+			"given_names": req.Customer.Name,
+			"email":       req.Customer.Email,
+		},
+		"metadata": req.Metadata,
+	}
+	if req.Customer.Email != "" {
+		payload["payer_email"] = req.Customer.Email
+	}
+	var inv xenditInvoice
+	if err := x.do(ctx, http.MethodPost, "/v2/invoices", payload, &inv); err != nil {
+		return Transaction{}, err
+	}
+	if inv.InvoiceURL == "" {
+		return Transaction{}, fmt.Errorf("xendit: create invoice: %s %s", inv.ErrorCode, inv.Message)
+	}
+	return Transaction{OrderID: req.OrderID, Token: inv.ID, RedirectURL: inv.InvoiceURL}, nil
+}
+
+// ParseNotification verifies the x-callback-token header and parses an invoice callback.
+func (x *Xendit) ParseNotification(body []byte, header http.Header) (Event, error) {
+	got := header.Get("X-Callback-Token")
+	if x.CallbackToken == "" || subtle.ConstantTimeCompare([]byte(got), []byte(x.CallbackToken)) != 1 {
+		return Event{}, ErrInvalidSignature
+	}
+	var inv xenditInvoice
+	if err := json.Unmarshal(body, &inv); err != nil {
+		return Event{}, fmt.Errorf("xendit: decode notification: %w", err)
+	}
+	return inv.event(), nil
+}
+
+func (x *Xendit) findInvoice(ctx context.Context, orderID string) (xenditInvoice, error) {
+	var list []xenditInvoice
+	if err := x.do(ctx, http.MethodGet, "/v2/invoices?external_id="+url.QueryEscape(orderID), nil, &list); err != nil {
+		return xenditInvoice{}, err
+	}
+	if len(list) == 0 {
+		return xenditInvoice{}, ErrNotFound
+	}
+	return list[0], nil
+}
+
+// Status implements Gateway by looking the invoice up by external_id.
+func (x *Xendit) Status(ctx context.Context, orderID string) (Event, error) {
+	inv, err := x.findInvoice(ctx, orderID)
+	if err != nil {
+		return Event{}, err
+	}
+	return inv.event(), nil
+}
+
+// Refund implements Gateway using POST /refunds for the paid invoice.
+func (x *Xendit) Refund(ctx context.Context, orderID string, amount int, reason string) error {
+	inv, err := x.findInvoice(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		amount = int(inv.PaidAmount)
+	}
+	payload := map[string]interface{}{
+		"invoice_id": inv.ID,
+		"amount":     amount,
+		"reason":     reason,
+	}
+	var resp struct {
+		ID        string `json:"id"`
+		ErrorCode string `json:"error_code"`
+		Message   string `json:"message"`
+	}
+	if err := x.do(ctx, http.MethodPost, "/refunds", payload, &resp); err != nil {
+		return err
+	}
+	if resp.ID == "" {
+		return fmt.Errorf("xendit: refund: %s %s", resp.ErrorCode, resp.Message)
+	}
+	return nil
+}
+
+func (x *Xendit) do(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var body []byte
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+	req, err := http.NewRequestWithContext(ctx, method, x.APIBaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(x.SecretKey, "")
+	resp, err := httpClient(x.HTTPClient).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("xendit: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("xendit: decode response: %w", err)
+	}
+	return nil
+}
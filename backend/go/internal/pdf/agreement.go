@@ -52,7 +52,7 @@ type AgreementData struct {
 
 	// Revisi & tenggat
 	RevisiPutaran     string `json:"revisi_putaran"`
-	RevisiHari        string `json:"revisi_hari"`
+	RevisiHari        string `json:"revisi_hari"` // kosong = tanpa batas waktu klaim revisi
 	KonfirmasiHari    string `json:"konfirmasi_hari"`
 	SerahTerimaHari   string `json:"serah_terima_hari"`
 	TanggungJawabHari string `json:"tanggung_jawab_hari"`
@@ -263,7 +263,11 @@ func GenerateAgreementFull(data *AgreementData) ([]byte, error) {
 	// --- PASAL 4: REVISI DAN PERUBAHAN ---
 	h.writeBold("PASAL 4 - REVISI DAN PERUBAHAN")
 	h.write(fmt.Sprintf("4.1. Pihak Pertama menyediakan %s putaran revisi yang wajar (minor) sesuai ruang lingkup yang disepakati. Revisi dimaksud tidak termasuk perubahan mendasar konsep atau penambahan fitur baru di luar scope awal.", data.RevisiPutaran))
-	h.write(fmt.Sprintf("4.2. Permintaan revisi disampaikan secara tertulis (e-mail/chat resmi) dalam waktu %s hari setelah penyerahan draft/hasil kerja. Revisi di luar batas putaran atau di luar batas waktu dapat dikenakan biaya tambahan berdasarkan kesepakatan tertulis.", data.RevisiHari))
+	if data.RevisiHari != "" {
+		h.write(fmt.Sprintf("4.2. Permintaan revisi disampaikan secara tertulis (e-mail/chat resmi) dalam waktu %s hari setelah penyerahan draft/hasil kerja. Revisi di luar batas putaran atau di luar batas waktu dapat dikenakan biaya tambahan berdasarkan kesepakatan tertulis.", data.RevisiHari))
+	} else {
+		h.write("4.2. Permintaan revisi disampaikan secara tertulis (e-mail/chat resmi) setelah penyerahan draft/hasil kerja. Revisi di luar batas putaran dapat dikenakan biaya tambahan berdasarkan kesepakatan tertulis.")
+	}
 	h.write("4.3. Perubahan besar (perluasan scope, tambahan fitur, perubahan fundamental) hanya berlaku setelah disetujui tertulis dan apabila ada penyesuaian nilai dan/atau jadwal.")
 	p.Ln(4)
 
@@ -448,7 +452,11 @@ func GenerateAgreementLite(data *AgreementData) ([]byte, error) {
 	// --- PASAL 3: REVISI ---
 	h.writeBold("PASAL 3 - REVISI")
 	h.write(fmt.Sprintf("3.1. Pihak Pertama menyediakan %s putaran revisi minor sesuai scope. Revisi di luar putaran dapat dikenakan biaya tambahan.", data.RevisiPutaran))
-	h.write(fmt.Sprintf("3.2. Permintaan revisi disampaikan secara tertulis dalam waktu %s hari setelah penyerahan draft.", data.RevisiHari))
+	if data.RevisiHari != "" {
+		h.write(fmt.Sprintf("3.2. Permintaan revisi disampaikan secara tertulis dalam waktu %s hari setelah penyerahan draft.", data.RevisiHari))
+	} else {
+		h.write("3.2. Permintaan revisi disampaikan secara tertulis setelah penyerahan draft.")
+	}
 	p.Ln(4)
 
 	// --- PASAL 4: HAK KEKAYAAN INTELEKTUAL ---
@@ -0,0 +1,146 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// QuotationLine is one row of the quotation table. Semua sudah dalam bentuk teks.
+type QuotationLine struct {
+	Deskripsi string
+	Qty       string
+	Harga     string // harga satuan, e.g. "Rp 500.000"
+	Diskon    string // e.g. "10%"; kosong = tanpa diskon
+	Subtotal  string
+}
+
+// QuotationData holds the fields of a price quotation (penawaran harga).
+type QuotationData struct {
+	Nomor         string
+	Tanggal       string
+	BerlakuSampai string
+	KepadaNama    string
+	KepadaEmail   string
+	Perihal       string // e.g. nama layanan
+	Lines         []QuotationLine
+	Bruto         string
+	Diskon        string // kosong = tidak ada diskon
+	Total         string
+	Terbilang     string
+	Pembayaran    []string // tahapan pembayaran, e.g. "Uang muka (DP) 50%: Rp 1.000.000"
+	Catatan       string
+	Penyedia      string // nama penyedia jasa di tanda tangan
+}
+
+// GenerateQuotationPDF renders the quotation with its line-item table.
+func GenerateQuotationPDF(data *QuotationData) ([]byte, error) {
+	if data == nil {
+		data = &QuotationData{}
+	}
+	p, h := newPDFDoc()
+
+	writeTitle(p, "PENAWARAN HARGA", "Quotation", data.Nomor)
+	p.Ln(2)
+	h.writeLabelVal("Tanggal", data.Tanggal)
+	h.writeLabelVal("Kepada", data.KepadaNama)
+	if data.KepadaEmail != "" {
+		h.writeLabelVal("E-mail", data.KepadaEmail)
+	}
+	if data.Perihal != "" {
+		h.writeLabelVal("Perihal", data.Perihal)
+	}
+	h.writeLabelVal("Berlaku sampai", data.BerlakuSampai)
+	p.Ln(4)
+	h.write("Dengan hormat, bersama ini kami sampaikan penawaran harga untuk pekerjaan berikut:")
+	p.Ln(2)
+
+	widths := []float64{10, 62, 12, 30, 16, 40}
+	p.SetFont("Helvetica", "B", 9)
+	for i, head := range []string{"No", "Deskripsi", "Qty", "Harga satuan", "Diskon", "Subtotal"} {
+		ln := 0
+		if i == len(widths)-1 {
+			ln = 1
+		}
+		p.CellFormat(widths[i], 7, head, "1", ln, "C", false, 0, "")
+	}
+	p.SetFont("Helvetica", "", 9)
+	const lineH = 5.0
+	for i, l := range data.Lines {
+		desc := p.SplitLines([]byte(clean(l.Deskripsi)), widths[1]-2)
+		rowH := lineH * float64(len(desc))
+		if rowH < 7 {
+			rowH = 7
+		}
+		if p.GetY()+rowH > 280 {
+			p.AddPage()
+		}
+		x, y := p.GetX(), p.GetY()
+		cells := []struct {
+			text  string
+			align string
+		}{{strconv.Itoa(i + 1), "C"}, {"", "L"}, {l.Qty, "C"}, {l.Harga, "R"}, {l.Diskon, "C"}, {l.Subtotal, "R"}}
+		cx := x
+		for j, c := range cells {
+			p.Rect(cx, y, widths[j], rowH, "D")
+			if j == 1 {
+				for k, part := range desc {
+					p.SetXY(cx+1, y+float64(k)*lineH+(rowH-lineH*float64(len(desc)))/2)
+					p.CellFormat(widths[j]-2, lineH, string(part), "", 0, "L", false, 0, "")
+				}
+			} else {
+				p.SetXY(cx+1, y)
+				p.CellFormat(widths[j]-2, rowH, c.text, "", 0, c.align, false, 0, "")
+			}
+			cx += widths[j]
+		}
+		p.SetXY(x, y+rowH)
+	}
+	labelW := widths[0] + widths[1] + widths[2] + widths[3] + widths[4]
+	total := func(label, value string, bold bool) {
+		if bold {
+			p.SetFont("Helvetica", "B", 9)
+		}
+		p.CellFormat(labelW, 7, label, "1", 0, "R", false, 0, "")
+		p.CellFormat(widths[5], 7, value, "1", 1, "R", false, 0, "")
+		p.SetFont("Helvetica", "", 9)
+	}
+	if data.Diskon != "" {
+		total("Jumlah", data.Bruto, false)
+		total("Diskon", "- "+data.Diskon, false)
+	}
+	total("TOTAL", data.Total, true)
+	p.SetFont("Helvetica", "I", 9)
+	if data.Terbilang != "" {
+		p.MultiCell(0, 6, "Terbilang: "+clean(data.Terbilang), "", "L", false)
+	}
+	p.SetFont("Helvetica", "", 10)
+	p.Ln(3)
+
+	if len(data.Pembayaran) > 0 {
+		h.writeBold("Ketentuan pembayaran")
+		for _, line := range data.Pembayaran {
+			h.write("- " + line)
+		}
+		p.Ln(2)
+	}
+	if data.Catatan != "" {
+		h.writeBold("Catatan")
+		h.write(data.Catatan)
+		p.Ln(2)
+	}
+	h.write("Penawaran ini berlaku sampai tanggal " + data.BerlakuSampai + ". Setelah penawaran disetujui, kami akan mengirimkan surat perjanjian kerja untuk ditandatangani.")
+	p.Ln(8)
+	h.write("Hormat kami,")
+	p.Ln(14)
+	penyedia := data.Penyedia
+	if penyedia == "" {
+		penyedia = "Rasya Production"
+	}
+	h.writeBold(penyedia)
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
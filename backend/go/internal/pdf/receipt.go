@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"bytes"
+)
+
+// DonationReceiptData holds the fields of a donation receipt (kuitansi donasi). Semua sudah dalam bentuk teks.
+type DonationReceiptData struct {
+	Nomor    string // nomor kuitansi (ID donasi)
+	Tanggal  string
+	Nama     string
+	Email    string
+	Jumlah   string // e.g. "Rp 50.000"
+	Metode   string
+	Campaign string // kosong = donasi umum
+}
+
+// GenerateDonationReceiptPDF renders a one-page donation receipt.
+func GenerateDonationReceiptPDF(data *DonationReceiptData) ([]byte, error) {
+	if data == nil {
+		data = &DonationReceiptData{}
+	}
+	p, h := newPDFDoc()
+
+	writeTitle(p, "KUITANSI DONASI", "Donation Receipt", data.Nomor)
+	p.Ln(4)
+	nama := data.Nama
+	if nama == "" {
+		nama = "Donatur"
+	}
+	h.writeLabelVal("Telah diterima dari", nama)
+	if data.Email != "" {
+		h.writeLabelVal("Email", data.Email)
+	}
+	h.writeLabelVal("Jumlah", data.Jumlah)
+	h.writeLabelVal("Metode pembayaran", data.Metode)
+	if data.Campaign != "" {
+		h.writeLabelVal("Untuk campaign", data.Campaign)
+	}
+	h.writeLabelVal("Tanggal", data.Tanggal)
+	p.Ln(8)
+	h.write("Terima kasih atas dukungan Anda. Kuitansi ini dibuat otomatis oleh sistem dan sah tanpa tanda tangan.")
+	p.Ln(4)
+	h.write("Rasya Production")
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,162 @@
+package pdf
+
+import (
+	"bytes"
+	"image/color"
+	"strconv"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf/v2"
+)
+
+// RevisionVoucher is one revision ticket on the voucher sheet. Semua sudah dalam bentuk teks.
+type RevisionVoucher struct {
+	Kode          string // e.g. "RV-AB12CD34"
+	Ke            int    // revisi ke-N
+	BerlakuSampai string // kosong = masa klaim belum dimulai (hasil belum diserahkan)
+	URL           string // tautan klaim dengan kode terisi; dijadikan QR code
+}
+
+// RevisionVoucherData holds the fields of a revision voucher sheet (lembar tiket revisi) for one order.
+type RevisionVoucherData struct {
+	Nomor     string // ID order
+	Tanggal   string
+	Pemesan   string
+	Layanan   string
+	URL       string // halaman klaim, e.g. https://.../revisi
+	MasaKlaim string // e.g. "7 (tujuh) hari"; kosong = tanpa batas waktu
+	Vouchers  []RevisionVoucher
+}
+
+const (
+	voucherW   = 82.0
+	voucherH   = 50.0
+	voucherGap = 6.0
+	voucherQR  = 30.0
+)
+
+// GenerateRevisionVoucherPDF renders the voucher sheet: dua tiket per baris, masing-masing dengan kode dan QR code.
+func GenerateRevisionVoucherPDF(data *RevisionVoucherData) ([]byte, error) {
+	if data == nil {
+		data = &RevisionVoucherData{}
+	}
+	p, h := newPDFDoc()
+
+	writeTitle(p, "TIKET REVISI", "Revision Voucher", data.Nomor)
+	p.Ln(2)
+	h.writeLabelVal("Pemesan", data.Pemesan)
+	h.writeLabelVal("Layanan", data.Layanan)
+	h.writeLabelVal("Tanggal", data.Tanggal)
+	p.Ln(4)
+	h.write("Setiap kode berlaku untuk satu putaran revisi. Untuk mengajukan revisi, pindai QR code atau buka " + data.URL + " lalu masukkan kode tiket beserta catatan revisi.")
+	if data.MasaKlaim != "" {
+		h.write("Tiket dapat diklaim paling lambat " + data.MasaKlaim + " setelah hasil pekerjaan diserahkan; tiket yang lewat masa klaim tidak dapat digunakan.")
+	}
+	h.write("Simpan kode ini dan jangan dibagikan kepada pihak lain.")
+	p.Ln(4)
+
+	left, _, right, bottom := p.GetMargins()
+	pageW, pageH := p.GetPageSize()
+	gap := pageW - left - right - 2*voucherW
+	if gap < 0 {
+		gap = voucherGap
+	}
+	y := p.GetY()
+	for i, v := range data.Vouchers {
+		col := i % 2
+		if col == 0 && i > 0 {
+			y += voucherH + voucherGap
+		}
+		if col == 0 && y+voucherH > pageH-bottom {
+			p.AddPage()
+			y = p.GetY()
+		}
+		if err := drawVoucher(p, left+float64(col)*(voucherW+gap), y, v); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := p.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawVoucher draws one ticket card with its top-left corner at (x, y).
+func drawVoucher(p *gofpdf.Fpdf, x, y float64, v RevisionVoucher) error {
+	p.SetDrawColor(120, 120, 120)
+	p.SetDashPattern([]float64{1.5, 1}, 0)
+	p.RoundedRect(x, y, voucherW, voucherH, 3, "1234", "D")
+	p.SetDashPattern([]float64{}, 0)
+	p.SetDrawColor(0, 0, 0)
+
+	textW := voucherW - voucherQR - 10
+	p.SetXY(x+4, y+4)
+	p.SetFont("Helvetica", "B", 8)
+	p.CellFormat(textW, 4, "RASYA PRODUCTION", "", 2, "L", false, 0, "")
+	p.SetFont("Helvetica", "", 8)
+	p.CellFormat(textW, 4, "Tiket Revisi", "", 2, "L", false, 0, "")
+	p.Ln(4)
+	p.SetX(x + 4)
+	p.SetFont("Helvetica", "B", 11)
+	p.CellFormat(textW, 6, "Revisi ke-"+strconv.Itoa(v.Ke), "", 2, "L", false, 0, "")
+	p.SetFont("Courier", "B", 12)
+	p.CellFormat(textW, 7, clean(v.Kode), "", 2, "L", false, 0, "")
+	p.Ln(2)
+	p.SetX(x + 4)
+	p.SetFont("Helvetica", "", 7)
+	if v.BerlakuSampai != "" {
+		p.MultiCell(textW, 3.5, "Berlaku sampai "+clean(v.BerlakuSampai), "", "L", false)
+	} else {
+		p.MultiCell(textW, 3.5, "Masa klaim dimulai saat hasil pekerjaan diserahkan", "", "L", false)
+	}
+	p.SetFont("Helvetica", "", 10)
+
+	if v.URL == "" {
+		return nil
+	}
+	return drawQR(p, x+voucherW-voucherQR-5, y+(voucherH-voucherQR)/2, voucherQR, v.URL)
+}
+
+// drawQR draws content as a QR code of size x size mm (vektor, tetap tajam saat dicetak).
+func drawQR(p *gofpdf.Fpdf, x, y, size float64, content string) error {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return err
+	}
+	b := code.Bounds()
+	n := b.Dx()
+	if n == 0 {
+		return nil
+	}
+	module := size / float64(n)
+	// Sedikit tumpang tindih antarbaris agar tidak muncul garis putih tipis di penampil PDF.
+	rowH := module + 0.05
+	p.SetFillColor(0, 0, 0)
+	for row := 0; row < n; row++ {
+		if row == n-1 {
+			rowH = module
+		}
+		// Modul gelap yang berurutan dalam satu baris digambar sebagai satu kotak.
+		for col := 0; col < n; {
+			if !dark(code.At(b.Min.X+col, b.Min.Y+row)) {
+				col++
+				continue
+			}
+			start := col
+			for col < n && dark(code.At(b.Min.X+col, b.Min.Y+row)) {
+				col++
+			}
+			p.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, rowH, "F")
+		}
+	}
+	p.SetFillColor(255, 255, 255)
+	return nil
+}
+
+// dark reports whether a QR module is black.
+func dark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
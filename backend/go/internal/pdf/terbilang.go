@@ -0,0 +1,40 @@
+package pdf
+
+import "strings"
+
+var satuan = [...]string{"", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan", "sepuluh", "sebelas"}
+
+// Terbilang spells n in Indonesian words, e.g. 1500000 -> "satu juta lima ratus ribu" (untuk nilai proyek di perjanjian).
+func Terbilang(n int64) string {
+	if n == 0 {
+		return "nol"
+	}
+	if n < 0 {
+		return "minus " + Terbilang(-n)
+	}
+	return strings.Join(strings.Fields(terbilang(n)), " ")
+}
+
+func terbilang(n int64) string {
+	switch {
+	case n < 12:
+		return satuan[n]
+	case n < 20:
+		return satuan[n-10] + " belas"
+	case n < 100:
+		return satuan[n/10] + " puluh " + terbilang(n%10)
+	case n < 200:
+		return "seratus " + terbilang(n-100)
+	case n < 1000:
+		return satuan[n/100] + " ratus " + terbilang(n%100)
+	case n < 2000:
+		return "seribu " + terbilang(n-1000)
+	case n < 1_000_000:
+		return terbilang(n/1000) + " ribu " + terbilang(n%1000)
+	case n < 1_000_000_000:
+		return terbilang(n/1_000_000) + " juta " + terbilang(n%1_000_000)
+	case n < 1_000_000_000_000:
+		return terbilang(n/1_000_000_000) + " miliar " + terbilang(n%1_000_000_000)
+	}
+	return terbilang(n/1_000_000_000_000) + " triliun " + terbilang(n%1_000_000_000_000)
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Campaign is one donation campaign (galang dana dengan target).
+type Campaign struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	TargetAmount  int        `json:"target_amount"` // IDR
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       *time.Time `json:"end_date,omitempty"` // nil = tanpa batas akhir
+	CoverImageURL string     `json:"cover_image_url"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsOpen reports whether the campaign accepts donations at t (tanggal akhir ikut dihitung).
+func (c Campaign) IsOpen(t time.Time) bool {
+	if t.Before(c.StartDate) {
+		return false
+	}
+	return c.EndDate == nil || t.Before(c.EndDate.AddDate(0, 0, 1))
+}
+
+// CampaignStore holds campaigns in memory or PostgreSQL.
+type CampaignStore struct {
+	mu    sync.RWMutex
+	items []Campaign
+	pool  *pgxpool.Pool
+}
+
+// NewCampaignStore returns a new in-memory campaign store.
+func NewCampaignStore() *CampaignStore {
+	return &CampaignStore{items: make([]Campaign, 0)}
+}
+
+// NewCampaignStoreFromDB returns a campaign store backed by PostgreSQL.
+func NewCampaignStoreFromDB(pool *pgxpool.Pool) *CampaignStore {
+	return &CampaignStore{pool: pool}
+}
+
+const campaignColumns = `id, title, description, target_amount, start_date, end_date, cover_image_url, created_at`
+
+// Add saves a new campaign and returns it with ID.
+func (s *CampaignStore) Add(c Campaign) Campaign {
+	c.ID = generateID()
+	c.CreatedAt = time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO campaigns (`+campaignColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+			c.ID, c.Title, c.Description, c.TargetAmount, c.StartDate, c.EndDate, c.CoverImageURL, c.CreatedAt)
+		if err != nil {
+			return Campaign{}
+		}
+		return c
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, c)
+	return c
+}
+
+// Update overwrites a campaign's editable fields. coverImageURL "" keeps the current cover.
+func (s *CampaignStore) Update(c Campaign) (Campaign, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE campaigns SET title = $2, description = $3, target_amount = $4, start_date = $5, end_date = $6,
+			cover_image_url = CASE WHEN $7 <> '' THEN $7 ELSE cover_image_url END WHERE id = $1`,
+			c.ID, c.Title, c.Description, c.TargetAmount, c.StartDate, c.EndDate, c.CoverImageURL)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Campaign{}, false
+		}
+		return s.Get(c.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == c.ID {
+			if c.CoverImageURL == "" {
+				c.CoverImageURL = s.items[i].CoverImageURL
+			}
+			c.CreatedAt = s.items[i].CreatedAt
+			s.items[i] = c
+			return c, true
+		}
+	}
+	return Campaign{}, false
+}
+
+// Get returns a campaign by ID.
+func (s *CampaignStore) Get(id string) (Campaign, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+campaignColumns+` FROM campaigns WHERE id = $1`, id)
+		if len(list) == 0 {
+			return Campaign{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.items {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Campaign{}, false
+}
+
+// List returns all campaigns, newest start date first.
+func (s *CampaignStore) List() []Campaign {
+	if s.pool != nil {
+		return s.queryDB(`SELECT ` + campaignColumns + ` FROM campaigns ORDER BY start_date DESC, created_at DESC`)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Campaign, len(s.items))
+	copy(out, s.items)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Delete removes a campaign by ID (donasi yang sudah masuk tetap tersimpan).
+func (s *CampaignStore) Delete(id string) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `DELETE FROM campaigns WHERE id = $1`, id)
+		if err != nil {
+			return false
+		}
+		return ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.items {
+		if c.ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CampaignStore) queryDB(q string, args ...any) []Campaign {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []Campaign
+	for rows.Next() {
+		var c Campaign
+		if err := rows.Scan(&c.ID, &c.Title, &c.Description, &c.TargetAmount, &c.StartDate, &c.EndDate, &c.CoverImageURL, &c.CreatedAt); err != nil {
+			return out
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a calendar date without time of day, JSON "2006-01-02".
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the calendar date of t (zona waktu t diabaikan, disimpan sebagai UTC tengah malam).
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf converts a nullable DB value to *Date.
+func DateOf(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	d := NewDate(*t)
+	return &d
+}
+
+// Ptr returns the date as *time.Time for DB writes (nil bila tanggal kosong).
+func (d *Date) Ptr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d Date) String() string {
+	return d.Format("2006-01-02")
+}
+
+// MarshalJSON encodes the date as "YYYY-MM-DD".
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts any format understood by ParseDate.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, ok := ParseDate(s)
+	if !ok {
+		return errors.New("invalid date: " + s)
+	}
+	*d = parsed
+	return nil
+}
+
+var idMonthNames = map[string]string{
+	"januari": "01", "jan": "01",
+	"februari": "02", "feb": "02", "pebruari": "02",
+	"maret": "03", "mar": "03",
+	"april": "04", "apr": "04",
+	"mei":  "05",
+	"juni": "06", "jun": "06",
+	"juli": "07", "jul": "07",
+	"agustus": "08", "agu": "08", "agt": "08", "ags": "08", "aug": "08",
+	"september": "09", "sep": "09", "sept": "09",
+	"oktober": "10", "okt": "10", "oct": "10",
+	"november": "11", "nov": "11", "nopember": "11",
+	"desember": "12", "des": "12", "dec": "12",
+}
+
+var dateLayouts = []string{"2006-01-02", "2-1-2006", "2/1/2006", "2.1.2006", "2 1 2006", "2006/1/2"}
+
+// ParseDate parses the date formats found in old orders: 2026-03-12, 12/03/2026, 12-03-2026,
+// "12 Maret 2026", "12 Mar 2026" (nama bulan Indonesia/Inggris). Tanggal dengan jam (RFC 3339) juga diterima.
+func ParseDate(s string) (Date, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return NewDate(t), true
+	}
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " ")))
+	for i, w := range words {
+		if m, ok := idMonthNames[strings.TrimSuffix(w, ".")]; ok {
+			words[i] = m
+		}
+	}
+	norm := strings.Join(words, " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, norm); err == nil {
+			return NewDate(t), true
+		}
+	}
+	return Date{}, false
+}
+
+// ParseRupiah parses an IDR amount such as "Rp 1.500.000", "1500000", "Rp1.500.000,00", "1,5 jt", "750rb" or "2 juta".
+// Teks lain (mis. "DP 50% dari 2jt") ditolak.
+func ParseRupiah(s string) (int64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "idr")
+	s = strings.TrimPrefix(s, "rp.")
+	s = strings.TrimPrefix(s, "rp")
+	s = strings.TrimSuffix(strings.ReplaceAll(s, " ", ""), ",-")
+	s = strings.TrimSuffix(s, ".-")
+	multiplier := 1.0
+	for _, suf := range []struct {
+		text string
+		mult float64
+	}{{"juta", 1e6}, {"jt", 1e6}, {"ribu", 1e3}, {"rb", 1e3}, {"k", 1e3}} {
+		if strings.HasSuffix(s, suf.text) {
+			s, multiplier = strings.TrimSuffix(s, suf.text), suf.mult
+			break
+		}
+	}
+	if s == "" {
+		return 0, false
+	}
+	if multiplier > 1 {
+		// "1,5 jt" / "1.5jt": koma atau titik adalah desimal.
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+		if err != nil || f < 0 {
+			return 0, false
+		}
+		return int64(math.Round(f * multiplier)), true
+	}
+	// Tanpa satuan: titik pemisah ribuan, ",00" desimal.
+	if i := strings.LastIndex(s, ","); i >= 0 && len(s)-i == 3 {
+		s = s[:i]
+	}
+	s = strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", "")
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
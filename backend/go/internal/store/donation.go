@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,14 +11,115 @@ import (
 
 // Donation represents a single donation (and optional contact/review).
 type Donation struct {
-	ID          string    `json:"id"`
-	OrderID     string    `json:"order_id,omitempty"` // Midtrans order_id (untuk idempotency webhook)
-	Amount      int       `json:"amount"`           // IDR
-	Comment     string    `json:"comment"`
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	Highlighted bool      `json:"highlighted"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID              string     `json:"id"`
+	OrderID         string     `json:"order_id,omitempty"` // Midtrans order_id (untuk idempotency webhook)
+	Amount          int        `json:"amount"`             // IDR
+	Comment         string     `json:"comment"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Highlighted     bool       `json:"highlighted"`
+	Status          string     `json:"status"` // Midtrans: pending, settlement, expire, ...; transfer bank: awaiting_transfer → awaiting_confirmation → confirmed/rejected
+	ProofPath       string     `json:"-"`      // bukti transfer bank (folder privat, hanya bisa dibuka admin)
+	HasProof        bool       `json:"has_proof"`
+	ProofUploadedAt *time.Time `json:"proof_uploaded_at,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"` // kapan admin konfirmasi/tolak
+	AdminNote       string     `json:"admin_note,omitempty"`
+	CampaignID      string     `json:"campaign_id,omitempty"`
+	SubscriptionID  string     `json:"subscription_id,omitempty"`
+	Anonymous       bool       `json:"anonymous"`       // donatur minta nama tidak ditampilkan sama sekali
+	ConsentPublish  bool       `json:"consent_publish"` // donatur setuju nama & pesan tampil publik
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Midtrans transaction_status values yang dipakai di donasi.
+const (
+	DonationStatusPending    = "pending"
+	DonationStatusSettlement = "settlement"
+	DonationStatusCapture    = "capture"
+	DonationStatusExpire     = "expire"
+)
+
+// Status donasi transfer bank manual.
+const (
+	DonationStatusAwaitingTransfer     = "awaiting_transfer"     // form dikirim, belum ada bukti
+	DonationStatusAwaitingConfirmation = "awaiting_confirmation" // bukti diunggah, menunggu admin
+	DonationStatusConfirmed            = "confirmed"
+	DonationStatusRejected             = "rejected"
+)
+
+// IsFinal reports whether the Midtrans status will not change anymore (tidak perlu direkonsiliasi).
+func (d Donation) IsFinal() bool {
+	switch d.Status {
+	case DonationStatusPending, "authorize":
+		return false
+	}
+	return true
+}
+
+// IsPaid reports whether the donation counts as received (settlement/capture, atau transfer bank yang sudah dikonfirmasi admin).
+func (d Donation) IsPaid() bool {
+	switch d.Status {
+	case DonationStatusSettlement, DonationStatusCapture, DonationStatusConfirmed:
+		return true
+	}
+	return false
+}
+
+// PublicName returns the donor name for public outputs: "Anonim" bila anonymous,
+// nama disamarkan bila belum ada persetujuan publikasi, nama asli bila disetujui.
+func (d Donation) PublicName() string {
+	name := strings.TrimSpace(d.Name)
+	if d.Anonymous || name == "" {
+		return "Anonim"
+	}
+	if !d.ConsentPublish {
+		return MaskName(name)
+	}
+	return name
+}
+
+// PublicComment returns the comment only if the donor consented to publication.
+func (d Donation) PublicComment() string {
+	if d.Anonymous || !d.ConsentPublish {
+		return ""
+	}
+	return d.Comment
+}
+
+// MaskName masks each word of name keeping its first letter, e.g. "Budi Santoso" → "B*** S***".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(r[0]) + "***"
+	}
+	if len(words) == 0 {
+		return "Anonim"
+	}
+	return strings.Join(words, " ")
+}
+
+// paidStatusSQL is the SQL condition equivalent of Donation.IsPaid.
+const paidStatusSQL = `status IN ('settlement', 'capture', 'confirmed')`
+
+const donationColumns = `id, order_id, amount, comment, name, email, highlighted, status, proof_path, proof_uploaded_at, reviewed_at, admin_note, campaign_id, anonymous, consent_publish, subscription_id, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDonation(row rowScanner) (Donation, error) {
+	var d Donation
+	var orderIDNull *string
+	if err := row.Scan(&d.ID, &orderIDNull, &d.Amount, &d.Comment, &d.Name, &d.Email, &d.Highlighted, &d.Status,
+		&d.ProofPath, &d.ProofUploadedAt, &d.ReviewedAt, &d.AdminNote, &d.CampaignID, &d.Anonymous, &d.ConsentPublish, &d.SubscriptionID, &d.CreatedAt); err != nil {
+		return Donation{}, err
+	}
+	if orderIDNull != nil {
+		d.OrderID = *orderIDNull
+	}
+	d.HasProof = d.ProofPath != ""
+	return d, nil
 }
 
 // Store holds donations in memory or PostgreSQL (when pool is set).
@@ -54,9 +156,9 @@ func (s *Store) addDB(d Donation) Donation {
 	d.ID = generateID()
 	d.CreatedAt = time.Now().UTC()
 	ctx := context.Background()
-	_, err := s.pool.Exec(ctx, `INSERT INTO donations (id, order_id, amount, comment, name, email, highlighted, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
-		d.ID, nullStr(d.OrderID), d.Amount, d.Comment, d.Name, d.Email, d.Highlighted, d.CreatedAt)
+	_, err := s.pool.Exec(ctx, `INSERT INTO donations (id, order_id, amount, comment, name, email, highlighted, status, campaign_id, anonymous, consent_publish, subscription_id, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		d.ID, nullStr(d.OrderID), d.Amount, d.Comment, d.Name, d.Email, d.Highlighted, d.Status, d.CampaignID, d.Anonymous, d.ConsentPublish, d.SubscriptionID, d.CreatedAt)
 	if err != nil {
 		return Donation{}
 	}
@@ -80,59 +182,162 @@ func (s *Store) FindByOrderID(orderID string) (Donation, bool) {
 
 func (s *Store) findByOrderIDDB(orderID string) (Donation, bool) {
 	ctx := context.Background()
-	var d Donation
-	var orderIDNull *string
-	err := s.pool.QueryRow(ctx, `SELECT id, order_id, amount, comment, name, email, highlighted, created_at
-		FROM donations WHERE order_id = $1`, orderID).Scan(
-		&d.ID, &orderIDNull, &d.Amount, &d.Comment, &d.Name, &d.Email, &d.Highlighted, &d.CreatedAt)
+	d, err := scanDonation(s.pool.QueryRow(ctx, `SELECT `+donationColumns+` FROM donations WHERE order_id = $1`, orderID))
 	if err != nil {
 		return Donation{}, false
 	}
-	if orderIDNull != nil {
-		d.OrderID = *orderIDNull
-	}
 	return d, true
 }
 
-// ListHighlighted returns donations that are highlighted (e.g. for email priority).
-func (s *Store) ListHighlighted() []Donation {
+// UpdateStatus sets the Midtrans status of the donation with the given order_id. Returns false if not found.
+func (s *Store) UpdateStatus(orderID, status string) bool {
 	if s.pool != nil {
-		return s.listHighlightedDB()
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE donations SET status = $2 WHERE order_id = $1`, orderID, status)
+		if err != nil {
+			return false
+		}
+		return ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].OrderID == orderID {
+			s.items[i].Status = status
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns a donation by ID.
+func (s *Store) Get(id string) (Donation, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		d, err := scanDonation(s.pool.QueryRow(ctx, `SELECT `+donationColumns+` FROM donations WHERE id = $1`, id))
+		if err != nil {
+			return Donation{}, false
+		}
+		return d, true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, d := range s.items {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Donation{}, false
+}
+
+// SetProof attaches a transfer proof (path relatif di folder privat) and moves the donation to awaiting_confirmation.
+func (s *Store) SetProof(id, proofPath string) (Donation, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		d, err := scanDonation(s.pool.QueryRow(ctx, `UPDATE donations SET proof_path = $2, proof_uploaded_at = $3, status = $4
+			WHERE id = $1 RETURNING `+donationColumns, id, proofPath, now, DonationStatusAwaitingConfirmation))
+		if err != nil {
+			return Donation{}, false
+		}
+		return d, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].ProofPath = proofPath
+			s.items[i].HasProof = true
+			s.items[i].ProofUploadedAt = &now
+			s.items[i].Status = DonationStatusAwaitingConfirmation
+			return s.items[i], true
+		}
+	}
+	return Donation{}, false
+}
+
+// Review sets the admin decision (confirmed/rejected) on a bank-transfer donation. Hanya donasi yang bukti
+// transfernya menunggu konfirmasi (awaiting_confirmation); false bila tidak ditemukan atau status lain.
+func (s *Store) Review(id, status, note string) (Donation, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		d, err := scanDonation(s.pool.QueryRow(ctx, `UPDATE donations SET status = $2, admin_note = $3, reviewed_at = $4
+			WHERE id = $1 AND status = $5 RETURNING `+donationColumns, id, status, note, now, DonationStatusAwaitingConfirmation))
+		if err != nil {
+			return Donation{}, false
+		}
+		return d, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status == DonationStatusAwaitingConfirmation {
+			s.items[i].Status = status
+			s.items[i].AdminNote = note
+			s.items[i].ReviewedAt = &now
+			return s.items[i], true
+		}
+	}
+	return Donation{}, false
+}
+
+// ListUnsettled returns Midtrans donations whose status is not final yet (untuk rekonsiliasi), oldest first.
+func (s *Store) ListUnsettled() []Donation {
+	if s.pool != nil {
+		return s.queryDB(`SELECT ` + donationColumns + ` FROM donations
+			WHERE order_id IS NOT NULL AND status IN ('pending', 'authorize') ORDER BY created_at`)
 	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	var out []Donation
-	for i := len(s.items) - 1; i >= 0; i-- {
-		if s.items[i].Highlighted {
-			out = append(out, s.items[i])
+	for _, d := range s.items {
+		if d.OrderID != "" && !d.IsFinal() {
+			out = append(out, d)
 		}
 	}
 	return out
 }
 
-func (s *Store) listHighlightedDB() []Donation {
+func (s *Store) queryDB(q string, args ...any) []Donation {
 	ctx := context.Background()
-	rows, err := s.pool.Query(ctx, `SELECT id, order_id, amount, comment, name, email, highlighted, created_at
-		FROM donations WHERE highlighted = true ORDER BY created_at DESC`)
+	rows, err := s.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil
 	}
 	defer rows.Close()
 	var out []Donation
 	for rows.Next() {
-		var d Donation
-		var orderIDNull *string
-		if err := rows.Scan(&d.ID, &orderIDNull, &d.Amount, &d.Comment, &d.Name, &d.Email, &d.Highlighted, &d.CreatedAt); err != nil {
+		d, err := scanDonation(rows)
+		if err != nil {
 			return out
 		}
-		if orderIDNull != nil {
-			d.OrderID = *orderIDNull
-		}
 		out = append(out, d)
 	}
 	return out
 }
 
+// ListHighlighted returns donations that are highlighted (e.g. for email priority).
+func (s *Store) ListHighlighted() []Donation {
+	if s.pool != nil {
+		return s.listHighlightedDB()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Donation
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if s.items[i].Highlighted && s.items[i].IsPaid() {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+func (s *Store) listHighlightedDB() []Donation {
+	return s.queryDB(`SELECT ` + donationColumns + ` FROM donations
+		WHERE highlighted = true AND ` + paidStatusSQL + ` ORDER BY created_at DESC`)
+}
+
 // ListAll returns all donations (for admin only).
 func (s *Store) ListAll() []Donation {
 	if s.pool != nil {
@@ -149,64 +354,149 @@ func (s *Store) ListAll() []Donation {
 }
 
 func (s *Store) listAllDB() []Donation {
-	ctx := context.Background()
-	rows, err := s.pool.Query(ctx, `SELECT id, order_id, amount, comment, name, email, highlighted, created_at
-		FROM donations ORDER BY created_at DESC`)
-	if err != nil {
-		return nil
+	return s.queryDB(`SELECT ` + donationColumns + ` FROM donations ORDER BY created_at DESC`)
+}
+
+// ListPaid returns paid donations, newest first, at most limit (limit <= 0 = semua).
+func (s *Store) ListPaid(limit int) []Donation {
+	if s.pool != nil {
+		if limit <= 0 {
+			return s.queryDB(`SELECT ` + donationColumns + ` FROM donations WHERE ` + paidStatusSQL + ` ORDER BY created_at DESC`)
+		}
+		return s.queryDB(`SELECT `+donationColumns+` FROM donations WHERE `+paidStatusSQL+` ORDER BY created_at DESC LIMIT $1`, limit)
 	}
-	defer rows.Close()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var out []Donation
-	for rows.Next() {
-		var d Donation
-		var orderIDNull *string
-		if err := rows.Scan(&d.ID, &orderIDNull, &d.Amount, &d.Comment, &d.Name, &d.Email, &d.Highlighted, &d.CreatedAt); err != nil {
-			return out
-		}
-		if orderIDNull != nil {
-			d.OrderID = *orderIDNull
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if s.items[i].IsPaid() {
+			out = append(out, s.items[i])
+			if len(out) == limit {
+				break
+			}
 		}
-		out = append(out, d)
 	}
 	return out
 }
 
-// ListReviews returns donations below threshold (for public ulasan), with comment.
-func (s *Store) ListReviews() []Donation {
+// ListPaidSince returns paid donations created at or after since (zero time = semua), oldest first.
+func (s *Store) ListPaidSince(since time.Time) []Donation {
 	if s.pool != nil {
-		return s.listReviewsDB()
+		return s.queryDB(`SELECT `+donationColumns+` FROM donations WHERE `+paidStatusSQL+` AND created_at >= $1 ORDER BY created_at`, since)
 	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	var out []Donation
-	for i := len(s.items) - 1; i >= 0; i-- {
-		d := s.items[i]
-		if !d.Highlighted && d.Comment != "" {
+	for _, d := range s.items {
+		if d.IsPaid() && !d.CreatedAt.Before(since) {
 			out = append(out, d)
 		}
 	}
 	return out
 }
 
-func (s *Store) listReviewsDB() []Donation {
-	ctx := context.Background()
-	rows, err := s.pool.Query(ctx, `SELECT id, order_id, amount, comment, name, email, highlighted, created_at
-		FROM donations WHERE highlighted = false AND comment != '' ORDER BY created_at DESC`)
-	if err != nil {
+// ListByEmail returns all donations with the given email (tanpa beda huruf besar/kecil), untuk permintaan subjek data.
+func (s *Store) ListByEmail(email string) []Donation {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
 		return nil
 	}
-	defer rows.Close()
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+donationColumns+` FROM donations WHERE LOWER(TRIM(email)) = $1 ORDER BY created_at DESC`, email)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var out []Donation
-	for rows.Next() {
-		var d Donation
-		var orderIDNull *string
-		if err := rows.Scan(&d.ID, &orderIDNull, &d.Amount, &d.Comment, &d.Name, &d.Email, &d.Highlighted, &d.CreatedAt); err != nil {
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if strings.ToLower(strings.TrimSpace(s.items[i].Email)) == email {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// Anonymize erases the personal data of a donation (nama, email, pesan, bukti transfer) but keeps
+// amount/status for pembukuan. Returns the removed proof path so the caller can delete the file.
+func (s *Store) Anonymize(id string) (proofPath string, ok bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		err := s.pool.QueryRow(ctx, `UPDATE donations d SET name = '', email = '', comment = '', proof_path = '',
+			anonymous = true, consent_publish = false
+			FROM (SELECT id, proof_path FROM donations WHERE id = $1) old WHERE d.id = old.id RETURNING old.proof_path`, id).Scan(&proofPath)
+		if err != nil {
+			return "", false
+		}
+		return proofPath, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			proofPath = s.items[i].ProofPath
+			s.items[i].Name = ""
+			s.items[i].Email = ""
+			s.items[i].Comment = ""
+			s.items[i].ProofPath = ""
+			s.items[i].HasProof = false
+			s.items[i].Anonymous = true
+			s.items[i].ConsentPublish = false
+			return proofPath, true
+		}
+	}
+	return "", false
+}
+
+// CampaignTotal is the paid total of one campaign.
+type CampaignTotal struct {
+	Collected  int `json:"collected"`   // IDR
+	DonorCount int `json:"donor_count"` // donatur unik (email, atau per donasi bila email kosong)
+}
+
+// CampaignTotals returns paid totals per campaign ID (hanya donasi settlement/capture/confirmed).
+func (s *Store) CampaignTotals() map[string]CampaignTotal {
+	out := make(map[string]CampaignTotal)
+	if s.pool != nil {
+		ctx := context.Background()
+		rows, err := s.pool.Query(ctx, `SELECT campaign_id, COALESCE(SUM(amount),0),
+			COUNT(DISTINCT COALESCE(NULLIF(LOWER(TRIM(email)),''), id))
+			FROM donations WHERE campaign_id <> '' AND `+paidStatusSQL+` GROUP BY campaign_id`)
+		if err != nil {
 			return out
 		}
-		if orderIDNull != nil {
-			d.OrderID = *orderIDNull
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			var t CampaignTotal
+			if err := rows.Scan(&id, &t.Collected, &t.DonorCount); err != nil {
+				return out
+			}
+			out[id] = t
 		}
-		out = append(out, d)
+		return out
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	donors := make(map[string]map[string]bool)
+	for _, d := range s.items {
+		if d.CampaignID == "" || !d.IsPaid() {
+			continue
+		}
+		t := out[d.CampaignID]
+		t.Collected += d.Amount
+		out[d.CampaignID] = t
+		key := strings.ToLower(strings.TrimSpace(d.Email))
+		if key == "" {
+			key = d.ID
+		}
+		if donors[d.CampaignID] == nil {
+			donors[d.CampaignID] = make(map[string]bool)
+		}
+		donors[d.CampaignID][key] = true
+	}
+	for id, set := range donors {
+		t := out[id]
+		t.DonorCount = len(set)
+		out[id] = t
 	}
 	return out
 }
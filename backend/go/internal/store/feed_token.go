@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FeedToken is the secret of a subscribable feed (mis. kalender order). Disimpan agar bisa diganti tanpa
+// mengganti JWT_SECRET, yang juga dipakai login admin dan link yang sudah dikirim ke klien.
+type FeedToken struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeedTokenStore holds feed tokens in memory or PostgreSQL.
+type FeedTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]FeedToken
+	pool   *pgxpool.Pool
+}
+
+// NewFeedTokenStore returns a new in-memory store.
+func NewFeedTokenStore() *FeedTokenStore {
+	return &FeedTokenStore{tokens: make(map[string]FeedToken)}
+}
+
+// NewFeedTokenStoreFromDB returns a store backed by PostgreSQL.
+func NewFeedTokenStoreFromDB(pool *pgxpool.Pool) *FeedTokenStore {
+	return &FeedTokenStore{pool: pool}
+}
+
+func generateFeedToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Get returns the token of feed name; false bila belum pernah dibuat.
+func (s *FeedTokenStore) Get(name string) (FeedToken, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		t := FeedToken{Name: name}
+		err := s.pool.QueryRow(ctx, `SELECT token, created_at FROM feed_tokens WHERE name = $1`, name).Scan(&t.Token, &t.CreatedAt)
+		if err != nil {
+			return FeedToken{}, false
+		}
+		return t, true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tokens[name]
+	return t, ok
+}
+
+// Ensure returns the token of feed name, creating it on first call.
+func (s *FeedTokenStore) Ensure(name string) (FeedToken, bool) {
+	t := FeedToken{Name: name, Token: generateFeedToken(), CreatedAt: time.Now().UTC()}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO feed_tokens (name, token, created_at) VALUES ($1,$2,$3) ON CONFLICT (name) DO NOTHING`,
+			t.Name, t.Token, t.CreatedAt)
+		if err != nil {
+			return FeedToken{}, false
+		}
+		return s.Get(name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.tokens[name]; ok {
+		return existing, true
+	}
+	s.tokens[name] = t
+	return t, true
+}
+
+// Rotate replaces the token of feed name with a new one; link lama langsung tidak berlaku.
+func (s *FeedTokenStore) Rotate(name string) (FeedToken, bool) {
+	t := FeedToken{Name: name, Token: generateFeedToken(), CreatedAt: time.Now().UTC()}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO feed_tokens (name, token, created_at) VALUES ($1,$2,$3)
+			ON CONFLICT (name) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at`,
+			t.Name, t.Token, t.CreatedAt)
+		if err != nil {
+			return FeedToken{}, false
+		}
+		return t, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[name] = t
+	return t, true
+}
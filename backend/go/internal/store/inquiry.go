@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Inquiry status.
+const (
+	InquiryStatusNew       = "new"       // baru masuk dari form publik
+	InquiryStatusContacted = "contacted" // sudah dihubungi admin
+	InquiryStatusConverted = "converted" // sudah dijadikan order (lihat OrderID)
+	InquiryStatusRejected  = "rejected"  // spam / tidak dilanjutkan
+)
+
+// ValidInquiryStatus reports whether s is a status admin may set by hand (converted hanya lewat konversi).
+func ValidInquiryStatus(s string) bool {
+	return s == InquiryStatusNew || s == InquiryStatusContacted || s == InquiryStatusRejected
+}
+
+// Inquiry is one order request (permintaan order) from the public site.
+type Inquiry struct {
+	ID         string    `json:"id"`
+	Nama       string    `json:"nama"`
+	Email      string    `json:"email"`
+	WhatsApp   string    `json:"whatsapp"`
+	ServiceIDs []string  `json:"service_ids"`
+	Layanan    []string  `json:"layanan"` // judul layanan saat dikirim (tetap walau layanan diubah/dihapus)
+	Deskripsi  string    `json:"deskripsi"`
+	BudgetMin  int64     `json:"budget_min"` // IDR; 0 = tidak diisi
+	BudgetMax  int64     `json:"budget_max"`
+	Deadline   *Date     `json:"deadline"`
+	Status     string    `json:"status"`
+	AdminNote  string    `json:"admin_note"`
+	OrderID    string    `json:"order_id,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InquiryStore holds inquiries in memory or PostgreSQL.
+type InquiryStore struct {
+	mu    sync.RWMutex
+	items []Inquiry
+	pool  *pgxpool.Pool
+}
+
+// NewInquiryStore returns a new in-memory inquiry store.
+func NewInquiryStore() *InquiryStore {
+	return &InquiryStore{items: make([]Inquiry, 0)}
+}
+
+// NewInquiryStoreFromDB returns an inquiry store backed by PostgreSQL.
+func NewInquiryStoreFromDB(pool *pgxpool.Pool) *InquiryStore {
+	return &InquiryStore{pool: pool}
+}
+
+const inquiryColumns = `id, nama, email, whatsapp, service_ids, layanan, deskripsi, budget_min, budget_max, deadline, status, admin_note,
+	order_id, ip, created_at`
+
+// Add saves a new inquiry with status new and returns it with ID.
+func (s *InquiryStore) Add(q Inquiry) Inquiry {
+	q.ID = generateID()
+	q.Status = InquiryStatusNew
+	q.OrderID = ""
+	q.CreatedAt = time.Now().UTC()
+	if q.ServiceIDs == nil {
+		q.ServiceIDs = []string{}
+	}
+	if q.Layanan == nil {
+		q.Layanan = []string{}
+	}
+	if s.pool != nil {
+		ids, _ := json.Marshal(q.ServiceIDs)
+		titles, _ := json.Marshal(q.Layanan)
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO inquiries (`+inquiryColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`,
+			q.ID, q.Nama, q.Email, q.WhatsApp, ids, titles, q.Deskripsi, q.BudgetMin, q.BudgetMax, q.Deadline.Ptr(), q.Status, q.AdminNote,
+			q.OrderID, q.IP, q.CreatedAt)
+		if err != nil {
+			return Inquiry{}
+		}
+		return q
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, q)
+	return q
+}
+
+// Get returns an inquiry by ID.
+func (s *InquiryStore) Get(id string) (Inquiry, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+inquiryColumns+` FROM inquiries WHERE id = $1`, id)
+		if len(list) == 0 {
+			return Inquiry{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, q := range s.items {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Inquiry{}, false
+}
+
+// List returns inquiries newest first; status "" = semua.
+func (s *InquiryStore) List(status string) []Inquiry {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+inquiryColumns+` FROM inquiries WHERE $1 = '' OR status = $1 ORDER BY created_at DESC`, status)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Inquiry
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if status == "" || s.items[i].Status == status {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// SetStatus updates status and admin note of an inquiry that has not been converted yet.
+func (s *InquiryStore) SetStatus(id, status, note string) (Inquiry, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE inquiries SET status = $2, admin_note = $3 WHERE id = $1 AND status <> 'converted'`, id, status, note)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Inquiry{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status != InquiryStatusConverted {
+			s.items[i].Status = status
+			s.items[i].AdminNote = note
+			return s.items[i], true
+		}
+	}
+	return Inquiry{}, false
+}
+
+// MarkConverted links the inquiry to orderID. Returns false when it was already converted (klik ganda).
+func (s *InquiryStore) MarkConverted(id, orderID string) (Inquiry, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE inquiries SET status = 'converted', order_id = $2 WHERE id = $1 AND status <> 'converted'`, id, orderID)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Inquiry{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status != InquiryStatusConverted {
+			s.items[i].Status = InquiryStatusConverted
+			s.items[i].OrderID = orderID
+			return s.items[i], true
+		}
+	}
+	return Inquiry{}, false
+}
+
+// ListByEmail returns inquiries sent with the given email (case-insensitive), untuk ekspor data subjek.
+func (s *InquiryStore) ListByEmail(email string) []Inquiry {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+inquiryColumns+` FROM inquiries WHERE LOWER(TRIM(email)) = $1 ORDER BY created_at DESC`, email)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Inquiry
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if strings.ToLower(strings.TrimSpace(s.items[i].Email)) == email {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// Anonymize removes the sender's identity, contact, description and IP (hapus data subjek).
+func (s *InquiryStore) Anonymize(id string) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE inquiries SET nama = '', email = '', whatsapp = '', deskripsi = '', ip = '' WHERE id = $1`, id)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Nama = ""
+			s.items[i].Email = ""
+			s.items[i].WhatsApp = ""
+			s.items[i].Deskripsi = ""
+			s.items[i].IP = ""
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InquiryStore) queryDB(q string, args ...any) []Inquiry {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []Inquiry
+	for rows.Next() {
+		var x Inquiry
+		var ids, titles []byte
+		var deadline *time.Time
+		if err := rows.Scan(&x.ID, &x.Nama, &x.Email, &x.WhatsApp, &ids, &titles, &x.Deskripsi, &x.BudgetMin, &x.BudgetMax, &deadline,
+			&x.Status, &x.AdminNote, &x.OrderID, &x.IP, &x.CreatedAt); err != nil {
+			return out
+		}
+		_ = json.Unmarshal(ids, &x.ServiceIDs)
+		_ = json.Unmarshal(titles, &x.Layanan)
+		x.Deadline = DateOf(deadline)
+		out = append(out, x)
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Outbox message status.
+const (
+	OutboxStatusPending = "pending" // menunggu dikirim / dicoba ulang
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed" // gagal permanen setelah batas percobaan
+)
+
+// OutboxAttachment is a file attached to an outbox message.
+type OutboxAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// OutboxMessage is one queued email. Key mencegah email yang sama dikirim dua kali (mis. "donation-receipt:<id>").
+type OutboxMessage struct {
+	ID            string             `json:"id"`
+	Key           string             `json:"key"`
+	To            string             `json:"to"`
+	Subject       string             `json:"subject"`
+	Text          string             `json:"-"`
+	HTML          string             `json:"-"`
+	Attachments   []OutboxAttachment `json:"-"`
+	Status        string             `json:"status"`
+	Attempts      int                `json:"attempts"`
+	LastError     string             `json:"last_error,omitempty"`
+	NextAttemptAt time.Time          `json:"next_attempt_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// MailOutboxStore holds queued emails in memory or PostgreSQL.
+type MailOutboxStore struct {
+	mu    sync.RWMutex
+	items []OutboxMessage
+	pool  *pgxpool.Pool
+}
+
+// NewMailOutboxStore returns a new in-memory outbox.
+func NewMailOutboxStore() *MailOutboxStore {
+	return &MailOutboxStore{items: make([]OutboxMessage, 0)}
+}
+
+// NewMailOutboxStoreFromDB returns an outbox backed by PostgreSQL.
+func NewMailOutboxStoreFromDB(pool *pgxpool.Pool) *MailOutboxStore {
+	return &MailOutboxStore{pool: pool}
+}
+
+const outboxColumns = `id, key, recipient, subject, text_body, html_body, attachments, status, attempts, last_error, next_attempt_at, sent_at, created_at`
+
+// Enqueue queues m for sending now. Returns false if a message with the same key already exists (atau gagal simpan).
+func (s *MailOutboxStore) Enqueue(m OutboxMessage) (OutboxMessage, bool) {
+	m.ID = generateID()
+	m.Status = OutboxStatusPending
+	m.Attempts = 0
+	m.CreatedAt = time.Now().UTC()
+	m.NextAttemptAt = m.CreatedAt
+	if s.pool != nil {
+		attachments, err := json.Marshal(m.Attachments)
+		if err != nil {
+			return OutboxMessage{}, false
+		}
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `INSERT INTO mail_outbox (`+outboxColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			ON CONFLICT (key) DO NOTHING`,
+			m.ID, m.Key, m.To, m.Subject, m.Text, m.HTML, attachments, m.Status, m.Attempts, m.LastError, m.NextAttemptAt, m.SentAt, m.CreatedAt)
+		if err != nil || ct.RowsAffected() == 0 {
+			return OutboxMessage{}, false
+		}
+		return m, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.items {
+		if existing.Key == m.Key {
+			return OutboxMessage{}, false
+		}
+	}
+	s.items = append(s.items, m)
+	return m, true
+}
+
+// Due returns up to limit pending messages whose next attempt is at or before now (oldest first).
+func (s *MailOutboxStore) Due(now time.Time, limit int) []OutboxMessage {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+outboxColumns+` FROM mail_outbox WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at LIMIT $2`, now, limit)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []OutboxMessage
+	for _, m := range s.items {
+		if m.Status == OutboxStatusPending && !m.NextAttemptAt.After(now) {
+			out = append(out, m)
+			if len(out) == limit {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// MarkSent records a successful delivery.
+func (s *MailOutboxStore) MarkSent(id string) bool {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE mail_outbox SET status = 'sent', attempts = attempts + 1, last_error = '', sent_at = $2 WHERE id = $1`, id, now)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Status = OutboxStatusSent
+			s.items[i].Attempts++
+			s.items[i].LastError = ""
+			s.items[i].SentAt = &now
+			return true
+		}
+	}
+	return false
+}
+
+// MarkFailed records a failed attempt. next nil = berhenti mencoba (status failed).
+func (s *MailOutboxStore) MarkFailed(id, errMsg string, next *time.Time) bool {
+	status := OutboxStatusPending
+	nextAt := time.Now().UTC()
+	if next == nil {
+		status = OutboxStatusFailed
+	} else {
+		nextAt = *next
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE mail_outbox SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4 WHERE id = $1`,
+			id, status, errMsg, nextAt)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Status = status
+			s.items[i].Attempts++
+			s.items[i].LastError = errMsg
+			s.items[i].NextAttemptAt = nextAt
+			return true
+		}
+	}
+	return false
+}
+
+// Retry puts a message back in the queue for an immediate attempt (dari admin).
+func (s *MailOutboxStore) Retry(id string) (OutboxMessage, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE mail_outbox SET status = 'pending', next_attempt_at = $2 WHERE id = $1 AND status <> 'sent'`, id, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return OutboxMessage{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status != OutboxStatusSent {
+			s.items[i].Status = OutboxStatusPending
+			s.items[i].NextAttemptAt = now
+			return s.items[i], true
+		}
+	}
+	return OutboxMessage{}, false
+}
+
+// Get returns a message by ID.
+func (s *MailOutboxStore) Get(id string) (OutboxMessage, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+outboxColumns+` FROM mail_outbox WHERE id = $1`, id)
+		if len(list) == 0 {
+			return OutboxMessage{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, m := range s.items {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return OutboxMessage{}, false
+}
+
+// List returns messages newest first; status "" = semua. limit <= 0 = 100.
+func (s *MailOutboxStore) List(status string, limit int) []OutboxMessage {
+	if limit <= 0 {
+		limit = 100
+	}
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+outboxColumns+` FROM mail_outbox WHERE ($1 = '' OR status = $1)
+			ORDER BY created_at DESC LIMIT $2`, status, limit)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []OutboxMessage
+	for i := len(s.items) - 1; i >= 0 && len(out) < limit; i-- {
+		if status == "" || s.items[i].Status == status {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// DeleteByRecipient removes every message to email (permintaan hapus data). Returns the number removed.
+func (s *MailOutboxStore) DeleteByRecipient(email string) int {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return 0
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `DELETE FROM mail_outbox WHERE LOWER(TRIM(recipient)) = $1`, email)
+		if err != nil {
+			return 0
+		}
+		return int(ct.RowsAffected())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.items[:0]
+	n := 0
+	for _, m := range s.items {
+		if strings.ToLower(strings.TrimSpace(m.To)) == email {
+			n++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	s.items = kept
+	return n
+}
+
+func (s *MailOutboxStore) queryDB(q string, args ...any) []OutboxMessage {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		var attachments []byte
+		if err := rows.Scan(&m.ID, &m.Key, &m.To, &m.Subject, &m.Text, &m.HTML, &attachments, &m.Status, &m.Attempts,
+			&m.LastError, &m.NextAttemptAt, &m.SentAt, &m.CreatedAt); err != nil {
+			return out
+		}
+		_ = json.Unmarshal(attachments, &m.Attachments)
+		out = append(out, m)
+	}
+	return out
+}
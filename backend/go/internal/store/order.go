@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,24 +13,38 @@ import (
 
 // OrderItem is one order layanan (sedang dikerjakan / antrian).
 type OrderItem struct {
-	ID                   string     `json:"id"`
-	Layanan              string     `json:"layanan"`                // nama layanan (e.g. UI Designer)
-	Pemesan              string     `json:"pemesan"`                // siapa yang order
-	DeskripsiPekerjaan   string     `json:"deskripsi_pekerjaan"`    // apa yang akan dikerjakan
-	Deadline             string     `json:"deadline"`               // deadline (YYYY-MM-DD atau teks)
-	MulaiTanggal         string     `json:"mulai_tanggal"`          // mulai tanggal
-	KesepakatanBriefUang string     `json:"kesepakatan_brief_uang"`  // kesepakatan uang (brief)
-	KapanUangMasuk       string     `json:"kapan_uang_masuk"`       // kapan uang masuk
-	Status               string     `json:"status"`                 // in_progress | completed
-	CompletedAt          *time.Time `json:"completed_at,omitempty"` // ketika diselesaikan
-	CreatedAt            time.Time  `json:"created_at"`
+	ID                   string       `json:"id"`
+	Layanan              string       `json:"layanan"`                 // nama layanan (e.g. UI Designer)
+	Pemesan              string       `json:"pemesan"`                 // siapa yang order
+	EmailPemesan         string       `json:"email_pemesan,omitempty"` // kontak klien (opsional)
+	DeskripsiPekerjaan   string       `json:"deskripsi_pekerjaan"`     // apa yang akan dikerjakan
+	Deadline             *Date        `json:"deadline"`                // deadline
+	MulaiTanggal         *Date        `json:"mulai_tanggal"`           // mulai tanggal
+	KesepakatanBriefUang string       `json:"kesepakatan_brief_uang"`  // catatan kesepakatan uang (brief)
+	NilaiKesepakatan     int64        `json:"nilai_kesepakatan"`       // nilai kesepakatan (IDR)
+	JumlahDibayar        int64        `json:"jumlah_dibayar"`          // total pembayaran klien yang sudah diterima (IDR)
+	KapanUangMasuk       *Date        `json:"kapan_uang_masuk"`        // kapan uang masuk
+	Status               string       `json:"status"`                  // lihat OrderStatus*
+	TrackingCode         string       `json:"tracking_code"`           // kode rahasia portal lacak order untuk klien
+	RevisiPutaran        int          `json:"revisi_putaran"`          // jatah revisi sesuai kesepakatan (tiket tambahan tidak dihitung)
+	CompletedAt          *time.Time   `json:"completed_at,omitempty"`  // ketika diselesaikan
+	Legacy               *OrderLegacy `json:"legacy,omitempty"`        // teks asli sebelum kolom bertipe
+	CreatedAt            time.Time    `json:"created_at"`
+}
+
+// OrderLegacy holds the original free-text values of orders created before dates were typed.
+type OrderLegacy struct {
+	Deadline       string `json:"deadline,omitempty"`
+	MulaiTanggal   string `json:"mulai_tanggal,omitempty"`
+	KapanUangMasuk string `json:"kapan_uang_masuk,omitempty"`
 }
 
 // OrderStore holds order layanan in memory or PostgreSQL (when pool is set).
 type OrderStore struct {
-	mu    sync.RWMutex
-	items []OrderItem
-	pool  *pgxpool.Pool
+	mu     sync.RWMutex
+	items  []OrderItem
+	events []OrderEvent
+	pool   *pgxpool.Pool
 }
 
 // NewOrderStore returns a new in-memory order store.
@@ -40,50 +57,78 @@ func NewOrderStoreFromDB(pool *pgxpool.Pool) *OrderStore {
 	return &OrderStore{pool: pool}
 }
 
-// Add appends an order.
-func (o *OrderStore) Add(layanan, pemesan, deskripsi, deadline, mulai, kesepakatan, uangMasuk string) OrderItem {
+const orderColumns = `id, layanan, pemesan, email_pemesan, deskripsi_pekerjaan, deadline, mulai_tanggal, kesepakatan_brief_uang,
+	nilai_kesepakatan, jumlah_dibayar, kapan_uang_masuk, status, tracking_code, revisi_putaran, completed_at, deadline_legacy, mulai_tanggal_legacy,
+	kapan_uang_masuk_legacy, created_at`
+
+// generateTrackingCode returns a new order tracking code, e.g. TRK-3F9A0C27B1DE.
+func generateTrackingCode() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "TRK-" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// Add appends an order (status in_progress bila kosong).
+func (o *OrderStore) Add(item OrderItem) OrderItem {
+	item.ID = generateID()
+	item.TrackingCode = generateTrackingCode()
+	item.CreatedAt = time.Now().UTC()
+	item.Legacy = nil
+	if item.Status == "" {
+		item.Status = OrderStatusInProgress
+	}
 	if o.pool != nil {
-		return o.addDB(layanan, pemesan, deskripsi, deadline, mulai, kesepakatan, uangMasuk)
+		ctx := context.Background()
+		_, err := o.pool.Exec(ctx, `INSERT INTO orders (id, layanan, pemesan, email_pemesan, deskripsi_pekerjaan, deadline, mulai_tanggal,
+			kesepakatan_brief_uang, nilai_kesepakatan, jumlah_dibayar, kapan_uang_masuk, status, tracking_code, revisi_putaran, legacy_parsed, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,true,$15)`,
+			item.ID, item.Layanan, item.Pemesan, item.EmailPemesan, item.DeskripsiPekerjaan, item.Deadline.Ptr(), item.MulaiTanggal.Ptr(),
+			item.KesepakatanBriefUang, item.NilaiKesepakatan, item.JumlahDibayar, item.KapanUangMasuk.Ptr(), item.Status, item.TrackingCode,
+			item.RevisiPutaran, item.CreatedAt)
+		if err != nil {
+			return OrderItem{}
+		}
+		return item
 	}
 	o.mu.Lock()
 	defer o.mu.Unlock()
-	item := OrderItem{
-		ID:                   generateID(),
-		Layanan:              layanan,
-		Pemesan:              pemesan,
-		DeskripsiPekerjaan:   deskripsi,
-		Deadline:             deadline,
-		MulaiTanggal:         mulai,
-		KesepakatanBriefUang: kesepakatan,
-		KapanUangMasuk:       uangMasuk,
-		CreatedAt:            time.Now().UTC(),
-	}
-	item.Status = "in_progress"
 	o.items = append(o.items, item)
 	return item
 }
 
-func (o *OrderStore) addDB(layanan, pemesan, deskripsi, deadline, mulai, kesepakatan, uangMasuk string) OrderItem {
-	item := OrderItem{
-		ID:                   generateID(),
-		Layanan:              layanan,
-		Pemesan:              pemesan,
-		DeskripsiPekerjaan:   deskripsi,
-		Deadline:             deadline,
-		MulaiTanggal:         mulai,
-		KesepakatanBriefUang: kesepakatan,
-		KapanUangMasuk:       uangMasuk,
-		Status:               "in_progress",
-		CreatedAt:            time.Now().UTC(),
+// Update overwrites the editable fields of an order (bukan status; status lewat Transition).
+func (o *OrderStore) Update(item OrderItem) (OrderItem, bool) {
+	if o.pool != nil {
+		ctx := context.Background()
+		ct, err := o.pool.Exec(ctx, `UPDATE orders SET layanan = $2, pemesan = $3, email_pemesan = $4, deskripsi_pekerjaan = $5,
+			deadline = $6, mulai_tanggal = $7, kesepakatan_brief_uang = $8, nilai_kesepakatan = $9, jumlah_dibayar = $10, kapan_uang_masuk = $11
+			WHERE id = $1`,
+			item.ID, item.Layanan, item.Pemesan, item.EmailPemesan, item.DeskripsiPekerjaan, item.Deadline.Ptr(), item.MulaiTanggal.Ptr(),
+			item.KesepakatanBriefUang, item.NilaiKesepakatan, item.JumlahDibayar, item.KapanUangMasuk.Ptr())
+		if err != nil || ct.RowsAffected() == 0 {
+			return OrderItem{}, false
+		}
+		return o.Get(item.ID)
 	}
-	ctx := context.Background()
-	_, err := o.pool.Exec(ctx, `INSERT INTO orders (id, layanan, pemesan, deskripsi_pekerjaan, deadline, mulai_tanggal, kesepakatan_brief_uang, kapan_uang_masuk, status, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
-		item.ID, item.Layanan, item.Pemesan, item.DeskripsiPekerjaan, item.Deadline, item.MulaiTanggal, item.KesepakatanBriefUang, item.KapanUangMasuk, item.Status, item.CreatedAt)
-	if err != nil {
-		return OrderItem{}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.items {
+		if o.items[i].ID == item.ID {
+			cur := &o.items[i]
+			cur.Layanan = item.Layanan
+			cur.Pemesan = item.Pemesan
+			cur.EmailPemesan = item.EmailPemesan
+			cur.DeskripsiPekerjaan = item.DeskripsiPekerjaan
+			cur.Deadline = item.Deadline
+			cur.MulaiTanggal = item.MulaiTanggal
+			cur.KesepakatanBriefUang = item.KesepakatanBriefUang
+			cur.NilaiKesepakatan = item.NilaiKesepakatan
+			cur.JumlahDibayar = item.JumlahDibayar
+			cur.KapanUangMasuk = item.KapanUangMasuk
+			return *cur, true
+		}
 	}
-	return item
+	return OrderItem{}, false
 }
 
 // List returns all orders (newest first).
@@ -102,8 +147,12 @@ func (o *OrderStore) List() []OrderItem {
 }
 
 func (o *OrderStore) listDB() []OrderItem {
+	return o.queryDB(`SELECT ` + orderColumns + ` FROM orders ORDER BY created_at DESC`)
+}
+
+func (o *OrderStore) queryDB(q string, args ...any) []OrderItem {
 	ctx := context.Background()
-	rows, err := o.pool.Query(ctx, `SELECT id, layanan, pemesan, deskripsi_pekerjaan, deadline, mulai_tanggal, kesepakatan_brief_uang, kapan_uang_masuk, status, completed_at, created_at FROM orders ORDER BY created_at DESC`)
+	rows, err := o.pool.Query(ctx, q, args...)
 	if err != nil {
 		return nil
 	}
@@ -111,41 +160,107 @@ func (o *OrderStore) listDB() []OrderItem {
 	var out []OrderItem
 	for rows.Next() {
 		var item OrderItem
-		if err := rows.Scan(&item.ID, &item.Layanan, &item.Pemesan, &item.DeskripsiPekerjaan, &item.Deadline, &item.MulaiTanggal, &item.KesepakatanBriefUang, &item.KapanUangMasuk, &item.Status, &item.CompletedAt, &item.CreatedAt); err != nil {
+		var deadline, mulai, uangMasuk *time.Time
+		var legacy OrderLegacy
+		if err := rows.Scan(&item.ID, &item.Layanan, &item.Pemesan, &item.EmailPemesan, &item.DeskripsiPekerjaan, &deadline, &mulai,
+			&item.KesepakatanBriefUang, &item.NilaiKesepakatan, &item.JumlahDibayar, &uangMasuk, &item.Status, &item.TrackingCode, &item.RevisiPutaran,
+			&item.CompletedAt,
+			&legacy.Deadline, &legacy.MulaiTanggal, &legacy.KapanUangMasuk, &item.CreatedAt); err != nil {
 			return out
 		}
+		item.Deadline, item.MulaiTanggal, item.KapanUangMasuk = DateOf(deadline), DateOf(mulai), DateOf(uangMasuk)
+		if legacy != (OrderLegacy{}) {
+			item.Legacy = &legacy
+		}
 		if item.Status == "" {
-			item.Status = "in_progress"
+			item.Status = OrderStatusInProgress
 		}
 		out = append(out, item)
 	}
 	return out
 }
 
-// Complete marks an order as completed (status=completed, completed_at=now).
-func (o *OrderStore) Complete(id string) bool {
+// Get returns an order by ID.
+func (o *OrderStore) Get(id string) (OrderItem, bool) {
+	if o.pool != nil {
+		list := o.queryDB(`SELECT `+orderColumns+` FROM orders WHERE id = $1`, id)
+		if len(list) == 0 {
+			return OrderItem{}, false
+		}
+		return list[0], true
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, item := range o.items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return OrderItem{}, false
+}
+
+// GetByTrackingCode returns the order with the given tracking code (tanpa beda huruf besar/kecil dan spasi).
+func (o *OrderStore) GetByTrackingCode(code string) (OrderItem, bool) {
+	code = trimUpper(code)
+	if code == "" {
+		return OrderItem{}, false
+	}
+	if o.pool != nil {
+		list := o.queryDB(`SELECT `+orderColumns+` FROM orders WHERE tracking_code = $1`, code)
+		if len(list) == 0 {
+			return OrderItem{}, false
+		}
+		return list[0], true
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, item := range o.items {
+		if item.TrackingCode == code {
+			return item, true
+		}
+	}
+	return OrderItem{}, false
+}
+
+// ListByEmail returns orders whose client email matches (tanpa beda huruf besar/kecil), untuk permintaan subjek data.
+func (o *OrderStore) ListByEmail(email string) []OrderItem {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	if o.pool != nil {
+		return o.queryDB(`SELECT `+orderColumns+` FROM orders WHERE LOWER(TRIM(email_pemesan)) = $1 ORDER BY created_at DESC`, email)
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var out []OrderItem
+	for i := len(o.items) - 1; i >= 0; i-- {
+		if strings.ToLower(strings.TrimSpace(o.items[i].EmailPemesan)) == email {
+			out = append(out, o.items[i])
+		}
+	}
+	return out
+}
+
+// Anonymize erases the client's identity on an order (pemesan, email) but keeps the job record.
+func (o *OrderStore) Anonymize(id string) bool {
 	if o.pool != nil {
-		return o.completeDB(id)
+		ctx := context.Background()
+		ct, err := o.pool.Exec(ctx, `UPDATE orders SET pemesan = '[dihapus]', email_pemesan = '' WHERE id = $1`, id)
+		return err == nil && ct.RowsAffected() > 0
 	}
 	o.mu.Lock()
 	defer o.mu.Unlock()
-	now := time.Now().UTC()
 	for i := range o.items {
 		if o.items[i].ID == id {
-			o.items[i].Status = "completed"
-			o.items[i].CompletedAt = &now
+			o.items[i].Pemesan = "[dihapus]"
+			o.items[i].EmailPemesan = ""
 			return true
 		}
 	}
 	return false
 }
 
-func (o *OrderStore) completeDB(id string) bool {
-	ctx := context.Background()
-	_, err := o.pool.Exec(ctx, `UPDATE orders SET status = 'completed', completed_at = $1 WHERE id = $2`, time.Now().UTC(), id)
-	return err == nil
-}
-
 // Delete removes an order by ID.
 func (o *OrderStore) Delete(id string) bool {
 	if o.pool != nil {
@@ -161,6 +276,13 @@ func (o *OrderStore) Delete(id string) bool {
 	for i, item := range o.items {
 		if item.ID == id {
 			o.items = append(o.items[:i], o.items[i+1:]...)
+			kept := o.events[:0]
+			for _, ev := range o.events {
+				if ev.OrderID != id {
+					kept = append(kept, ev)
+				}
+			}
+			o.events = kept
 			return true
 		}
 	}
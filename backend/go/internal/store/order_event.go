@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Order lifecycle statuses.
+const (
+	OrderStatusInquiry       = "inquiry"        // permintaan masuk, belum ada penawaran
+	OrderStatusQuoted        = "quoted"         // penawaran harga dikirim
+	OrderStatusAgreementSent = "agreement_sent" // surat perjanjian dikirim untuk ditandatangani
+	OrderStatusDPPaid        = "dp_paid"        // DP diterima, masuk antrian
+	OrderStatusInProgress    = "in_progress"
+	OrderStatusInReview      = "in_review" // hasil dikirim, menunggu tanggapan klien
+	OrderStatusRevision      = "revision"
+	OrderStatusCompleted     = "completed"
+	OrderStatusCancelled     = "cancelled"
+)
+
+// orderTransitions lists the statuses each status may move to.
+// in_progress → completed tetap diizinkan untuk pekerjaan kecil tanpa tahap review (tombol "selesai" lama),
+// completed → revision untuk klaim tiket revisi setelah serah terima.
+var orderTransitions = map[string][]string{
+	OrderStatusInquiry:       {OrderStatusQuoted, OrderStatusCancelled},
+	OrderStatusQuoted:        {OrderStatusAgreementSent, OrderStatusInquiry, OrderStatusCancelled},
+	OrderStatusAgreementSent: {OrderStatusDPPaid, OrderStatusQuoted, OrderStatusCancelled},
+	OrderStatusDPPaid:        {OrderStatusInProgress, OrderStatusCancelled},
+	OrderStatusInProgress:    {OrderStatusInReview, OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusInReview:      {OrderStatusRevision, OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusRevision:      {OrderStatusInReview, OrderStatusCancelled},
+	OrderStatusCompleted:     {OrderStatusRevision},
+	OrderStatusCancelled:     {},
+}
+
+// ValidOrderStatus reports whether s is a known order status.
+func ValidOrderStatus(s string) bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another.
+func CanTransitionOrder(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOrderStatuses returns the statuses an order in status may move to (untuk tombol di admin).
+func NextOrderStatuses(status string) []string {
+	return append([]string{}, orderTransitions[status]...)
+}
+
+// IsActive reports whether the order is paid and being worked on (masuk antrian pengerjaan).
+func (o OrderItem) IsActive() bool {
+	switch o.Status {
+	case OrderStatusDPPaid, OrderStatusInProgress, OrderStatusInReview, OrderStatusRevision:
+		return true
+	}
+	return false
+}
+
+// OrderEvent is one recorded status change of an order.
+type OrderEvent struct {
+	ID         string    `json:"id"`
+	OrderID    string    `json:"order_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"` // email admin, "client" atau "system"
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Transition moves order id from status from to status to and records an event.
+// Returns false if the order is missing, its status is no longer from (diubah bersamaan), or the move is not allowed.
+func (o *OrderStore) Transition(id, from, to, actor, note string) (OrderItem, bool) {
+	if !CanTransitionOrder(from, to) {
+		return OrderItem{}, false
+	}
+	now := time.Now().UTC()
+	ev := OrderEvent{ID: generateID(), OrderID: id, FromStatus: from, ToStatus: to, Actor: actor, Note: note, CreatedAt: now}
+	if o.pool != nil {
+		ctx := context.Background()
+		tx, err := o.pool.Begin(ctx)
+		if err != nil {
+			return OrderItem{}, false
+		}
+		defer tx.Rollback(ctx)
+		ct, err := tx.Exec(ctx, `UPDATE orders SET status = $3,
+			completed_at = CASE WHEN $3 = 'completed' THEN $4 ELSE completed_at END
+			WHERE id = $1 AND status = $2`, id, from, to, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return OrderItem{}, false
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO order_events (id, order_id, from_status, to_status, actor, note, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)`, ev.ID, ev.OrderID, ev.FromStatus, ev.ToStatus, ev.Actor, ev.Note, ev.CreatedAt); err != nil {
+			return OrderItem{}, false
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return OrderItem{}, false
+		}
+		return o.Get(id)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.items {
+		if o.items[i].ID != id {
+			continue
+		}
+		if o.items[i].Status != from {
+			return OrderItem{}, false
+		}
+		o.items[i].Status = to
+		if to == OrderStatusCompleted {
+			o.items[i].CompletedAt = &now
+		}
+		o.events = append(o.events, ev)
+		return o.items[i], true
+	}
+	return OrderItem{}, false
+}
+
+// Events returns the status history of an order, oldest first.
+func (o *OrderStore) Events(orderID string) []OrderEvent {
+	if o.pool != nil {
+		ctx := context.Background()
+		rows, err := o.pool.Query(ctx, `SELECT id, order_id, from_status, to_status, actor, note, created_at FROM order_events
+			WHERE order_id = $1 ORDER BY created_at`, orderID)
+		if err != nil {
+			return nil
+		}
+		defer rows.Close()
+		var out []OrderEvent
+		for rows.Next() {
+			var ev OrderEvent
+			if err := rows.Scan(&ev.ID, &ev.OrderID, &ev.FromStatus, &ev.ToStatus, &ev.Actor, &ev.Note, &ev.CreatedAt); err != nil {
+				return out
+			}
+			out = append(out, ev)
+		}
+		return out
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var out []OrderEvent
+	for _, ev := range o.events {
+		if ev.OrderID == orderID {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
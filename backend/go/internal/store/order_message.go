@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Order message senders.
+const (
+	MessageSenderAdmin  = "admin"
+	MessageSenderClient = "client"
+)
+
+// MessageAttachment is a file attached to an order message. Path relatif terhadap PRIVATE_UPLOAD_DIR, tidak pernah dikirim ke klien.
+type MessageAttachment struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"` // nama file asli (sudah dibersihkan)
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Path        string `json:"-"`
+}
+
+// OrderMessage is one message in the discussion thread of an order (admin <-> klien).
+type OrderMessage struct {
+	ID          string              `json:"id"`
+	OrderID     string              `json:"order_id"`
+	Sender      string              `json:"sender"`      // admin | client
+	SenderName  string              `json:"sender_name"` // email admin atau nama pemesan
+	Body        string              `json:"body"`
+	Attachments []MessageAttachment `json:"attachments"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// OrderMessageStore holds order messages in memory or PostgreSQL.
+type OrderMessageStore struct {
+	mu    sync.RWMutex
+	items []OrderMessage
+	pool  *pgxpool.Pool
+}
+
+// NewOrderMessageStore returns a new in-memory order message store.
+func NewOrderMessageStore() *OrderMessageStore {
+	return &OrderMessageStore{items: make([]OrderMessage, 0)}
+}
+
+// NewOrderMessageStoreFromDB returns an order message store backed by PostgreSQL.
+func NewOrderMessageStoreFromDB(pool *pgxpool.Pool) *OrderMessageStore {
+	return &OrderMessageStore{pool: pool}
+}
+
+const orderMessageColumns = `id, order_id, sender, sender_name, body, attachments, created_at`
+
+// Add saves a new message and returns it with ID. Attachment IDs must be set by the caller (file sudah disimpan).
+func (s *OrderMessageStore) Add(m OrderMessage) OrderMessage {
+	m.ID = generateID()
+	m.CreatedAt = time.Now().UTC()
+	if m.Attachments == nil {
+		m.Attachments = []MessageAttachment{}
+	}
+	if s.pool != nil {
+		atts, _ := json.Marshal(storedAttachments(m.Attachments))
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO order_messages (`+orderMessageColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			m.ID, m.OrderID, m.Sender, m.SenderName, m.Body, atts, m.CreatedAt)
+		if err != nil {
+			return OrderMessage{}
+		}
+		return m
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, m)
+	return m
+}
+
+// List returns the thread of an order, oldest first.
+func (s *OrderMessageStore) List(orderID string) []OrderMessage {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+orderMessageColumns+` FROM order_messages WHERE order_id = $1 ORDER BY created_at`, orderID)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []OrderMessage
+	for _, m := range s.items {
+		if m.OrderID == orderID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Attachment returns attachment id of a message in the given order.
+func (s *OrderMessageStore) Attachment(orderID, id string) (MessageAttachment, bool) {
+	for _, m := range s.List(orderID) {
+		for _, a := range m.Attachments {
+			if a.ID == id {
+				return a, true
+			}
+		}
+	}
+	return MessageAttachment{}, false
+}
+
+// DeleteByOrder removes the whole thread of an order and returns the deleted messages (untuk menghapus file lampiran).
+func (s *OrderMessageStore) DeleteByOrder(orderID string) []OrderMessage {
+	if s.pool != nil {
+		return s.queryDB(`DELETE FROM order_messages WHERE order_id = $1 RETURNING `+orderMessageColumns, orderID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted []OrderMessage
+	kept := s.items[:0]
+	for _, m := range s.items {
+		if m.OrderID == orderID {
+			deleted = append(deleted, m)
+		} else {
+			kept = append(kept, m)
+		}
+	}
+	s.items = kept
+	return deleted
+}
+
+// storedAttachment includes Path, which is hidden from the JSON API but must be kept in the database.
+type storedAttachment struct {
+	MessageAttachment
+	Path string `json:"path"`
+}
+
+func storedAttachments(list []MessageAttachment) []storedAttachment {
+	out := make([]storedAttachment, len(list))
+	for i, a := range list {
+		out[i] = storedAttachment{MessageAttachment: a, Path: a.Path}
+	}
+	return out
+}
+
+func (s *OrderMessageStore) queryDB(q string, args ...any) []OrderMessage {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []OrderMessage
+	for rows.Next() {
+		var m OrderMessage
+		var atts []byte
+		if err := rows.Scan(&m.ID, &m.OrderID, &m.Sender, &m.SenderName, &m.Body, &atts, &m.CreatedAt); err != nil {
+			return out
+		}
+		var stored []storedAttachment
+		_ = json.Unmarshal(atts, &stored)
+		m.Attachments = make([]MessageAttachment, len(stored))
+		for i, a := range stored {
+			m.Attachments[i] = a.MessageAttachment
+			m.Attachments[i].Path = a.Path
+		}
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Quotation status.
+const (
+	QuotationStatusDraft    = "draft"    // masih disusun admin, belum bisa dilihat klien
+	QuotationStatusSent     = "sent"     // dikirim, menunggu jawaban klien
+	QuotationStatusAccepted = "accepted" // disetujui klien
+	QuotationStatusDeclined = "declined" // ditolak klien
+)
+
+// QuotationItem is one line of a quotation. Subtotal = Qty x HargaSatuan dikurangi diskon baris.
+type QuotationItem struct {
+	ServiceID    string `json:"service_id,omitempty"`
+	Deskripsi    string `json:"deskripsi"`
+	Qty          int    `json:"qty"`
+	HargaSatuan  int64  `json:"harga_satuan"`  // IDR
+	DiskonPersen int    `json:"diskon_persen"` // 0-100
+	Subtotal     int64  `json:"subtotal"`
+}
+
+// Quotation is a price quote (penawaran harga) for one order.
+type Quotation struct {
+	ID             string          `json:"id"`
+	Nomor          string          `json:"nomor"` // e.g. 003/RP-PN/X/2026
+	OrderID        string          `json:"order_id"`
+	Items          []QuotationItem `json:"items"`
+	Bruto          int64           `json:"bruto"`  // jumlah sebelum diskon
+	Diskon         int64           `json:"diskon"` // total diskon semua baris
+	Total          int64           `json:"total"`
+	DPPercent      int             `json:"dp_percent"`      // uang muka (%)
+	Termin2Percent int             `json:"termin2_percent"` // termin progress (%); 0 = tanpa termin
+	Termin2Waktu   string          `json:"termin2_waktu"`
+	BerlakuSampai  Date            `json:"berlaku_sampai"`
+	Catatan        string          `json:"catatan"`
+	Status         string          `json:"status"`
+	AlasanTolak    string          `json:"alasan_tolak,omitempty"`
+	SentAt         *time.Time      `json:"sent_at,omitempty"`
+	RespondedAt    *time.Time      `json:"responded_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Recalculate fills line subtotals and the quotation totals from the items.
+func (q *Quotation) Recalculate() {
+	q.Bruto, q.Diskon, q.Total = 0, 0, 0
+	for i := range q.Items {
+		it := &q.Items[i]
+		gross := int64(it.Qty) * it.HargaSatuan
+		disc := gross * int64(it.DiskonPersen) / 100
+		it.Subtotal = gross - disc
+		q.Bruto += gross
+		q.Diskon += disc
+		q.Total += it.Subtotal
+	}
+}
+
+// Expired reports whether the quotation is past its validity date (today = tanggal hari ini, WIB).
+func (q Quotation) Expired(today Date) bool {
+	return q.BerlakuSampai.Before(today.Time)
+}
+
+// PaymentSplit returns the DP, termin 2 and pelunasan amounts of the total (pelunasan = sisa).
+func (q Quotation) PaymentSplit() (dp, termin2, pelunasan int64) {
+	dp = q.Total * int64(q.DPPercent) / 100
+	termin2 = q.Total * int64(q.Termin2Percent) / 100
+	return dp, termin2, q.Total - dp - termin2
+}
+
+// QuotationStore holds quotations in memory or PostgreSQL.
+type QuotationStore struct {
+	mu    sync.RWMutex
+	items []Quotation
+	pool  *pgxpool.Pool
+}
+
+// NewQuotationStore returns a new in-memory quotation store.
+func NewQuotationStore() *QuotationStore {
+	return &QuotationStore{items: make([]Quotation, 0)}
+}
+
+// NewQuotationStoreFromDB returns a quotation store backed by PostgreSQL.
+func NewQuotationStoreFromDB(pool *pgxpool.Pool) *QuotationStore {
+	return &QuotationStore{pool: pool}
+}
+
+const quotationColumns = `id, nomor, order_id, items, bruto, diskon, total, dp_percent, termin2_percent, termin2_waktu, berlaku_sampai,
+	catatan, status, alasan_tolak, sent_at, responded_at, created_at`
+
+var romanMonths = [...]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// Add saves a new draft quotation with the next nomor of the year (format seperti nomor perjanjian).
+func (s *QuotationStore) Add(q Quotation) Quotation {
+	q.ID = generateID()
+	q.Status = QuotationStatusDraft
+	q.CreatedAt = time.Now().UTC()
+	q.Recalculate()
+	yearStart := time.Date(q.CreatedAt.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if s.pool != nil {
+		ctx := context.Background()
+		var n int
+		_ = s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM quotations WHERE created_at >= $1`, yearStart).Scan(&n)
+		q.Nomor = fmt.Sprintf("%03d/RP-PN/%s/%d", n+1, romanMonths[q.CreatedAt.Month()-1], q.CreatedAt.Year())
+		items, _ := json.Marshal(q.Items)
+		_, err := s.pool.Exec(ctx, `INSERT INTO quotations (`+quotationColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)`,
+			q.ID, q.Nomor, q.OrderID, items, q.Bruto, q.Diskon, q.Total, q.DPPercent, q.Termin2Percent, q.Termin2Waktu, q.BerlakuSampai.Time,
+			q.Catatan, q.Status, q.AlasanTolak, q.SentAt, q.RespondedAt, q.CreatedAt)
+		if err != nil {
+			return Quotation{}
+		}
+		return q
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, x := range s.items {
+		if !x.CreatedAt.Before(yearStart) {
+			n++
+		}
+	}
+	q.Nomor = fmt.Sprintf("%03d/RP-PN/%s/%d", n+1, romanMonths[q.CreatedAt.Month()-1], q.CreatedAt.Year())
+	s.items = append(s.items, q)
+	return q
+}
+
+// Update overwrites items, payment terms, validity and note of a draft quotation.
+func (s *QuotationStore) Update(q Quotation) (Quotation, bool) {
+	q.Recalculate()
+	if s.pool != nil {
+		items, _ := json.Marshal(q.Items)
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE quotations SET items = $2, bruto = $3, diskon = $4, total = $5, dp_percent = $6, termin2_percent = $7,
+			termin2_waktu = $8, berlaku_sampai = $9, catatan = $10 WHERE id = $1 AND status = 'draft'`,
+			q.ID, items, q.Bruto, q.Diskon, q.Total, q.DPPercent, q.Termin2Percent, q.Termin2Waktu, q.BerlakuSampai.Time, q.Catatan)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Quotation{}, false
+		}
+		return s.Get(q.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		cur := &s.items[i]
+		if cur.ID == q.ID && cur.Status == QuotationStatusDraft {
+			cur.Items = q.Items
+			cur.Bruto, cur.Diskon, cur.Total = q.Bruto, q.Diskon, q.Total
+			cur.DPPercent, cur.Termin2Percent, cur.Termin2Waktu = q.DPPercent, q.Termin2Percent, q.Termin2Waktu
+			cur.BerlakuSampai = q.BerlakuSampai
+			cur.Catatan = q.Catatan
+			return *cur, true
+		}
+	}
+	return Quotation{}, false
+}
+
+// Get returns a quotation by ID.
+func (s *QuotationStore) Get(id string) (Quotation, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+quotationColumns+` FROM quotations WHERE id = $1`, id)
+		if len(list) == 0 {
+			return Quotation{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, q := range s.items {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Quotation{}, false
+}
+
+// List returns quotations newest first; orderID "" = semua.
+func (s *QuotationStore) List(orderID string) []Quotation {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+quotationColumns+` FROM quotations WHERE $1 = '' OR order_id = $1 ORDER BY created_at DESC`, orderID)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Quotation
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if orderID == "" || s.items[i].OrderID == orderID {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// MarkSent sets a draft (or already sent) quotation to sent. Kirim ulang memperbarui SentAt.
+func (s *QuotationStore) MarkSent(id string) (Quotation, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE quotations SET status = 'sent', sent_at = $2 WHERE id = $1 AND status IN ('draft', 'sent')`, id, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Quotation{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && (s.items[i].Status == QuotationStatusDraft || s.items[i].Status == QuotationStatusSent) {
+			s.items[i].Status = QuotationStatusSent
+			s.items[i].SentAt = &now
+			return s.items[i], true
+		}
+	}
+	return Quotation{}, false
+}
+
+// Respond records the client's answer (accepted | declined) on a sent quotation.
+// Returns false when the quotation is not (or no longer) waiting for an answer.
+func (s *QuotationStore) Respond(id, status, alasan string) (Quotation, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE quotations SET status = $2, alasan_tolak = $3, responded_at = $4 WHERE id = $1 AND status = 'sent'`,
+			id, status, alasan, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Quotation{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status == QuotationStatusSent {
+			s.items[i].Status = status
+			s.items[i].AlasanTolak = alasan
+			s.items[i].RespondedAt = &now
+			return s.items[i], true
+		}
+	}
+	return Quotation{}, false
+}
+
+func (s *QuotationStore) queryDB(q string, args ...any) []Quotation {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []Quotation
+	for rows.Next() {
+		var x Quotation
+		var items []byte
+		if err := rows.Scan(&x.ID, &x.Nomor, &x.OrderID, &items, &x.Bruto, &x.Diskon, &x.Total, &x.DPPercent, &x.Termin2Percent, &x.Termin2Waktu,
+			&x.BerlakuSampai.Time, &x.Catatan, &x.Status, &x.AlasanTolak, &x.SentAt, &x.RespondedAt, &x.CreatedAt); err != nil {
+			return out
+		}
+		_ = json.Unmarshal(items, &x.Items)
+		out = append(out, x)
+	}
+	return out
+}
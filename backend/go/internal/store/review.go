@@ -0,0 +1,355 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Review moderation status.
+const (
+	ReviewStatusPending  = "pending"  // menunggu moderasi admin
+	ReviewStatusApproved = "approved" // tampil di halaman publik
+	ReviewStatusHidden   = "hidden"   // disembunyikan admin
+)
+
+// ValidReviewStatus reports whether s is a known moderation status.
+func ValidReviewStatus(s string) bool {
+	return s == ReviewStatusPending || s == ReviewStatusApproved || s == ReviewStatusHidden
+}
+
+// Review is one client review (ulasan). Email hanya untuk admin, tidak pernah tampil publik.
+type Review struct {
+	ID          string     `json:"id"`
+	Rating      int        `json:"rating"` // 1-5; 0 = ulasan lama dari donasi (tanpa rating)
+	DisplayName string     `json:"display_name"`
+	Comment     string     `json:"comment"`
+	Email       string     `json:"email,omitempty"`
+	OrderID     string     `json:"order_id,omitempty"`
+	ServiceID   string     `json:"service_id,omitempty"`
+	Layanan     string     `json:"layanan,omitempty"` // nama layanan dari order (ulasan terverifikasi)
+	Verified    bool       `json:"verified_client"`   // true = dikirim lewat token order yang sudah selesai
+	Source      string     `json:"source"`            // form | admin | donation | order
+	Status      string     `json:"status"`
+	AdminNote   string     `json:"admin_note,omitempty"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ReviewStore holds reviews in memory or PostgreSQL.
+type ReviewStore struct {
+	mu     sync.RWMutex
+	items  []Review
+	tokens []ReviewToken
+	pool   *pgxpool.Pool
+}
+
+// NewReviewStore returns a new in-memory review store.
+func NewReviewStore() *ReviewStore {
+	return &ReviewStore{items: make([]Review, 0)}
+}
+
+// NewReviewStoreFromDB returns a review store backed by PostgreSQL.
+func NewReviewStoreFromDB(pool *pgxpool.Pool) *ReviewStore {
+	return &ReviewStore{pool: pool}
+}
+
+const reviewColumns = `id, rating, display_name, comment, email, order_id, service_id, layanan, verified, source, status, admin_note, moderated_at, created_at`
+
+// Add saves a new review and returns it with ID. Status kosong = pending.
+func (s *ReviewStore) Add(rv Review) Review {
+	rv.ID = generateID()
+	rv.CreatedAt = time.Now().UTC()
+	if rv.Status == "" {
+		rv.Status = ReviewStatusPending
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO reviews (`+reviewColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+			rv.ID, rv.Rating, rv.DisplayName, rv.Comment, rv.Email, rv.OrderID, rv.ServiceID, rv.Layanan, rv.Verified, rv.Source, rv.Status, rv.AdminNote, rv.ModeratedAt, rv.CreatedAt)
+		if err != nil {
+			return Review{}
+		}
+		return rv
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, rv)
+	return rv
+}
+
+// Get returns a review by ID.
+func (s *ReviewStore) Get(id string) (Review, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+reviewColumns+` FROM reviews WHERE id = $1`, id)
+		if len(list) == 0 {
+			return Review{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rv := range s.items {
+		if rv.ID == id {
+			return rv, true
+		}
+	}
+	return Review{}, false
+}
+
+// List returns reviews newest first; status "" = semua status.
+func (s *ReviewStore) List(status string) []Review {
+	if s.pool != nil {
+		if status == "" {
+			return s.queryDB(`SELECT ` + reviewColumns + ` FROM reviews ORDER BY created_at DESC`)
+		}
+		return s.queryDB(`SELECT `+reviewColumns+` FROM reviews WHERE status = $1 ORDER BY created_at DESC`, status)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Review
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if status == "" || s.items[i].Status == status {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// ListByEmail returns reviews submitted with email (tanpa beda huruf besar/kecil), untuk permintaan subjek data.
+func (s *ReviewStore) ListByEmail(email string) []Review {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+reviewColumns+` FROM reviews WHERE LOWER(TRIM(email)) = $1 ORDER BY created_at DESC`, email)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Review
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if strings.ToLower(strings.TrimSpace(s.items[i].Email)) == email {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// Moderate sets the moderation status and admin note of a review.
+func (s *ReviewStore) Moderate(id, status, note string) (Review, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE reviews SET status = $2, admin_note = $3, moderated_at = $4 WHERE id = $1`, id, status, note, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Review{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Status = status
+			s.items[i].AdminNote = note
+			s.items[i].ModeratedAt = &now
+			return s.items[i], true
+		}
+	}
+	return Review{}, false
+}
+
+// Delete removes a review by ID.
+func (s *ReviewStore) Delete(id string) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `DELETE FROM reviews WHERE id = $1`, id)
+		if err != nil {
+			return false
+		}
+		return ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, rv := range s.items {
+		if rv.ID == id {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ReviewStore) queryDB(q string, args ...any) []Review {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []Review
+	for rows.Next() {
+		var rv Review
+		if err := rows.Scan(&rv.ID, &rv.Rating, &rv.DisplayName, &rv.Comment, &rv.Email, &rv.OrderID, &rv.ServiceID,
+			&rv.Layanan, &rv.Verified, &rv.Source, &rv.Status, &rv.AdminNote, &rv.ModeratedAt, &rv.CreatedAt); err != nil {
+			return out
+		}
+		out = append(out, rv)
+	}
+	return out
+}
+
+// ReviewToken is a one-time link token for a client to review a completed order.
+type ReviewToken struct {
+	Token     string     `json:"token"`
+	OrderID   string     `json:"order_id"`
+	Layanan   string     `json:"layanan"`
+	ServiceID string     `json:"service_id,omitempty"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func generateReviewToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// IssueToken returns the review token of an order, creating it on first call (satu token per order).
+func (s *ReviewStore) IssueToken(orderID, layanan, serviceID string) (ReviewToken, bool) {
+	t := ReviewToken{
+		Token:     generateReviewToken(),
+		OrderID:   orderID,
+		Layanan:   layanan,
+		ServiceID: serviceID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO review_tokens (token, order_id, layanan, service_id, created_at)
+			VALUES ($1,$2,$3,$4,$5) ON CONFLICT (order_id) DO NOTHING`,
+			t.Token, t.OrderID, t.Layanan, t.ServiceID, t.CreatedAt)
+		if err != nil {
+			return ReviewToken{}, false
+		}
+		err = s.pool.QueryRow(ctx, `SELECT token, order_id, layanan, service_id, used_at, created_at FROM review_tokens WHERE order_id = $1`, orderID).
+			Scan(&t.Token, &t.OrderID, &t.Layanan, &t.ServiceID, &t.UsedAt, &t.CreatedAt)
+		if err != nil {
+			return ReviewToken{}, false
+		}
+		return t, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.tokens {
+		if existing.OrderID == orderID {
+			return existing, true
+		}
+	}
+	s.tokens = append(s.tokens, t)
+	return t, true
+}
+
+// RedeemToken uses a review token once and saves rv as a verified review for the token's order.
+// Returns false if the token is unknown or already used.
+func (s *ReviewStore) RedeemToken(token string, rv Review) (Review, bool) {
+	now := time.Now().UTC()
+	rv.ID = generateID()
+	rv.Verified = true
+	rv.Source = "order"
+	rv.CreatedAt = now
+	if rv.Status == "" {
+		rv.Status = ReviewStatusPending
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		tx, err := s.pool.Begin(ctx)
+		if err != nil {
+			return Review{}, false
+		}
+		defer tx.Rollback(ctx)
+		err = tx.QueryRow(ctx, `UPDATE review_tokens SET used_at = $2 WHERE token = $1 AND used_at IS NULL
+			RETURNING order_id, layanan, service_id`, token, now).Scan(&rv.OrderID, &rv.Layanan, &rv.ServiceID)
+		if err != nil {
+			return Review{}, false
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO reviews (`+reviewColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+			rv.ID, rv.Rating, rv.DisplayName, rv.Comment, rv.Email, rv.OrderID, rv.ServiceID, rv.Layanan, rv.Verified, rv.Source, rv.Status, rv.AdminNote, rv.ModeratedAt, rv.CreatedAt)
+		if err != nil {
+			return Review{}, false
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return Review{}, false
+		}
+		return rv, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tokens {
+		if s.tokens[i].Token != token {
+			continue
+		}
+		if s.tokens[i].UsedAt != nil {
+			return Review{}, false
+		}
+		s.tokens[i].UsedAt = &now
+		rv.OrderID = s.tokens[i].OrderID
+		rv.Layanan = s.tokens[i].Layanan
+		rv.ServiceID = s.tokens[i].ServiceID
+		s.items = append(s.items, rv)
+		return rv, true
+	}
+	return Review{}, false
+}
+
+// RatingSummary is the average rating of one service.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// ServiceRatings returns rating summaries per service ID, from approved verified-client reviews only.
+func (s *ReviewStore) ServiceRatings() map[string]RatingSummary {
+	out := make(map[string]RatingSummary)
+	if s.pool != nil {
+		ctx := context.Background()
+		rows, err := s.pool.Query(ctx, `SELECT service_id, AVG(rating)::float8, COUNT(*) FROM reviews
+			WHERE verified = true AND status = 'approved' AND rating > 0 AND service_id <> '' GROUP BY service_id`)
+		if err != nil {
+			return out
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			var r RatingSummary
+			if err := rows.Scan(&id, &r.Average, &r.Count); err != nil {
+				return out
+			}
+			out[id] = r
+		}
+		return out
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sums := make(map[string]int)
+	for _, rv := range s.items {
+		if !rv.Verified || rv.Status != ReviewStatusApproved || rv.Rating <= 0 || rv.ServiceID == "" {
+			continue
+		}
+		sums[rv.ServiceID] += rv.Rating
+		r := out[rv.ServiceID]
+		r.Count++
+		out[rv.ServiceID] = r
+	}
+	for id, r := range out {
+		r.Average = float64(sums[id]) / float64(r.Count)
+		out[id] = r
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Revision purchase status.
+const (
+	RevisionPurchasePending = "pending" // menunggu pembayaran
+	RevisionPurchasePaid    = "paid"    // lunas, tiket sudah diterbitkan
+	RevisionPurchaseFailed  = "failed"  // expire/cancel/deny di payment gateway
+)
+
+// RevisionPurchase is an online purchase of extra revision tickets by the client of an order.
+type RevisionPurchase struct {
+	ID             string     `json:"id"`
+	OrderID        string     `json:"order_id"`
+	PaymentOrderID string     `json:"payment_order_id"` // order_id di payment gateway, e.g. revisi-1760000000-ab12cd
+	Qty            int        `json:"qty"`
+	Amount         int        `json:"amount"` // IDR, total
+	Status         string     `json:"status"`
+	TicketCodes    []string   `json:"ticket_codes"` // terisi setelah lunas
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RevisionPurchaseStore holds revision purchases in memory or PostgreSQL.
+type RevisionPurchaseStore struct {
+	mu    sync.RWMutex
+	items []RevisionPurchase
+	pool  *pgxpool.Pool
+}
+
+// NewRevisionPurchaseStore returns a new in-memory store.
+func NewRevisionPurchaseStore() *RevisionPurchaseStore {
+	return &RevisionPurchaseStore{items: make([]RevisionPurchase, 0)}
+}
+
+// NewRevisionPurchaseStoreFromDB returns a store backed by PostgreSQL.
+func NewRevisionPurchaseStoreFromDB(pool *pgxpool.Pool) *RevisionPurchaseStore {
+	return &RevisionPurchaseStore{pool: pool}
+}
+
+const revisionPurchaseColumns = `id, order_id, payment_order_id, qty, amount, status, ticket_codes, paid_at, created_at`
+
+// Add saves a new pending purchase and returns it with ID (ID kosong bila gagal disimpan).
+func (s *RevisionPurchaseStore) Add(p RevisionPurchase) RevisionPurchase {
+	p.ID = generateID()
+	p.Status = RevisionPurchasePending
+	p.TicketCodes = []string{}
+	p.CreatedAt = time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO revision_purchases (id, order_id, payment_order_id, qty, amount, status, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			p.ID, p.OrderID, p.PaymentOrderID, p.Qty, p.Amount, p.Status, p.CreatedAt)
+		if err != nil {
+			return RevisionPurchase{}
+		}
+		return p
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, p)
+	return p
+}
+
+// FindByPaymentOrderID returns the purchase of a payment gateway order_id.
+func (s *RevisionPurchaseStore) FindByPaymentOrderID(paymentOrderID string) (RevisionPurchase, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+revisionPurchaseColumns+` FROM revision_purchases WHERE payment_order_id = $1`, paymentOrderID)
+		if len(list) == 0 {
+			return RevisionPurchase{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.items {
+		if p.PaymentOrderID == paymentOrderID {
+			return p, true
+		}
+	}
+	return RevisionPurchase{}, false
+}
+
+// ListByOrder returns the purchases of an order, newest first.
+func (s *RevisionPurchaseStore) ListByOrder(orderID string) []RevisionPurchase {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+revisionPurchaseColumns+` FROM revision_purchases WHERE order_id = $1 ORDER BY created_at DESC`, orderID)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []RevisionPurchase
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if s.items[i].OrderID == orderID {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// MarkPaid moves a pending purchase to paid. Returns false bila sudah tidak pending (webhook ganda),
+// sehingga tiket hanya diterbitkan sekali.
+func (s *RevisionPurchaseStore) MarkPaid(id string) (RevisionPurchase, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		list := s.queryDB(`UPDATE revision_purchases SET status = 'paid', paid_at = $2
+			WHERE id = $1 AND status = 'pending' RETURNING `+revisionPurchaseColumns, id, now)
+		if len(list) == 0 {
+			return RevisionPurchase{}, false
+		}
+		return list[0], true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status == RevisionPurchasePending {
+			s.items[i].Status = RevisionPurchasePaid
+			s.items[i].PaidAt = &now
+			return s.items[i], true
+		}
+	}
+	return RevisionPurchase{}, false
+}
+
+// MarkFailed moves a pending purchase to failed (pembayaran kedaluwarsa/dibatalkan).
+func (s *RevisionPurchaseStore) MarkFailed(id string) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		tag, err := s.pool.Exec(ctx, `UPDATE revision_purchases SET status = 'failed' WHERE id = $1 AND status = 'pending'`, id)
+		return err == nil && tag.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status == RevisionPurchasePending {
+			s.items[i].Status = RevisionPurchaseFailed
+			return true
+		}
+	}
+	return false
+}
+
+// SetTicketCodes records the codes of the tickets issued for a paid purchase.
+func (s *RevisionPurchaseStore) SetTicketCodes(id string, codes []string) bool {
+	if codes == nil {
+		codes = []string{}
+	}
+	if s.pool != nil {
+		raw, _ := json.Marshal(codes)
+		ctx := context.Background()
+		tag, err := s.pool.Exec(ctx, `UPDATE revision_purchases SET ticket_codes = $2 WHERE id = $1`, id, raw)
+		return err == nil && tag.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].TicketCodes = codes
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RevisionPurchaseStore) queryDB(q string, args ...any) []RevisionPurchase {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []RevisionPurchase
+	for rows.Next() {
+		var p RevisionPurchase
+		var codes []byte
+		if err := rows.Scan(&p.ID, &p.OrderID, &p.PaymentOrderID, &p.Qty, &p.Amount, &p.Status, &codes, &p.PaidAt, &p.CreatedAt); err != nil {
+			return out
+		}
+		_ = json.Unmarshal(codes, &p.TicketCodes)
+		if p.TicketCodes == nil {
+			p.TicketCodes = []string{}
+		}
+		out = append(out, p)
+	}
+	return out
+}
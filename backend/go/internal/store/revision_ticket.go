@@ -4,22 +4,90 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// RevisionTicket is one revision coupon for an order (max 2 per order).
+// Revision workflow status of a redeemed ticket.
+const (
+	RevisionRequested  = "requested"   // tiket diklaim klien beserta deskripsi perubahan
+	RevisionInProgress = "in_progress" // sedang dikerjakan
+	RevisionDelivered  = "delivered"   // hasil revisi dikirim, menunggu klien
+	RevisionAccepted   = "accepted"    // klien menerima hasil revisi
+)
+
+// revisionTransitions lists the revision statuses each status may move to.
+// delivered → in_progress bila klien minta perbaikan atas revisi yang sama.
+var revisionTransitions = map[string][]string{
+	RevisionRequested:  {RevisionInProgress},
+	RevisionInProgress: {RevisionDelivered},
+	RevisionDelivered:  {RevisionAccepted, RevisionInProgress},
+	RevisionAccepted:   {},
+}
+
+// CanTransitionRevision reports whether a revision may move from one status to another.
+func CanTransitionRevision(from, to string) bool {
+	for _, s := range revisionTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Where a revision ticket came from.
+const (
+	TicketSourceIncluded = "included" // jatah revisi sesuai kesepakatan order
+	TicketSourceAdmin    = "admin"    // diterbitkan admin belakangan
+	TicketSourcePurchase = "purchase" // dibeli klien secara online
+)
+
+// RevisionTicket is one revision coupon for an order (jumlahnya mengikuti jatah revisi order, plus tiket tambahan).
+// Setelah diklaim, Note berisi deskripsi perubahan dari klien dan RevisiStatus mengikuti alur revisi.
 type RevisionTicket struct {
-	ID        string     `json:"id"`
-	OrderID   string     `json:"order_id"`
-	Code      string     `json:"code"`
-	Sequence  int        `json:"sequence"`
-	Status    string     `json:"status"` // "unused" | "used"
-	UsedAt    *time.Time `json:"used_at,omitempty"`
-	Note      string     `json:"note"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID              string              `json:"id"`
+	OrderID         string              `json:"order_id"`
+	Code            string              `json:"code"`
+	Sequence        int                 `json:"sequence"`
+	Status          string              `json:"status"` // "unused" | "used" | "expired"
+	Source          string              `json:"source"` // included | admin | purchase
+	UsedAt          *time.Time          `json:"used_at,omitempty"`
+	Note            string              `json:"note"`
+	Attachments     []MessageAttachment `json:"attachments"`             // referensi dari klien saat klaim
+	RevisiStatus    string              `json:"revisi_status,omitempty"` // "" selama belum diklaim
+	DeliveryNote    string              `json:"delivery_note,omitempty"` // catatan/link hasil revisi dari admin
+	RevisiUpdatedAt *time.Time          `json:"revisi_updated_at,omitempty"`
+	ExpiresAt       *time.Time          `json:"expires_at,omitempty"`  // batas klaim; nil sampai hasil diserahkan
+	ExpiryNote      string              `json:"expiry_note,omitempty"` // alasan perpanjangan dari admin
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// Expired reports whether an unused ticket can no longer be redeemed at now.
+func (t RevisionTicket) Expired(now time.Time) bool {
+	return t.Status == "expired" || (t.Status == "unused" && t.ExpiresAt != nil && !now.Before(*t.ExpiresAt))
+}
+
+const ticketColumns = `id, order_id, code, sequence, status, source, used_at, note, attachments, revisi_status, delivery_note, revisi_updated_at, expires_at, expiry_note, created_at`
+
+func scanTicket(row pgx.Row) (RevisionTicket, error) {
+	var t RevisionTicket
+	var atts []byte
+	if err := row.Scan(&t.ID, &t.OrderID, &t.Code, &t.Sequence, &t.Status, &t.Source, &t.UsedAt, &t.Note, &atts, &t.RevisiStatus, &t.DeliveryNote,
+		&t.RevisiUpdatedAt, &t.ExpiresAt, &t.ExpiryNote, &t.CreatedAt); err != nil {
+		return RevisionTicket{}, err
+	}
+	var stored []storedAttachment
+	_ = json.Unmarshal(atts, &stored)
+	t.Attachments = make([]MessageAttachment, len(stored))
+	for i, a := range stored {
+		t.Attachments[i] = a.MessageAttachment
+		t.Attachments[i].Path = a.Path
+	}
+	return t, nil
 }
 
 // RevisionTicketStore holds revision tickets in memory or PostgreSQL.
@@ -45,15 +113,30 @@ func generateTicketCode() string {
 	return "RV-" + hex.EncodeToString(b)[:10]
 }
 
-// CreateForOrder creates n revision tickets for an order (typically 2).
+// CreateForOrder creates the n revision tickets included in an order's agreement.
 func (s *RevisionTicketStore) CreateForOrder(orderID string, n int) []RevisionTicket {
+	return s.AddTickets(orderID, n, TicketSourceIncluded)
+}
+
+// AddTickets creates n more tickets for an order, continuing its sequence (revisi ke-3, ke-4, ...).
+func (s *RevisionTicketStore) AddTickets(orderID string, n int, source string) []RevisionTicket {
+	if n <= 0 {
+		return nil
+	}
 	if s.pool != nil {
-		return s.createForOrderDB(orderID, n)
+		return s.addTicketsDB(orderID, n, source)
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := make([]RevisionTicket, 0, n)
+	last := 0
 	seen := make(map[string]bool)
+	for _, t := range s.items {
+		seen[t.Code] = true
+		if t.OrderID == orderID && t.Sequence > last {
+			last = t.Sequence
+		}
+	}
+	out := make([]RevisionTicket, 0, n)
 	for i := 1; i <= n; i++ {
 		code := generateTicketCode()
 		for seen[code] {
@@ -64,18 +147,22 @@ func (s *RevisionTicketStore) CreateForOrder(orderID string, n int) []RevisionTi
 			ID:        generateID(),
 			OrderID:   orderID,
 			Code:      code,
-			Sequence:  i,
+			Sequence:  last + i,
 			Status:    "unused",
+			Source:    source,
 			CreatedAt: time.Now().UTC(),
 		}
+		t.Attachments = []MessageAttachment{}
 		s.items = append(s.items, t)
 		out = append(out, t)
 	}
 	return out
 }
 
-func (s *RevisionTicketStore) createForOrderDB(orderID string, n int) []RevisionTicket {
+func (s *RevisionTicketStore) addTicketsDB(orderID string, n int, source string) []RevisionTicket {
 	ctx := context.Background()
+	var last int
+	_ = s.pool.QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM revision_tickets WHERE order_id = $1`, orderID).Scan(&last)
 	out := make([]RevisionTicket, 0, n)
 	seen := make(map[string]bool)
 	for i := 1; i <= n; i++ {
@@ -88,13 +175,15 @@ func (s *RevisionTicketStore) createForOrderDB(orderID string, n int) []Revision
 			ID:        generateID(),
 			OrderID:   orderID,
 			Code:      code,
-			Sequence:  i,
+			Sequence:  last + i,
 			Status:    "unused",
+			Source:    source,
 			CreatedAt: time.Now().UTC(),
 		}
-		_, err := s.pool.Exec(ctx, `INSERT INTO revision_tickets (id, order_id, code, sequence, status, created_at)
-			VALUES ($1,$2,$3,$4,'unused',$5)`,
-			t.ID, t.OrderID, t.Code, t.Sequence, t.CreatedAt)
+		t.Attachments = []MessageAttachment{}
+		_, err := s.pool.Exec(ctx, `INSERT INTO revision_tickets (id, order_id, code, sequence, status, source, created_at)
+			VALUES ($1,$2,$3,$4,'unused',$5,$6)`,
+			t.ID, t.OrderID, t.Code, t.Sequence, t.Source, t.CreatedAt)
 		if err != nil {
 			return out
 		}
@@ -121,41 +210,46 @@ func (s *RevisionTicketStore) ByOrderID(orderID string) []RevisionTicket {
 
 func (s *RevisionTicketStore) byOrderIDDB(orderID string) []RevisionTicket {
 	ctx := context.Background()
-	rows, err := s.pool.Query(ctx, `SELECT id, order_id, code, sequence, status, used_at, note, created_at
-		FROM revision_tickets WHERE order_id = $1 ORDER BY sequence`, orderID)
+	rows, err := s.pool.Query(ctx, `SELECT `+ticketColumns+` FROM revision_tickets WHERE order_id = $1 ORDER BY sequence`, orderID)
 	if err != nil {
 		return nil
 	}
 	defer rows.Close()
 	var out []RevisionTicket
 	for rows.Next() {
-		var t RevisionTicket
-		var usedAt *time.Time
-		if err := rows.Scan(&t.ID, &t.OrderID, &t.Code, &t.Sequence, &t.Status, &usedAt, &t.Note, &t.CreatedAt); err != nil {
+		t, err := scanTicket(rows)
+		if err != nil {
 			return out
 		}
-		t.UsedAt = usedAt
 		out = append(out, t)
 	}
 	return out
 }
 
-// Redeem marks a ticket as used by code. Returns (ticket, true) if valid and was unused.
-func (s *RevisionTicketStore) Redeem(code string) (RevisionTicket, bool) {
+// Redeem marks a ticket as used by code and opens its revision (status requested) with the client's
+// description and reference attachments. Returns (ticket, true) if valid, unused and not past its expiry.
+func (s *RevisionTicketStore) Redeem(code, note string, atts []MessageAttachment) (RevisionTicket, bool) {
 	code = trimUpper(code)
 	if code == "" {
 		return RevisionTicket{}, false
 	}
+	if atts == nil {
+		atts = []MessageAttachment{}
+	}
 	if s.pool != nil {
-		return s.redeemDB(code)
+		return s.redeemDB(code, note, atts)
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	now := time.Now().UTC()
 	for i := range s.items {
-		if s.items[i].Code == code && s.items[i].Status == "unused" {
-			now := time.Now().UTC()
+		if trimUpper(s.items[i].Code) == code && s.items[i].Status == "unused" && !s.items[i].Expired(now) {
 			s.items[i].Status = "used"
 			s.items[i].UsedAt = &now
+			s.items[i].Note = note
+			s.items[i].Attachments = atts
+			s.items[i].RevisiStatus = RevisionRequested
+			s.items[i].RevisiUpdatedAt = &now
 			return s.items[i], true
 		}
 	}
@@ -176,27 +270,17 @@ func trimUpper(s string) string {
 	return string(b)
 }
 
-func (s *RevisionTicketStore) redeemDB(code string) (RevisionTicket, bool) {
+func (s *RevisionTicketStore) redeemDB(code, note string, atts []MessageAttachment) (RevisionTicket, bool) {
 	ctx := context.Background()
-	codeNorm := trimUpper(code)
-	var t RevisionTicket
-	var usedAt *time.Time
-	err := s.pool.QueryRow(ctx, `SELECT id, order_id, code, sequence, status, used_at, note, created_at
-		FROM revision_tickets WHERE UPPER(REPLACE(TRIM(code),' ','')) = $1`, codeNorm).Scan(
-		&t.ID, &t.OrderID, &t.Code, &t.Sequence, &t.Status, &usedAt, &t.Note, &t.CreatedAt)
-	if err != nil {
-		return RevisionTicket{}, false
-	}
-	if t.Status != "unused" {
-		return t, false
-	}
+	stored, _ := json.Marshal(storedAttachments(atts))
 	now := time.Now().UTC()
-	_, err = s.pool.Exec(ctx, `UPDATE revision_tickets SET status = 'used', used_at = $2 WHERE id = $1`, t.ID, now)
+	t, err := scanTicket(s.pool.QueryRow(ctx, `UPDATE revision_tickets SET status = 'used', used_at = $2, note = $3, attachments = $4,
+		revisi_status = 'requested', revisi_updated_at = $2
+		WHERE UPPER(REPLACE(TRIM(code),' ','')) = $1 AND status = 'unused' AND (expires_at IS NULL OR expires_at > $2)
+		RETURNING `+ticketColumns, code, now, note, stored))
 	if err != nil {
 		return RevisionTicket{}, false
 	}
-	t.Status = "used"
-	t.UsedAt = &now
 	return t, true
 }
 
@@ -221,15 +305,171 @@ func (s *RevisionTicketStore) GetByCode(code string) (RevisionTicket, bool) {
 
 func (s *RevisionTicketStore) getByCodeDB(code string) (RevisionTicket, bool) {
 	ctx := context.Background()
-	codeNorm := trimUpper(code)
-	var t RevisionTicket
-	var usedAt *time.Time
-	err := s.pool.QueryRow(ctx, `SELECT id, order_id, code, sequence, status, used_at, note, created_at
-		FROM revision_tickets WHERE UPPER(REPLACE(TRIM(code),' ','')) = $1`, codeNorm).Scan(
-		&t.ID, &t.OrderID, &t.Code, &t.Sequence, &t.Status, &usedAt, &t.Note, &t.CreatedAt)
+	t, err := scanTicket(s.pool.QueryRow(ctx, `SELECT `+ticketColumns+` FROM revision_tickets WHERE UPPER(REPLACE(TRIM(code),' ','')) = $1`, code))
 	if err != nil {
 		return RevisionTicket{}, false
 	}
-	t.UsedAt = usedAt
 	return t, true
 }
+
+// Get returns a ticket by ID.
+func (s *RevisionTicketStore) Get(id string) (RevisionTicket, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		t, err := scanTicket(s.pool.QueryRow(ctx, `SELECT `+ticketColumns+` FROM revision_tickets WHERE id = $1`, id))
+		if err != nil {
+			return RevisionTicket{}, false
+		}
+		return t, true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.items {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return RevisionTicket{}, false
+}
+
+// SetRevisionStatus moves the revision of ticket id from status from to status to.
+// deliveryNote diisi saat delivered (kosong = tidak diubah). Returns false bila status sudah berubah atau perpindahan tidak diizinkan.
+func (s *RevisionTicketStore) SetRevisionStatus(id, from, to, deliveryNote string) (RevisionTicket, bool) {
+	if !CanTransitionRevision(from, to) {
+		return RevisionTicket{}, false
+	}
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		t, err := scanTicket(s.pool.QueryRow(ctx, `UPDATE revision_tickets SET revisi_status = $3, revisi_updated_at = $4,
+			delivery_note = CASE WHEN $5 = '' THEN delivery_note ELSE $5 END
+			WHERE id = $1 AND revisi_status = $2 RETURNING `+ticketColumns, id, from, to, now, deliveryNote))
+		if err != nil {
+			return RevisionTicket{}, false
+		}
+		return t, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].RevisiStatus == from {
+			s.items[i].RevisiStatus = to
+			s.items[i].RevisiUpdatedAt = &now
+			if deliveryNote != "" {
+				s.items[i].DeliveryNote = deliveryNote
+			}
+			return s.items[i], true
+		}
+	}
+	return RevisionTicket{}, false
+}
+
+// OpenClaimWindow sets the expiry of an order's unused tickets to until (hasil diserahkan: masa klaim dimulai).
+// Expiry hanya dimundurkan, tidak pernah dimajukan, sehingga perpanjangan admin yang lebih lama tetap berlaku.
+func (s *RevisionTicketStore) OpenClaimWindow(orderID string, until time.Time) int {
+	until = until.UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		tag, err := s.pool.Exec(ctx, `UPDATE revision_tickets SET expires_at = $2
+			WHERE order_id = $1 AND status = 'unused' AND (expires_at IS NULL OR expires_at < $2)`, orderID, until)
+		if err != nil {
+			return 0
+		}
+		return int(tag.RowsAffected())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for i := range s.items {
+		t := &s.items[i]
+		if t.OrderID == orderID && t.Status == "unused" && (t.ExpiresAt == nil || t.ExpiresAt.Before(until)) {
+			expires := until
+			t.ExpiresAt = &expires
+			n++
+		}
+	}
+	return n
+}
+
+// ExpireDue marks unused tickets whose expiry has passed as expired and returns them (dipanggil scheduler).
+func (s *RevisionTicketStore) ExpireDue(now time.Time) []RevisionTicket {
+	if s.pool != nil {
+		ctx := context.Background()
+		rows, err := s.pool.Query(ctx, `UPDATE revision_tickets SET status = 'expired'
+			WHERE status = 'unused' AND expires_at IS NOT NULL AND expires_at <= $1 RETURNING `+ticketColumns, now.UTC())
+		if err != nil {
+			return nil
+		}
+		defer rows.Close()
+		var out []RevisionTicket
+		for rows.Next() {
+			t, err := scanTicket(rows)
+			if err != nil {
+				return out
+			}
+			out = append(out, t)
+		}
+		return out
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []RevisionTicket
+	for i := range s.items {
+		t := &s.items[i]
+		if t.Status == "unused" && t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+			t.Status = "expired"
+			out = append(out, *t)
+		}
+	}
+	return out
+}
+
+// ExtendExpiry moves the expiry of an unused or expired ticket to until with the admin's note;
+// tiket yang sudah expired aktif kembali. Returns false bila tiket tidak ada atau sudah dipakai.
+func (s *RevisionTicketStore) ExtendExpiry(id string, until time.Time, note string) (RevisionTicket, bool) {
+	until = until.UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		t, err := scanTicket(s.pool.QueryRow(ctx, `UPDATE revision_tickets SET status = 'unused', expires_at = $2, expiry_note = $3
+			WHERE id = $1 AND status IN ('unused', 'expired') RETURNING `+ticketColumns, id, until, note))
+		if err != nil {
+			return RevisionTicket{}, false
+		}
+		return t, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		t := &s.items[i]
+		if t.ID == id && (t.Status == "unused" || t.Status == "expired") {
+			t.Status = "unused"
+			t.ExpiresAt = &until
+			t.ExpiryNote = note
+			return *t, true
+		}
+	}
+	return RevisionTicket{}, false
+}
+
+// RedactOrder clears the client's descriptions and attachments on the tickets of an order (hapus data subjek);
+// returns the removed attachments so their files can be deleted.
+func (s *RevisionTicketStore) RedactOrder(orderID string) []MessageAttachment {
+	var removed []MessageAttachment
+	for _, t := range s.ByOrderID(orderID) {
+		removed = append(removed, t.Attachments...)
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, _ = s.pool.Exec(ctx, `UPDATE revision_tickets SET note = '', attachments = '[]' WHERE order_id = $1`, orderID)
+		return removed
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].OrderID == orderID {
+			s.items[i].Note = ""
+			s.items[i].Attachments = []MessageAttachment{}
+		}
+	}
+	return removed
+}
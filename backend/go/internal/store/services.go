@@ -25,6 +25,9 @@ type Service struct {
 	PriceAfterDiscount string    `json:"price_after_discount"` // e.g. "360 ribu" saat diskon 10%
 	Order              int       `json:"order"`
 	Closed             bool      `json:"closed"`
+	DurasiHari         int       `json:"durasi_hari"` // rata-rata lama pengerjaan (hari); 0 = pakai QUEUE_DEFAULT_DAYS
+	Kapasitas          int       `json:"kapasitas"`   // maks. order aktif bersamaan; 0 = tanpa batas
+	Tier               string    `json:"tier"`        // paket perjanjian: standar | profesional (menentukan jatah revisi)
 	CreatedAt          time.Time `json:"created_at"`
 }
 
@@ -111,6 +114,7 @@ func (s *ServiceStore) SeedIfEmpty() {
 			PriceAfterDiscount: "",
 			Order:              s.nextOrder,
 			Closed:             false,
+			Tier:               ServiceTierStandar,
 			CreatedAt:          time.Now().UTC(),
 		})
 	}
@@ -172,6 +176,7 @@ func (s *ServiceStore) Add(title, tag, desc, priceAwal string) Service {
 		PriceAfterDiscount: "",
 		Order:              s.nextOrder,
 		Closed:             false,
+		Tier:               ServiceTierStandar,
 		CreatedAt:          time.Now().UTC(),
 	}
 	s.items = append(s.items, svc)
@@ -192,6 +197,7 @@ func (s *ServiceStore) addDB(title, tag, desc, priceAwal string) Service {
 		PriceAfterDiscount: "",
 		Order:              nextOrd,
 		Closed:             false,
+		Tier:               ServiceTierStandar,
 		CreatedAt:          time.Now().UTC(),
 	}
 	_, err := s.pool.Exec(ctx, `INSERT INTO services (id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at)
@@ -243,8 +249,9 @@ func (s *ServiceStore) updateDB(id, title, tag, desc, priceAwal string, discount
 		return Service{}, false
 	}
 	var svc Service
-	err = s.pool.QueryRow(ctx, `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at FROM services WHERE id = $1`, id).Scan(
-		&svc.ID, &svc.Title, &svc.Tag, &svc.Desc, &svc.PriceAwal, &svc.DiscountPercent, &svc.PriceAfterDiscount, &svc.Order, &svc.Closed, &svc.CreatedAt)
+	err = s.pool.QueryRow(ctx, `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at, durasi_hari, kapasitas, tier FROM services WHERE id = $1`, id).Scan(
+		&svc.ID, &svc.Title, &svc.Tag, &svc.Desc, &svc.PriceAwal, &svc.DiscountPercent, &svc.PriceAfterDiscount, &svc.Order, &svc.Closed, &svc.CreatedAt,
+		&svc.DurasiHari, &svc.Kapasitas, &svc.Tier)
 	if err != nil {
 		return Service{}, false
 	}
@@ -281,9 +288,9 @@ func (s *ServiceStore) ListAll() []Service {
 
 func (s *ServiceStore) listDB(all bool) []Service {
 	ctx := context.Background()
-	q := `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at FROM services ORDER BY "order"`
+	q := `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at, durasi_hari, kapasitas, tier FROM services ORDER BY "order"`
 	if !all {
-		q = `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at FROM services WHERE closed = false ORDER BY "order"`
+		q = `SELECT id, title, tag, "desc", price_awal, discount_percent, price_after_discount, "order", closed, created_at, durasi_hari, kapasitas, tier FROM services WHERE closed = false ORDER BY "order"`
 	}
 	rows, err := s.pool.Query(ctx, q)
 	if err != nil {
@@ -293,7 +300,8 @@ func (s *ServiceStore) listDB(all bool) []Service {
 	var out []Service
 	for rows.Next() {
 		var svc Service
-		if err := rows.Scan(&svc.ID, &svc.Title, &svc.Tag, &svc.Desc, &svc.PriceAwal, &svc.DiscountPercent, &svc.PriceAfterDiscount, &svc.Order, &svc.Closed, &svc.CreatedAt); err != nil {
+		if err := rows.Scan(&svc.ID, &svc.Title, &svc.Tag, &svc.Desc, &svc.PriceAwal, &svc.DiscountPercent, &svc.PriceAfterDiscount, &svc.Order, &svc.Closed, &svc.CreatedAt,
+			&svc.DurasiHari, &svc.Kapasitas, &svc.Tier); err != nil {
 			return out
 		}
 		out = append(out, svc)
@@ -322,6 +330,66 @@ func (s *ServiceStore) SetClosed(id string, closed bool) bool {
 	return false
 }
 
+// SetQueueSettings sets the average duration (hari) and capacity of a service for antrian estimates.
+// Returns (updated, true) or (zero, false) when not found.
+func (s *ServiceStore) SetQueueSettings(id string, durasiHari, kapasitas int) (Service, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE services SET durasi_hari = $2, kapasitas = $3 WHERE id = $1`, id, durasiHari, kapasitas)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Service{}, false
+		}
+		for _, svc := range s.listDB(true) {
+			if svc.ID == id {
+				return svc, true
+			}
+		}
+		return Service{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].DurasiHari = durasiHari
+			s.items[i].Kapasitas = kapasitas
+			return s.items[i], true
+		}
+	}
+	return Service{}, false
+}
+
+// Service tiers (sama dengan tier di surat perjanjian).
+const (
+	ServiceTierStandar     = "standar"
+	ServiceTierProfesional = "profesional"
+)
+
+// SetTier sets the agreement tier of a service. Returns (updated, true) or (zero, false) when not found.
+func (s *ServiceStore) SetTier(id, tier string) (Service, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE services SET tier = $2 WHERE id = $1`, id, tier)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Service{}, false
+		}
+		for _, svc := range s.listDB(true) {
+			if svc.ID == id {
+				return svc, true
+			}
+		}
+		return Service{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Tier = tier
+			return s.items[i], true
+		}
+	}
+	return Service{}, false
+}
+
 // Delete removes a service by ID.
 func (s *ServiceStore) Delete(id string) bool {
 	if s.pool != nil {
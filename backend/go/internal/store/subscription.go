@@ -0,0 +1,395 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Subscription status.
+const (
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusPastDue   = "past_due" // tagihan periode terakhir belum dibayar
+	SubscriptionStatusCancelled = "cancelled"
+)
+
+// SubscriptionPlan is a monthly support plan with a fixed amount.
+type SubscriptionPlan struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Amount      int       `json:"amount"` // IDR per bulan
+	Description string    `json:"description"`
+	Active      bool      `json:"active"` // false = tidak bisa dipilih pendukung baru
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Subscription is one supporter's monthly subscription. Setiap periode dibuat sebagai donasi pending
+// dengan SubscriptionID; lunas/tidaknya periode dilihat dari donasi PeriodOrderID.
+type Subscription struct {
+	ID              string     `json:"id"`
+	PlanID          string     `json:"plan_id"`
+	PlanName        string     `json:"plan_name"`
+	Amount          int        `json:"amount"` // IDR, disalin dari plan saat daftar
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Anonymous       bool       `json:"anonymous"`
+	ConsentPublish  bool       `json:"consent_publish"`
+	Status          string     `json:"status"`
+	AnchorDay       int        `json:"anchor_day"` // tanggal tagihan tiap bulan (1-31)
+	NextChargeAt    time.Time  `json:"next_charge_at"`
+	PeriodOrderID   string     `json:"period_order_id,omitempty"`
+	PeriodStartedAt *time.Time `json:"period_started_at,omitempty"`
+	PeriodPaid      bool       `json:"period_paid"`
+	MissedPeriods   int        `json:"missed_periods"` // periode berturut-turut yang tidak dibayar
+	LastPaidAt      *time.Time `json:"last_paid_at,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// SubscriptionStore holds plans and subscriptions in memory or PostgreSQL.
+type SubscriptionStore struct {
+	mu    sync.RWMutex
+	plans []SubscriptionPlan
+	items []Subscription
+	pool  *pgxpool.Pool
+}
+
+// NewSubscriptionStore returns a new in-memory subscription store.
+func NewSubscriptionStore() *SubscriptionStore {
+	return &SubscriptionStore{plans: make([]SubscriptionPlan, 0), items: make([]Subscription, 0)}
+}
+
+// NewSubscriptionStoreFromDB returns a subscription store backed by PostgreSQL.
+func NewSubscriptionStoreFromDB(pool *pgxpool.Pool) *SubscriptionStore {
+	return &SubscriptionStore{pool: pool}
+}
+
+// AddPlan saves a new plan and returns it with ID.
+func (s *SubscriptionStore) AddPlan(p SubscriptionPlan) SubscriptionPlan {
+	p.ID = generateID()
+	p.CreatedAt = time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO subscription_plans (id, name, amount, description, active, created_at) VALUES ($1,$2,$3,$4,$5,$6)`,
+			p.ID, p.Name, p.Amount, p.Description, p.Active, p.CreatedAt)
+		if err != nil {
+			return SubscriptionPlan{}
+		}
+		return p
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.plans = append(s.plans, p)
+	return p
+}
+
+// UpdatePlan overwrites a plan. Langganan yang sudah berjalan tetap memakai nominal lamanya.
+func (s *SubscriptionStore) UpdatePlan(p SubscriptionPlan) (SubscriptionPlan, bool) {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE subscription_plans SET name = $2, amount = $3, description = $4, active = $5 WHERE id = $1`,
+			p.ID, p.Name, p.Amount, p.Description, p.Active)
+		if err != nil || ct.RowsAffected() == 0 {
+			return SubscriptionPlan{}, false
+		}
+		return s.GetPlan(p.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.plans {
+		if s.plans[i].ID == p.ID {
+			p.CreatedAt = s.plans[i].CreatedAt
+			s.plans[i] = p
+			return p, true
+		}
+	}
+	return SubscriptionPlan{}, false
+}
+
+// GetPlan returns a plan by ID.
+func (s *SubscriptionStore) GetPlan(id string) (SubscriptionPlan, bool) {
+	if s.pool != nil {
+		list := s.queryPlansDB(`SELECT id, name, amount, description, active, created_at FROM subscription_plans WHERE id = $1`, id)
+		if len(list) == 0 {
+			return SubscriptionPlan{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.plans {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return SubscriptionPlan{}, false
+}
+
+// ListPlans returns plans ordered by amount; activeOnly untuk halaman publik.
+func (s *SubscriptionStore) ListPlans(activeOnly bool) []SubscriptionPlan {
+	if s.pool != nil {
+		return s.queryPlansDB(`SELECT id, name, amount, description, active, created_at FROM subscription_plans
+			WHERE active OR NOT $1 ORDER BY amount, created_at`, activeOnly)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []SubscriptionPlan
+	for _, p := range s.plans {
+		if p.Active || !activeOnly {
+			out = append(out, p)
+		}
+	}
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j].Amount < out[j-1].Amount; j-- {
+			out[j], out[j-1] = out[j-1], out[j]
+		}
+	}
+	return out
+}
+
+func (s *SubscriptionStore) queryPlansDB(q string, args ...any) []SubscriptionPlan {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []SubscriptionPlan
+	for rows.Next() {
+		var p SubscriptionPlan
+		if err := rows.Scan(&p.ID, &p.Name, &p.Amount, &p.Description, &p.Active, &p.CreatedAt); err != nil {
+			return out
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+const subscriptionColumns = `id, plan_id, plan_name, amount, name, email, anonymous, consent_publish, status, anchor_day, next_charge_at,
+	period_order_id, period_started_at, period_paid, missed_periods, last_paid_at, cancelled_at, created_at`
+
+// Add saves a new active subscription whose first charge is due now.
+func (st *SubscriptionStore) Add(s Subscription) Subscription {
+	now := time.Now().UTC()
+	s.ID = generateID()
+	s.Status = SubscriptionStatusActive
+	s.CreatedAt = now
+	s.NextChargeAt = now
+	s.AnchorDay = now.In(time.FixedZone("WIB", 7*60*60)).Day()
+	if st.pool != nil {
+		ctx := context.Background()
+		_, err := st.pool.Exec(ctx, `INSERT INTO subscriptions (`+subscriptionColumns+`)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)`,
+			s.ID, s.PlanID, s.PlanName, s.Amount, s.Name, s.Email, s.Anonymous, s.ConsentPublish, s.Status, s.AnchorDay, s.NextChargeAt,
+			s.PeriodOrderID, s.PeriodStartedAt, s.PeriodPaid, s.MissedPeriods, s.LastPaidAt, s.CancelledAt, s.CreatedAt)
+		if err != nil {
+			return Subscription{}
+		}
+		return s
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.items = append(st.items, s)
+	return s
+}
+
+// Get returns a subscription by ID.
+func (s *SubscriptionStore) Get(id string) (Subscription, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE id = $1`, id)
+		if len(list) == 0 {
+			return Subscription{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, x := range s.items {
+		if x.ID == id {
+			return x, true
+		}
+	}
+	return Subscription{}, false
+}
+
+// List returns subscriptions newest first; status "" = semua.
+func (s *SubscriptionStore) List(status string) []Subscription {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC`, status)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Subscription
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if status == "" || s.items[i].Status == status {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// ListDue returns non-cancelled subscriptions whose next charge is at or before now.
+func (s *SubscriptionStore) ListDue(now time.Time) []Subscription {
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE status <> 'cancelled' AND next_charge_at <= $1 ORDER BY next_charge_at`, now)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Subscription
+	for _, x := range s.items {
+		if x.Status != SubscriptionStatusCancelled && !x.NextChargeAt.After(now) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// StartPeriod records a new billing period. status dan missed dihitung pemanggil (periode sebelumnya lunas atau tidak).
+func (s *SubscriptionStore) StartPeriod(id, orderID string, startedAt, nextChargeAt time.Time, status string, missed int) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE subscriptions SET period_order_id = $2, period_started_at = $3, period_paid = false,
+			next_charge_at = $4, status = $5, missed_periods = $6 WHERE id = $1 AND status <> 'cancelled'`,
+			id, orderID, startedAt, nextChargeAt, status, missed)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id && s.items[i].Status != SubscriptionStatusCancelled {
+			t := startedAt
+			s.items[i].PeriodOrderID = orderID
+			s.items[i].PeriodStartedAt = &t
+			s.items[i].PeriodPaid = false
+			s.items[i].NextChargeAt = nextChargeAt
+			s.items[i].Status = status
+			s.items[i].MissedPeriods = missed
+			return true
+		}
+	}
+	return false
+}
+
+// MarkPaid records payment of the period with orderID: status kembali active.
+// Pembayaran periode lama (bukan periode berjalan) hanya memperbarui last_paid_at.
+func (s *SubscriptionStore) MarkPaid(id, orderID string, paidAt time.Time) bool {
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE subscriptions SET last_paid_at = $3,
+			period_paid = period_paid OR period_order_id = $2,
+			status = CASE WHEN period_order_id = $2 AND status <> 'cancelled' THEN 'active' ELSE status END,
+			missed_periods = CASE WHEN period_order_id = $2 THEN 0 ELSE missed_periods END
+			WHERE id = $1`, id, orderID, paidAt)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID != id {
+			continue
+		}
+		t := paidAt
+		s.items[i].LastPaidAt = &t
+		if s.items[i].PeriodOrderID == orderID {
+			s.items[i].PeriodPaid = true
+			s.items[i].MissedPeriods = 0
+			if s.items[i].Status != SubscriptionStatusCancelled {
+				s.items[i].Status = SubscriptionStatusActive
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Cancel stops a subscription; tidak ada tagihan baru setelah ini.
+func (s *SubscriptionStore) Cancel(id string) (Subscription, bool) {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE subscriptions SET status = 'cancelled', cancelled_at = COALESCE(cancelled_at, $2) WHERE id = $1`, id, now)
+		if err != nil || ct.RowsAffected() == 0 {
+			return Subscription{}, false
+		}
+		return s.Get(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Status = SubscriptionStatusCancelled
+			if s.items[i].CancelledAt == nil {
+				s.items[i].CancelledAt = &now
+			}
+			return s.items[i], true
+		}
+	}
+	return Subscription{}, false
+}
+
+func (s *SubscriptionStore) queryDB(q string, args ...any) []Subscription {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []Subscription
+	for rows.Next() {
+		var x Subscription
+		if err := rows.Scan(&x.ID, &x.PlanID, &x.PlanName, &x.Amount, &x.Name, &x.Email, &x.Anonymous, &x.ConsentPublish, &x.Status,
+			&x.AnchorDay, &x.NextChargeAt, &x.PeriodOrderID, &x.PeriodStartedAt, &x.PeriodPaid, &x.MissedPeriods,
+			&x.LastPaidAt, &x.CancelledAt, &x.CreatedAt); err != nil {
+			return out
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+// ListByEmail returns subscriptions of the given email (case-insensitive), untuk ekspor data subjek.
+func (s *SubscriptionStore) ListByEmail(email string) []Subscription {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+subscriptionColumns+` FROM subscriptions WHERE LOWER(TRIM(email)) = $1 ORDER BY created_at DESC`, email)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Subscription
+	for i := len(s.items) - 1; i >= 0; i-- {
+		if strings.ToLower(strings.TrimSpace(s.items[i].Email)) == email {
+			out = append(out, s.items[i])
+		}
+	}
+	return out
+}
+
+// Anonymize cancels the subscription and removes the supporter's name and email (hapus data subjek).
+func (s *SubscriptionStore) Anonymize(id string) bool {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE subscriptions SET name = '', email = '', status = 'cancelled', cancelled_at = COALESCE(cancelled_at, $2) WHERE id = $1`, id, now)
+		return err == nil && ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].Name = ""
+			s.items[i].Email = ""
+			s.items[i].Status = SubscriptionStatusCancelled
+			if s.items[i].CancelledAt == nil {
+				s.items[i].CancelledAt = &now
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Webhook event processing statuses.
+const (
+	WebhookStatusReceived  = "received"  // tersimpan, belum diproses
+	WebhookStatusProcessed = "processed" // handler dijalankan
+	WebhookStatusDuplicate = "duplicate" // event yang sama sudah pernah diproses, dilewati
+	WebhookStatusRejected  = "rejected"  // signature/token tidak valid
+	WebhookStatusFailed    = "failed"    // payload tidak bisa di-parse / error saat proses
+)
+
+// WebhookEvent is one incoming payment notification stored verbatim (inbox).
+type WebhookEvent struct {
+	ID          string              `json:"id"`
+	Gateway     string              `json:"gateway"`
+	OrderID     string              `json:"order_id"`
+	EventKey    string              `json:"event_key"` // gateway:order_id:status, untuk idempotency
+	Payload     string              `json:"payload"`   // body mentah
+	Headers     map[string][]string `json:"headers"`
+	Verified    bool                `json:"verified"`
+	Status      string              `json:"status"`
+	Outcome     string              `json:"outcome"` // hasil proses (mis. "pending -> settlement")
+	Attempts    int                 `json:"attempts"`
+	ReceivedAt  time.Time           `json:"received_at"`
+	ProcessedAt *time.Time          `json:"processed_at,omitempty"`
+}
+
+// WebhookEventStore holds webhook events in memory or PostgreSQL.
+type WebhookEventStore struct {
+	mu     sync.RWMutex
+	items  []WebhookEvent
+	claims map[string]string // event key -> ID event yang memprosesnya (mode memory)
+	pool   *pgxpool.Pool
+}
+
+// NewWebhookEventStore returns a new in-memory store.
+func NewWebhookEventStore() *WebhookEventStore {
+	return &WebhookEventStore{items: make([]WebhookEvent, 0), claims: make(map[string]string)}
+}
+
+// NewWebhookEventStoreFromDB returns a store backed by PostgreSQL.
+func NewWebhookEventStoreFromDB(pool *pgxpool.Pool) *WebhookEventStore {
+	return &WebhookEventStore{pool: pool}
+}
+
+// Add stores a freshly received event (status received) and returns it with ID.
+func (s *WebhookEventStore) Add(gateway, payload string, headers map[string][]string) WebhookEvent {
+	ev := WebhookEvent{
+		ID:         generateID(),
+		Gateway:    gateway,
+		Payload:    payload,
+		Headers:    headers,
+		Status:     WebhookStatusReceived,
+		ReceivedAt: time.Now().UTC(),
+	}
+	if ev.Headers == nil {
+		ev.Headers = map[string][]string{}
+	}
+	if s.pool != nil {
+		hdr, _ := json.Marshal(ev.Headers)
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `INSERT INTO webhook_events (id, gateway, payload, headers, status, received_at)
+			VALUES ($1,$2,$3,$4,$5,$6)`, ev.ID, ev.Gateway, ev.Payload, hdr, ev.Status, ev.ReceivedAt)
+		if err != nil {
+			return WebhookEvent{}
+		}
+		return ev
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, ev)
+	return ev
+}
+
+// SetResult records the processing result of an event and bumps its attempt counter.
+func (s *WebhookEventStore) SetResult(id, orderID, eventKey string, verified bool, status, outcome string) bool {
+	now := time.Now().UTC()
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE webhook_events SET order_id = $2, event_key = $3, verified = $4, status = $5,
+			outcome = $6, attempts = attempts + 1, processed_at = $7 WHERE id = $1`,
+			id, orderID, eventKey, verified, status, outcome, now)
+		if err != nil {
+			return false
+		}
+		return ct.RowsAffected() > 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.items {
+		if s.items[i].ID == id {
+			s.items[i].OrderID = orderID
+			s.items[i].EventKey = eventKey
+			s.items[i].Verified = verified
+			s.items[i].Status = status
+			s.items[i].Outcome = outcome
+			s.items[i].Attempts++
+			s.items[i].ProcessedAt = &now
+			return true
+		}
+	}
+	return false
+}
+
+// Claim reserves eventKey for event id before it is applied, so concurrent deliveries of the same event
+// cannot both run the handler. Returns false if another event already holds the key (duplikat).
+// Error database lain tidak memblokir pemrosesan (fail open, seperti sebelumnya).
+func (s *WebhookEventStore) Claim(id, eventKey string) bool {
+	if eventKey == "" {
+		return true
+	}
+	if s.pool != nil {
+		ctx := context.Background()
+		_, err := s.pool.Exec(ctx, `UPDATE webhook_events SET processed_key = $2 WHERE id = $1`, id, eventKey)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false
+		}
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if owner, ok := s.claims[eventKey]; ok && owner != id {
+		return false
+	}
+	s.claims[eventKey] = id
+	return true
+}
+
+// Release gives up the key claimed by event id (mis. pemrosesan gagal), so a retry can be applied.
+func (s *WebhookEventStore) Release(id string) {
+	if s.pool != nil {
+		ctx := context.Background()
+		_, _ = s.pool.Exec(ctx, `UPDATE webhook_events SET processed_key = NULL WHERE id = $1`, id)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, owner := range s.claims {
+		if owner == id {
+			delete(s.claims, key)
+		}
+	}
+}
+
+// Get returns an event by ID.
+func (s *WebhookEventStore) Get(id string) (WebhookEvent, bool) {
+	if s.pool != nil {
+		list := s.queryDB(`SELECT `+webhookEventColumns+` FROM webhook_events WHERE id = $1`, id)
+		if len(list) == 0 {
+			return WebhookEvent{}, false
+		}
+		return list[0], true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ev := range s.items {
+		if ev.ID == id {
+			return ev, true
+		}
+	}
+	return WebhookEvent{}, false
+}
+
+// List returns the newest events first, optionally filtered by status and/or order_id, at most limit.
+func (s *WebhookEventStore) List(status, orderID string, limit int) []WebhookEvent {
+	if limit <= 0 {
+		limit = 100
+	}
+	if s.pool != nil {
+		return s.queryDB(`SELECT `+webhookEventColumns+` FROM webhook_events
+			WHERE ($1 = '' OR status = $1) AND ($2 = '' OR order_id = $2)
+			ORDER BY received_at DESC LIMIT $3`, status, orderID, limit)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []WebhookEvent
+	for i := len(s.items) - 1; i >= 0 && len(out) < limit; i-- {
+		ev := s.items[i]
+		if (status == "" || ev.Status == status) && (orderID == "" || ev.OrderID == orderID) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// RedactOrder replaces the payload and headers of every event for orderID (data pribadi donatur ada di payload).
+// Event tidak bisa di-replay lagi setelah ini. Returns the number of events redacted.
+func (s *WebhookEventStore) RedactOrder(orderID string) int {
+	if orderID == "" {
+		return 0
+	}
+	const redacted = `{"redacted":true}`
+	if s.pool != nil {
+		ctx := context.Background()
+		ct, err := s.pool.Exec(ctx, `UPDATE webhook_events SET payload = $2, headers = '{}' WHERE order_id = $1`, orderID, redacted)
+		if err != nil {
+			return 0
+		}
+		return int(ct.RowsAffected())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for i := range s.items {
+		if s.items[i].OrderID == orderID {
+			s.items[i].Payload = redacted
+			s.items[i].Headers = map[string][]string{}
+			n++
+		}
+	}
+	return n
+}
+
+const webhookEventColumns = `id, gateway, order_id, event_key, payload, headers, verified, status, outcome, attempts, received_at, processed_at`
+
+func (s *WebhookEventStore) queryDB(q string, args ...any) []WebhookEvent {
+	ctx := context.Background()
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []WebhookEvent
+	for rows.Next() {
+		var ev WebhookEvent
+		var hdr []byte
+		if err := rows.Scan(&ev.ID, &ev.Gateway, &ev.OrderID, &ev.EventKey, &ev.Payload, &hdr, &ev.Verified, &ev.Status,
+			&ev.Outcome, &ev.Attempts, &ev.ReceivedAt, &ev.ProcessedAt); err != nil {
+			return out
+		}
+		_ = json.Unmarshal(hdr, &ev.Headers)
+		out = append(out, ev)
+	}
+	return out
+}
@@ -2077,7 +2077,7 @@ const defaultAgreement: AgreementForm = {
   bank_account: "Rasya Production",
   keterlambatan_hari: "14 (empat belas)",
   revisi_putaran: "2 (dua)",
-  revisi_hari: "7 (tujuh)",
+  revisi_hari: "",
   konfirmasi_hari: "5",
   serah_terima_hari: "7 (tujuh)",
   tanggung_jawab_hari: "14 (empat belas)",
@@ -2338,7 +2338,7 @@ function AdminAgreement({ apiUrl, adminKey }: { apiUrl: string; adminKey: string
       <div className="flex flex-wrap gap-3 items-center mb-4">
         <input value={form.keterlambatan_hari} onChange={(e) => set("keterlambatan_hari", e.target.value)} placeholder="Keterlambatan hari" className="w-40 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
         <input value={form.revisi_putaran} onChange={(e) => set("revisi_putaran", e.target.value)} placeholder="Revisi putaran" className="w-32 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
-        <input value={form.revisi_hari} onChange={(e) => set("revisi_hari", e.target.value)} placeholder="Revisi hari" className="w-32 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
+        <input value={form.revisi_hari} onChange={(e) => set("revisi_hari", e.target.value)} placeholder="Revisi hari (kosong = ikut REVISI_HARI)" className="w-32 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
         <input value={form.konfirmasi_hari} onChange={(e) => set("konfirmasi_hari", e.target.value)} placeholder="Konfirmasi hari" className="w-28 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
         <input value={form.serah_terima_hari} onChange={(e) => set("serah_terima_hari", e.target.value)} placeholder="Serah terima hari" className="w-36 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />
         <input value={form.tanggung_jawab_hari} onChange={(e) => set("tanggung_jawab_hari", e.target.value)} placeholder="Tanggung jawab hari" className="w-40 rounded-lg border border-rasya-border bg-rasya-dark px-3 py-2 text-white text-sm focus:border-rasya-accent focus:outline-none" />